@@ -303,10 +303,11 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	// Router factory
 
 	accessLog := setupAccessLog(staticConfiguration.AccessLog)
+	tcpAccessLog := setupTCPAccessLog(staticConfiguration.AccessLog)
 	tracer := setupTracing(staticConfiguration.Tracing)
 
 	chainBuilder := middleware.NewChainBuilder(metricsRegistry, accessLog, tracer)
-	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, pluginBuilder, metricsRegistry, dialerManager)
+	routerFactory := server.NewRouterFactory(*staticConfiguration, managerFactory, tlsManager, chainBuilder, pluginBuilder, metricsRegistry, dialerManager, tcpAccessLog)
 
 	// Watcher
 
@@ -387,7 +388,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		}
 	})
 
-	return server.NewServer(routinesPool, serverEntryPointsTCP, serverEntryPointsUDP, watcher, chainBuilder, accessLog), nil
+	return server.NewServer(routinesPool, serverEntryPointsTCP, serverEntryPointsUDP, watcher, chainBuilder, accessLog, tcpAccessLog), nil
 }
 
 func getHTTPChallengeHandler(acmeProviders []*acme.Provider, httpChallengeProvider http.Handler) http.Handler {
@@ -600,6 +601,20 @@ func setupAccessLog(conf *types.AccessLog) *accesslog.Handler {
 	return accessLoggerMiddleware
 }
 
+func setupTCPAccessLog(conf *types.AccessLog) *accesslog.TCPHandler {
+	if conf == nil || conf.TCP == nil {
+		return nil
+	}
+
+	tcpAccessLogger, err := accesslog.NewTCPHandler(conf)
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to create TCP access logger")
+		return nil
+	}
+
+	return tcpAccessLogger
+}
+
 func setupTracing(conf *static.Tracing) *tracing.Tracing {
 	if conf == nil {
 		return nil
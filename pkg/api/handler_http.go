@@ -218,6 +218,36 @@ func (h Handler) getMiddleware(rw http.ResponseWriter, request *http.Request) {
 	}
 }
 
+func (h Handler) getMiddlewareQuota(rw http.ResponseWriter, request *http.Request) {
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	middleware, ok := h.runtimeConfiguration.Middlewares[middlewareID]
+	if !ok {
+		writeError(rw, fmt.Sprintf("middleware not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+
+	usage, err := middleware.GetQuotaUsage(request.Context())
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if usage == nil {
+		writeError(rw, fmt.Sprintf("quota not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+
+	err = json.NewEncoder(rw).Encode(usage)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func keepRouter(name string, item *runtime.RouterInfo, criterion *searchCriterion) bool {
 	if criterion == nil {
 		return true
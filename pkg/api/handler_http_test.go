@@ -10,6 +10,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -924,6 +925,37 @@ func TestHandler_HTTP(t *testing.T) {
 				statusCode: http.StatusNotFound,
 			},
 		},
+		{
+			desc: "quota usage of a middleware",
+			path: "/api/http/middlewares/quota@myprovider/quota",
+			conf: runtime.Configuration{
+				Middlewares: map[string]*runtime.MiddlewareInfo{
+					"quota@myprovider": newQuotaMiddlewareInfo(),
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				jsonFile:   "testdata/middleware-quota-usage.json",
+			},
+		},
+		{
+			desc: "quota usage of a middleware, that is not a quota",
+			path: "/api/http/middlewares/auth@myprovider/quota",
+			conf: runtime.Configuration{
+				Middlewares: map[string]*runtime.MiddlewareInfo{
+					"auth@myprovider": {
+						Middleware: &dynamic.Middleware{
+							BasicAuth: &dynamic.BasicAuth{
+								Users: []string{"admin:admin"},
+							},
+						},
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -990,3 +1022,29 @@ func generateHTTPRouters(nbRouters int) map[string]*runtime.RouterInfo {
 	}
 	return routers
 }
+
+func newQuotaMiddlewareInfo() *runtime.MiddlewareInfo {
+	info := &runtime.MiddlewareInfo{
+		Middleware: &dynamic.Middleware{
+			Quota: &dynamic.Quota{
+				Limit:  100,
+				Period: dynamic.QuotaPeriodDay,
+			},
+		},
+	}
+
+	info.SetQuotaUsage(func(_ context.Context) (*runtime.QuotaUsage, error) {
+		return &runtime.QuotaUsage{
+			Limit:       100,
+			Period:      dynamic.QuotaPeriodDay,
+			WindowStart: time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC),
+			WindowEnd:   time.Date(2023, time.June, 2, 0, 0, 0, 0, time.UTC),
+			Sources: map[string]int64{
+				"10.0.0.1": 42,
+				"10.0.0.2": 100,
+			},
+		}, nil
+	})
+
+	return info
+}
@@ -28,9 +28,10 @@ func newUDPRouterRepresentation(name string, rt *runtime.UDPRouterInfo) udpRoute
 
 type udpServiceRepresentation struct {
 	*runtime.UDPServiceInfo
-	Name     string `json:"name,omitempty"`
-	Provider string `json:"provider,omitempty"`
-	Type     string `json:"type,omitempty"`
+	ServerStatus map[string]string `json:"serverStatus,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	Provider     string            `json:"provider,omitempty"`
+	Type         string            `json:"type,omitempty"`
 }
 
 func newUDPServiceRepresentation(name string, si *runtime.UDPServiceInfo) udpServiceRepresentation {
@@ -38,6 +39,7 @@ func newUDPServiceRepresentation(name string, si *runtime.UDPServiceInfo) udpSer
 		UDPServiceInfo: si,
 		Name:           name,
 		Provider:       getProviderName(name),
+		ServerStatus:   si.GetAllStatus(),
 		Type:           strings.ToLower(extractType(si.UDPService)),
 	}
 }
@@ -15,6 +15,23 @@ const (
 	// DefaultHealthCheckTimeout is the default value for the ServerHealthCheck timeout.
 	DefaultHealthCheckTimeout = ptypes.Duration(5 * time.Second)
 
+	// DefaultPassiveHealthCheckFailureThreshold is the default value for the PassiveServerHealthCheck failure threshold.
+	DefaultPassiveHealthCheckFailureThreshold = 5
+	// DefaultPassiveHealthCheckBaseEjectionTime is the default value for the PassiveServerHealthCheck base ejection time.
+	DefaultPassiveHealthCheckBaseEjectionTime = ptypes.Duration(30 * time.Second)
+	// DefaultPassiveHealthCheckMaxEjectionTime is the default value for the PassiveServerHealthCheck max ejection time.
+	DefaultPassiveHealthCheckMaxEjectionTime = ptypes.Duration(300 * time.Second)
+
+	// DefaultSlowStartDuration is the default value for the SlowStart duration.
+	DefaultSlowStartDuration = ptypes.Duration(30 * time.Second)
+	// DefaultSlowStartAggression is the default value for the SlowStart aggression.
+	DefaultSlowStartAggression = 1.0
+	// DefaultSlowStartMinWeightPercent is the default value for the SlowStart min weight percent.
+	DefaultSlowStartMinWeightPercent = 10
+
+	// DefaultCoalescingMaxBodySize is the default value for the Coalescing max body size.
+	DefaultCoalescingMaxBodySize = 1024 * 1024
+
 	// DefaultPassHostHeader is the default value for the ServersLoadBalancer passHostHeader.
 	DefaultPassHostHeader = true
 
@@ -22,6 +39,20 @@ const (
 	DefaultFlushInterval = ptypes.Duration(100 * time.Millisecond)
 )
 
+// BalancerStrategy is the load-balancing algorithm used by a ServersLoadBalancer.
+type BalancerStrategy string
+
+const (
+	// BalancerStrategyWRR is the weighted round-robin strategy.
+	BalancerStrategyWRR BalancerStrategy = "wrr"
+	// BalancerStrategyLeastConn selects the server with the least outstanding requests.
+	BalancerStrategyLeastConn BalancerStrategy = "leastconn"
+	// BalancerStrategyP2C selects the least loaded of two servers picked at random (power of two random choices).
+	BalancerStrategyP2C BalancerStrategy = "p2c"
+	// BalancerStrategyHash selects the server from a consistent hash of a request key.
+	BalancerStrategyHash BalancerStrategy = "hash"
+)
+
 // +k8s:deepcopy-gen=true
 
 // HTTPConfiguration contains all the HTTP configuration parameters.
@@ -135,6 +166,23 @@ func (w *WRRService) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// HashPolicy holds the consistent hashing configuration.
+// The hash key defaults to the request's remote address.
+// All key sources are mutually exclusive.
+type HashPolicy struct {
+	// SourceCriterion defines what criterion is used to build the hash key.
+	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty" export:"true"`
+	// RequestCookieName defines the name of the cookie used as the hash key.
+	RequestCookieName string `json:"requestCookieName,omitempty" toml:"requestCookieName,omitempty" yaml:"requestCookieName,omitempty" export:"true"`
+	// RequestQueryParameterName defines the name of the query parameter used as the hash key.
+	RequestQueryParameterName string `json:"requestQueryParameterName,omitempty" toml:"requestQueryParameterName,omitempty" yaml:"requestQueryParameterName,omitempty" export:"true"`
+	// Replicas defines the number of points each server owns on the hash ring, per unit of weight.
+	// Defaults to 100.
+	Replicas int `json:"replicas,omitempty" toml:"replicas,omitempty" yaml:"replicas,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Sticky holds the sticky configuration.
 type Sticky struct {
 	// Cookie defines the sticky cookie configuration.
@@ -162,11 +210,26 @@ type Cookie struct {
 type ServersLoadBalancer struct {
 	Sticky  *Sticky  `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	Servers []Server `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
+	// Strategy defines the load-balancing algorithm used to select a server (wrr, leastconn, p2c or hash).
+	// Defaults to wrr.
+	Strategy BalancerStrategy `json:"strategy,omitempty" toml:"strategy,omitempty" yaml:"strategy,omitempty" export:"true"`
+	// Hash defines how the hash key of a request is built, when the hash strategy is used.
+	Hash *HashPolicy `json:"hash,omitempty" toml:"hash,omitempty" yaml:"hash,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	// HealthCheck enables regular active checks of the responsiveness of the
 	// children servers of this load-balancer. To propagate status changes (e.g. all
 	// servers of this service are down) upwards, HealthCheck must also be enabled on
 	// the parent(s) of this service.
-	HealthCheck        *ServerHealthCheck  `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" export:"true"`
+	HealthCheck *ServerHealthCheck `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" export:"true"`
+	// PassiveHealthCheck enables the ejection of the children servers of this load-balancer
+	// which fail to serve consecutive requests. As HealthCheck, it must also be enabled
+	// on the parent(s) of this service to propagate status changes upwards.
+	PassiveHealthCheck *PassiveServerHealthCheck `json:"passiveHealthCheck,omitempty" toml:"passiveHealthCheck,omitempty" yaml:"passiveHealthCheck,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// Coalescing collapses the concurrent identical GET requests into a single request to the servers,
+	// whose response is written to all of them.
+	Coalescing *Coalescing `json:"coalescing,omitempty" toml:"coalescing,omitempty" yaml:"coalescing,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// SlowStart defines how the weight of a newly added, or recovered, server ramps up
+	// to its configured weight, when the wrr strategy is used.
+	SlowStart          *SlowStart          `json:"slowStart,omitempty" toml:"slowStart,omitempty" yaml:"slowStart,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader" export:"true"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty" export:"true"`
 	ServersTransport   string              `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
@@ -257,6 +320,71 @@ func (h *ServerHealthCheck) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// PassiveServerHealthCheck holds the passive HealthCheck configuration.
+// A server is ejected from the load-balancer when it fails to serve consecutive requests,
+// i.e. when it answers with a 5XX status code or cannot be reached.
+type PassiveServerHealthCheck struct {
+	// FailureThreshold defines the number of consecutive failures after which the server is ejected.
+	FailureThreshold int `json:"failureThreshold,omitempty" toml:"failureThreshold,omitempty" yaml:"failureThreshold,omitempty" export:"true"`
+	// BaseEjectionTime defines how long a server is ejected for the first time.
+	// The ejection time grows with each new ejection of the server, and decreases back
+	// when the server stays healthy.
+	BaseEjectionTime ptypes.Duration `json:"baseEjectionTime,omitempty" toml:"baseEjectionTime,omitempty" yaml:"baseEjectionTime,omitempty" export:"true"`
+	// MaxEjectionTime defines the maximum duration of an ejection.
+	MaxEjectionTime ptypes.Duration `json:"maxEjectionTime,omitempty" toml:"maxEjectionTime,omitempty" yaml:"maxEjectionTime,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values for a PassiveServerHealthCheck.
+func (h *PassiveServerHealthCheck) SetDefaults() {
+	h.FailureThreshold = DefaultPassiveHealthCheckFailureThreshold
+	h.BaseEjectionTime = DefaultPassiveHealthCheckBaseEjectionTime
+	h.MaxEjectionTime = DefaultPassiveHealthCheckMaxEjectionTime
+}
+
+// +k8s:deepcopy-gen=true
+
+// SlowStart holds the slow start configuration.
+// During the slow start window, the effective weight of a server grows from
+// MinWeightPercent of its configured weight to its configured weight.
+type SlowStart struct {
+	// Duration defines the length of the slow start window.
+	Duration ptypes.Duration `json:"duration,omitempty" toml:"duration,omitempty" yaml:"duration,omitempty" export:"true"`
+	// Aggression defines the shape of the ramp: 1 is linear, greater values
+	// ramp up faster at the beginning of the window, lower values ramp up slower.
+	Aggression float64 `json:"aggression,omitempty" toml:"aggression,omitempty" yaml:"aggression,omitempty" export:"true"`
+	// MinWeightPercent defines the effective weight, in percent of the configured weight,
+	// of a server at the beginning of the slow start window.
+	MinWeightPercent int `json:"minWeightPercent,omitempty" toml:"minWeightPercent,omitempty" yaml:"minWeightPercent,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values for a SlowStart.
+func (s *SlowStart) SetDefaults() {
+	s.Duration = DefaultSlowStartDuration
+	s.Aggression = DefaultSlowStartAggression
+	s.MinWeightPercent = DefaultSlowStartMinWeightPercent
+}
+
+// +k8s:deepcopy-gen=true
+
+// Coalescing holds the request coalescing configuration.
+// While a GET request is being handled by the servers, the identical requests wait for its response
+// instead of being forwarded, and the response is written to all of them.
+type Coalescing struct {
+	// MaxBodySize defines the maximum size, in bytes, of a response written to the coalesced requests.
+	// When the response is larger, the waiting requests are forwarded to the servers on their own.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	// IdentityHeaders defines the request headers identifying the clients, in addition to Authorization, Proxy-Authorization and Cookie.
+	// A response is only shared between requests with the same values of these headers.
+	IdentityHeaders []string `json:"identityHeaders,omitempty" toml:"identityHeaders,omitempty" yaml:"identityHeaders,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values for a Coalescing.
+func (c *Coalescing) SetDefaults() {
+	c.MaxBodySize = DefaultCoalescingMaxBodySize
+}
+
+// +k8s:deepcopy-gen=true
+
 // HealthCheck controls healthcheck awareness and propagation at the services level.
 type HealthCheck struct{}
 
@@ -19,18 +19,27 @@ type Middleware struct {
 	ReplacePathRegex  *ReplacePathRegex  `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty" export:"true"`
 	Chain             *Chain             `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty" export:"true"`
 	IPAllowList       *IPAllowList       `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	IPDenyList        *IPDenyList        `json:"ipDenyList,omitempty" toml:"ipDenyList,omitempty" yaml:"ipDenyList,omitempty" export:"true"`
+	GeoIP             *GeoIP             `json:"geoIP,omitempty" toml:"geoIP,omitempty" yaml:"geoIP,omitempty" export:"true"`
 	Headers           *Headers           `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
 	Errors            *ErrorPage         `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty" export:"true"`
 	RateLimit         *RateLimit         `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+	Quota             *Quota             `json:"quota,omitempty" toml:"quota,omitempty" yaml:"quota,omitempty" export:"true"`
 	RedirectRegex     *RedirectRegex     `json:"redirectRegex,omitempty" toml:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty" export:"true"`
 	RedirectScheme    *RedirectScheme    `json:"redirectScheme,omitempty" toml:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty" export:"true"`
 	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty" export:"true"`
 	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty" export:"true"`
 	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty" export:"true"`
+	JWT               *JWT               `json:"jwt,omitempty" toml:"jwt,omitempty" yaml:"jwt,omitempty" export:"true"`
+	OIDC              *OIDC              `json:"oidc,omitempty" toml:"oidc,omitempty" yaml:"oidc,omitempty" export:"true"`
+	APIKey            *APIKey            `json:"apiKey,omitempty" toml:"apiKey,omitempty" yaml:"apiKey,omitempty" export:"true"`
 	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty" export:"true"`
 	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty" export:"true"`
+	BodyRewrite       *BodyRewrite       `json:"bodyRewrite,omitempty" toml:"bodyRewrite,omitempty" yaml:"bodyRewrite,omitempty" export:"true"`
+	Cache             *Cache             `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" export:"true"`
 	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty" export:"true"`
 	Compress          *Compress          `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	Decompress        *Decompress        `json:"decompress,omitempty" toml:"decompress,omitempty" yaml:"decompress,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty" export:"true"`
 	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty" export:"true"`
 	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
@@ -69,6 +78,34 @@ type AddPrefix struct {
 
 // +k8s:deepcopy-gen=true
 
+// APIKey holds the API key middleware configuration.
+// This middleware restricts access to your services to the requests bearing a known API key.
+type APIKey struct {
+	// Keys is an array of authorized keys.
+	// Each key must be declared using the owner:hashed-key format, where the key is hashed with bcrypt, or with SHA-256 ({SHA256} prefix).
+	// The bcrypt-hashed keys must be sent in the owner:key format.
+	Keys Users `json:"keys,omitempty" toml:"keys,omitempty" yaml:"keys,omitempty" loggable:"false"`
+	// KeysFile is the path to an external file that contains the authorized keys.
+	KeysFile string `json:"keysFile,omitempty" toml:"keysFile,omitempty" yaml:"keysFile,omitempty"`
+	// HeaderName defines the name of the request header containing the key.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty" export:"true"`
+	// QueryParam defines the name of the query parameter containing the key.
+	QueryParam string `json:"queryParam,omitempty" toml:"queryParam,omitempty" yaml:"queryParam,omitempty" export:"true"`
+	// CookieName defines the name of the cookie containing the key.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty" export:"true"`
+	// HeaderField defines a header field to store the owner of the key.
+	HeaderField string `json:"headerField,omitempty" toml:"headerField,omitempty" yaml:"headerField,omitempty" export:"true"`
+	// RemoveKey defines whether to remove the key from the request before forwarding it to the service.
+	RemoveKey bool `json:"removeKey,omitempty" toml:"removeKey,omitempty" yaml:"removeKey,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an APIKey.
+func (a *APIKey) SetDefaults() {
+	a.HeaderName = "X-API-Key"
+}
+
+// +k8s:deepcopy-gen=true
+
 // BasicAuth holds the basic auth middleware configuration.
 // This middleware restricts access to your services to known users.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/basicauth/
@@ -92,6 +129,59 @@ type BasicAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// BodyRewrite holds the body rewrite middleware configuration.
+// This middleware rewrites the request and response bodies with regular expressions and JSONPath operations.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/bodyrewrite/
+type BodyRewrite struct {
+	// Request defines the rewriting of the request bodies.
+	Request *BodyRewriteRules `json:"request,omitempty" toml:"request,omitempty" yaml:"request,omitempty" export:"true"`
+	// Response defines the rewriting of the response bodies.
+	Response *BodyRewriteRules `json:"response,omitempty" toml:"response,omitempty" yaml:"response,omitempty" export:"true"`
+	// MaxBodyBytes defines the maximum size (in bytes) of a body held in memory to be rewritten,
+	// i.e. of a JSON or compressed body, or of a line of a body only rewritten with regular expressions.
+	// The larger response bodies are forwarded as is.
+	// Default: 1048576.
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty" toml:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// BodyRewriteRules holds the rewriting rules of the request or response bodies.
+type BodyRewriteRules struct {
+	// ContentTypes defines the media types of the bodies to rewrite.
+	// A media type can have a wildcard subtype, such as text/*.
+	// Default: text/*, application/json.
+	ContentTypes []string `json:"contentTypes,omitempty" toml:"contentTypes,omitempty" yaml:"contentTypes,omitempty" export:"true"`
+	// Replacements defines the regular expression replacements, applied in order to the bodies.
+	Replacements []BodyReplacement `json:"replacements,omitempty" toml:"replacements,omitempty" yaml:"replacements,omitempty" export:"true"`
+	// JSONOperations defines the JSONPath operations, applied in order to the JSON bodies, before the replacements.
+	JSONOperations []JSONOperation `json:"jsonOperations,omitempty" toml:"jsonOperations,omitempty" yaml:"jsonOperations,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// BodyReplacement holds a regular expression replacement of the body rewrite middleware.
+type BodyReplacement struct {
+	// Regex defines the regular expression matching the parts of the body to replace.
+	Regex string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty" export:"true"`
+	// Replacement defines the replacement, which can include captured variables.
+	Replacement string `json:"replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// JSONOperation holds a JSONPath operation of the body rewrite middleware.
+type JSONOperation struct {
+	// Op defines the operation, either set or delete.
+	Op string `json:"op,omitempty" toml:"op,omitempty" yaml:"op,omitempty" export:"true"`
+	// Path defines the JSONPath expression of the values to set or delete, such as $.items[*].internalId.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty" export:"true"`
+	// Value defines the JSON encoded value to set.
+	Value string `json:"value,omitempty" toml:"value,omitempty" yaml:"value,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Buffering holds the buffering middleware configuration.
 // This middleware retries or limits the size of requests that can be forwarded to backends.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/buffering/#maxrequestbodybytes
@@ -118,6 +208,52 @@ type Buffering struct {
 
 // +k8s:deepcopy-gen=true
 
+// Cache holds the cache middleware configuration.
+// This middleware stores the responses of the backends, and serves them to the subsequent requests
+// while they are fresh, following the HTTP caching semantics (RFC 9111).
+type Cache struct {
+	// MaxEntries defines the maximum number of cached responses.
+	// The least recently used responses are evicted first.
+	// Default: 1000.
+	MaxEntries int `json:"maxEntries,omitempty" toml:"maxEntries,omitempty" yaml:"maxEntries,omitempty" export:"true"`
+	// MaxBodyBytes defines the maximum size (in bytes) of the body of a cached response.
+	// The larger responses are forwarded to the client without being cached.
+	// Default: 1048576 (1Mi).
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty" toml:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty" export:"true"`
+	// MaxMemory defines the maximum size (in bytes) of the responses stored in memory.
+	// The least recently used responses are evicted first. It does not apply to the on-disk store.
+	// Default: 67108864 (64Mi).
+	MaxMemory int64 `json:"maxMemory,omitempty" toml:"maxMemory,omitempty" yaml:"maxMemory,omitempty" export:"true"`
+	// DefaultTTL defines how long a cacheable response without explicit expiration time is considered fresh.
+	// Default: 0 (such responses are only reused after a revalidation).
+	DefaultTTL ptypes.Duration `json:"defaultTTL,omitempty" toml:"defaultTTL,omitempty" yaml:"defaultTTL,omitempty" export:"true"`
+	// StatusHeader defines the name of the response header reporting the cache status of the responses.
+	// Default: X-Cache-Status.
+	StatusHeader string `json:"statusHeader,omitempty" toml:"statusHeader,omitempty" yaml:"statusHeader,omitempty" export:"true"`
+	// Disk defines the on-disk store of the cached responses.
+	// If not set, the responses are only stored in memory.
+	Disk *CacheDisk `json:"disk,omitempty" toml:"disk,omitempty" yaml:"disk,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Cache.
+func (c *Cache) SetDefaults() {
+	c.MaxEntries = 1000
+	c.MaxBodyBytes = 1024 * 1024
+	c.MaxMemory = 64 * 1024 * 1024
+	c.StatusHeader = "X-Cache-Status"
+}
+
+// +k8s:deepcopy-gen=true
+
+// CacheDisk holds the configuration of the on-disk store of the Cache middleware.
+type CacheDisk struct {
+	// Path defines the directory where the responses are stored. It must not be shared with another Cache middleware.
+	// The responses stored in the directory are reused after a restart.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Chain holds the chain middleware configuration.
 // This middleware enables to define reusable combinations of other pieces of middleware.
 type Chain struct {
@@ -151,7 +287,7 @@ func (c *CircuitBreaker) SetDefaults() {
 // +k8s:deepcopy-gen=true
 
 // Compress holds the compress middleware configuration.
-// This middleware compresses responses before sending them to the client, using gzip compression.
+// This middleware compresses responses before sending them to the client, using zstd, brotli or gzip compression.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/compress/
 type Compress struct {
 	// ExcludedContentTypes defines the list of content types to compare the Content-Type header of the incoming requests and responses before compressing.
@@ -160,6 +296,37 @@ type Compress struct {
 	// MinResponseBodyBytes defines the minimum amount of bytes a response body must have to be compressed.
 	// Default: 1024.
 	MinResponseBodyBytes int `json:"minResponseBodyBytes,omitempty" toml:"minResponseBodyBytes,omitempty" yaml:"minResponseBodyBytes,omitempty" export:"true"`
+	// Encodings defines the list of supported encodings (zstd, br and gzip), in the order of preference.
+	// The preference order breaks the ties between the encodings accepted by the client with the same quality value.
+	// Default: br, gzip, zstd.
+	Encodings []string `json:"encodings,omitempty" toml:"encodings,omitempty" yaml:"encodings,omitempty" export:"true"`
+	// Levels defines the compression level of each encoding.
+	Levels *CompressLevels `json:"levels,omitempty" toml:"levels,omitempty" yaml:"levels,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// CompressLevels holds the compression level of each encoding of the compress middleware.
+// The default level of an encoding is used when its level is not set.
+type CompressLevels struct {
+	// Zstd defines the zstd compression level, from 1 to 22.
+	Zstd int `json:"zstd,omitempty" toml:"zstd,omitempty" yaml:"zstd,omitempty" export:"true"`
+	// Brotli defines the brotli compression level, from 1 to 11.
+	Brotli int `json:"brotli,omitempty" toml:"brotli,omitempty" yaml:"brotli,omitempty" export:"true"`
+	// Gzip defines the gzip compression level, from 1 to 9.
+	Gzip int `json:"gzip,omitempty" toml:"gzip,omitempty" yaml:"gzip,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Decompress holds the decompress middleware configuration.
+// This middleware decompresses the request bodies encoded with gzip, brotli or zstd compression, before forwarding them.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/decompress/
+type Decompress struct {
+	// MaxDecompressedBodyBytes defines the maximum size (in bytes) of a decompressed request body.
+	// The requests whose body is larger once decompressed are rejected.
+	// Default: 10485760.
+	MaxDecompressedBodyBytes int64 `json:"maxDecompressedBodyBytes,omitempty" toml:"maxDecompressedBodyBytes,omitempty" yaml:"maxDecompressedBodyBytes,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -221,6 +388,127 @@ type ForwardAuth struct {
 	// AuthRequestHeaders defines the list of the headers to copy from the request to the authentication server.
 	// If not set or empty then all request headers are passed.
 	AuthRequestHeaders []string `json:"authRequestHeaders,omitempty" toml:"authRequestHeaders,omitempty" yaml:"authRequestHeaders,omitempty" export:"true"`
+	// Cache defines the caching of the authentication server responses.
+	// If not set, the authentication server is called for every request.
+	Cache *ForwardAuthCache `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" export:"true"`
+	// ExtAuthz defines whether to call the authentication server with the Envoy ext_authz gRPC protocol, instead of HTTP.
+	// The Address is then the gRPC address (host:port) of the authentication server.
+	ExtAuthz *ForwardAuthExtAuthz `json:"extAuthz,omitempty" toml:"extAuthz,omitempty" yaml:"extAuthz,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardAuthExtAuthz holds the configuration of the Envoy ext_authz gRPC protocol of the ForwardAuth middleware.
+type ForwardAuthExtAuthz struct {
+	// ContextExtensions defines the context extensions sent to the authentication server.
+	ContextExtensions map[string]string `json:"contextExtensions,omitempty" toml:"contextExtensions,omitempty" yaml:"contextExtensions,omitempty" export:"true"`
+	// Timeout defines the maximum duration of a call to the authentication server. Default: 30s.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a ForwardAuthExtAuthz.
+func (f *ForwardAuthExtAuthz) SetDefaults() {
+	f.Timeout = ptypes.Duration(30 * time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardAuthCache holds the configuration of the ForwardAuth response cache.
+type ForwardAuthCache struct {
+	// TTL defines how long an authentication server response is cached.
+	TTL ptypes.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty" export:"true"`
+	// MaxEntries defines the maximum number of cached responses.
+	MaxEntries int `json:"maxEntries,omitempty" toml:"maxEntries,omitempty" yaml:"maxEntries,omitempty" export:"true"`
+	// KeyHeaders defines the headers, sent to the authentication server, used to build the cache key.
+	KeyHeaders []string `json:"keyHeaders,omitempty" toml:"keyHeaders,omitempty" yaml:"keyHeaders,omitempty" export:"true"`
+	// KeyCookies defines the cookies, sent to the authentication server, used to build the cache key.
+	KeyCookies []string `json:"keyCookies,omitempty" toml:"keyCookies,omitempty" yaml:"keyCookies,omitempty" export:"true"`
+	// IgnoreURI defines whether the requested URI (X-Forwarded-Uri) is left out of the cache key,
+	// so that a response is reused for all the paths of a host.
+	IgnoreURI bool `json:"ignoreURI,omitempty" toml:"ignoreURI,omitempty" yaml:"ignoreURI,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a ForwardAuthCache.
+func (f *ForwardAuthCache) SetDefaults() {
+	f.TTL = ptypes.Duration(30 * time.Second)
+	f.MaxEntries = 10000
+}
+
+// +k8s:deepcopy-gen=true
+
+// JWT holds the JWT middleware configuration.
+// This middleware validates the JSON Web Token of the requests, and forwards selected claims as headers.
+type JWT struct {
+	// Secret defines the secret used to validate the tokens signed with an HMAC algorithm (HS256, HS384 and HS512).
+	Secret string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" loggable:"false"`
+	// PublicKeys defines the PEM-encoded public keys, or certificates, used to validate the tokens signed with an RSA, ECDSA or EdDSA algorithm.
+	PublicKeys []string `json:"publicKeys,omitempty" toml:"publicKeys,omitempty" yaml:"publicKeys,omitempty"`
+	// JWKSURL defines the URL of the JSON Web Key Set used to validate the tokens.
+	JWKSURL string `json:"jwksURL,omitempty" toml:"jwksURL,omitempty" yaml:"jwksURL,omitempty"`
+	// JWKSRefreshInterval defines how often the JSON Web Key Set is fetched again.
+	// It is also fetched again when a token is signed with an unknown key.
+	JWKSRefreshInterval ptypes.Duration `json:"jwksRefreshInterval,omitempty" toml:"jwksRefreshInterval,omitempty" yaml:"jwksRefreshInterval,omitempty" export:"true"`
+	// Issuer defines the expected value of the iss claim.
+	Issuer string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// Audience defines the accepted values of the aud claim. The token must have at least one of them.
+	Audience []string `json:"audience,omitempty" toml:"audience,omitempty" yaml:"audience,omitempty"`
+	// ClockSkew defines the tolerance applied when checking the exp and nbf claims.
+	ClockSkew ptypes.Duration `json:"clockSkew,omitempty" toml:"clockSkew,omitempty" yaml:"clockSkew,omitempty" export:"true"`
+	// ForwardClaims defines the request headers to set from the token claims, as header names mapped to claim names.
+	ForwardClaims map[string]string `json:"forwardClaims,omitempty" toml:"forwardClaims,omitempty" yaml:"forwardClaims,omitempty" export:"true"`
+	// RemoveHeader defines whether to remove the Authorization header before forwarding the request to the service.
+	RemoveHeader bool `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a JWT.
+func (j *JWT) SetDefaults() {
+	j.JWKSRefreshInterval = ptypes.Duration(15 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDC holds the OpenID Connect middleware configuration.
+// This middleware authenticates the users against an OpenID Connect provider, with the authorization code flow and PKCE,
+// and forwards their identity as headers.
+type OIDC struct {
+	// Issuer defines the URL of the OpenID Connect provider, used to discover its endpoints.
+	Issuer string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// ClientID defines the client ID of the application registered on the provider.
+	ClientID string `json:"clientID,omitempty" toml:"clientID,omitempty" yaml:"clientID,omitempty"`
+	// ClientSecret defines the client secret of the application registered on the provider.
+	ClientSecret string `json:"clientSecret,omitempty" toml:"clientSecret,omitempty" yaml:"clientSecret,omitempty" loggable:"false"`
+	// Scopes defines the scopes requested to the provider.
+	Scopes []string `json:"scopes,omitempty" toml:"scopes,omitempty" yaml:"scopes,omitempty" export:"true"`
+	// CallbackPath defines the path of the redirection URL, where the provider sends back the users once authenticated.
+	CallbackPath string `json:"callbackPath,omitempty" toml:"callbackPath,omitempty" yaml:"callbackPath,omitempty" export:"true"`
+	// RedirectURL defines the absolute redirection URL registered on the provider.
+	// If set, the callback is served on its path, instead of the CallbackPath one.
+	// Otherwise, the redirection URL is built from the request host and scheme, and the CallbackPath.
+	RedirectURL string `json:"redirectURL,omitempty" toml:"redirectURL,omitempty" yaml:"redirectURL,omitempty"`
+	// LogoutPath defines the path where the users are logged out.
+	LogoutPath string `json:"logoutPath,omitempty" toml:"logoutPath,omitempty" yaml:"logoutPath,omitempty" export:"true"`
+	// SessionSecret defines the secret used to encrypt the session cookie.
+	SessionSecret string `json:"sessionSecret,omitempty" toml:"sessionSecret,omitempty" yaml:"sessionSecret,omitempty" loggable:"false"`
+	// SessionCookieName defines the name of the session cookie.
+	SessionCookieName string `json:"sessionCookieName,omitempty" toml:"sessionCookieName,omitempty" yaml:"sessionCookieName,omitempty" export:"true"`
+	// SessionCookieDomain defines the domain of the session cookie.
+	SessionCookieDomain string `json:"sessionCookieDomain,omitempty" toml:"sessionCookieDomain,omitempty" yaml:"sessionCookieDomain,omitempty"`
+	// SessionMaxAge defines the maximum lifetime of a session, after which the users are authenticated again.
+	SessionMaxAge ptypes.Duration `json:"sessionMaxAge,omitempty" toml:"sessionMaxAge,omitempty" yaml:"sessionMaxAge,omitempty" export:"true"`
+	// TLS defines the configuration used to secure the connection to the provider.
+	TLS *types.ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	// ForwardClaims defines the request headers to set from the ID token claims, as header names mapped to claim names.
+	ForwardClaims map[string]string `json:"forwardClaims,omitempty" toml:"forwardClaims,omitempty" yaml:"forwardClaims,omitempty" export:"true"`
+	// ForwardAccessToken defines whether to forward the access token to the service, in the Authorization header.
+	ForwardAccessToken bool `json:"forwardAccessToken,omitempty" toml:"forwardAccessToken,omitempty" yaml:"forwardAccessToken,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an OIDC.
+func (o *OIDC) SetDefaults() {
+	o.Scopes = []string{"openid", "profile", "email"}
+	o.CallbackPath = "/oidc/callback"
+	o.SessionCookieName = "_traefik_oidc"
+	o.SessionMaxAge = ptypes.Duration(24 * time.Hour)
 }
 
 // +k8s:deepcopy-gen=true
@@ -387,6 +675,50 @@ type IPAllowList struct {
 
 // +k8s:deepcopy-gen=true
 
+// IPDenyList holds the IP denylist middleware configuration.
+// This middleware refuses the requests from the given IPs, and accepts the others.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/ipdenylist/
+type IPDenyList struct {
+	// SourceRange defines the set of denied IPs (or ranges of denied IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// SourceRangeFile defines the path to a file listing additional denied IPs (or ranges of denied IPs), one per line.
+	// The file is watched, and reloaded when it changes.
+	SourceRangeFile string      `json:"sourceRangeFile,omitempty" toml:"sourceRangeFile,omitempty" yaml:"sourceRangeFile,omitempty"`
+	IPStrategy      *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// GeoIP holds the GeoIP middleware configuration.
+// This middleware accepts / refuses requests based on the country and the autonomous system of the client IP,
+// looked up in databases in the MaxMind DB format, such as the GeoLite2 and GeoIP2 databases.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/geoip/
+type GeoIP struct {
+	// CountryDatabase defines the path to the database used to look up the country of the client IP (e.g. GeoLite2-Country.mmdb).
+	// The file is watched, and reloaded when it changes.
+	CountryDatabase string `json:"countryDatabase,omitempty" toml:"countryDatabase,omitempty" yaml:"countryDatabase,omitempty"`
+	// ASNDatabase defines the path to the database used to look up the autonomous system of the client IP (e.g. GeoLite2-ASN.mmdb).
+	// The file is watched, and reloaded when it changes.
+	ASNDatabase string `json:"asnDatabase,omitempty" toml:"asnDatabase,omitempty" yaml:"asnDatabase,omitempty"`
+	// AllowedCountries defines the ISO 3166-1 alpha-2 codes of the allowed countries.
+	// When defined, the requests from the other countries, or from an unknown country, are refused.
+	AllowedCountries []string `json:"allowedCountries,omitempty" toml:"allowedCountries,omitempty" yaml:"allowedCountries,omitempty" export:"true"`
+	// DeniedCountries defines the ISO 3166-1 alpha-2 codes of the refused countries.
+	DeniedCountries []string `json:"deniedCountries,omitempty" toml:"deniedCountries,omitempty" yaml:"deniedCountries,omitempty" export:"true"`
+	// AllowedASNs defines the numbers of the allowed autonomous systems.
+	// When defined, the requests from the other autonomous systems, or from an unknown one, are refused.
+	AllowedASNs []uint `json:"allowedASNs,omitempty" toml:"allowedASNs,omitempty" yaml:"allowedASNs,omitempty" export:"true"`
+	// DeniedASNs defines the numbers of the refused autonomous systems.
+	DeniedASNs []uint `json:"deniedASNs,omitempty" toml:"deniedASNs,omitempty" yaml:"deniedASNs,omitempty" export:"true"`
+	// CountryHeader defines the name of the header set with the country code of the client IP for the backends (e.g. X-Geo-Country).
+	CountryHeader string `json:"countryHeader,omitempty" toml:"countryHeader,omitempty" yaml:"countryHeader,omitempty" export:"true"`
+	// ASNHeader defines the name of the header set with the autonomous system number of the client IP for the backends (e.g. X-Geo-ASN).
+	ASNHeader  string      `json:"asnHeader,omitempty" toml:"asnHeader,omitempty" yaml:"asnHeader,omitempty" export:"true"`
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // InFlightReq holds the in-flight request middleware configuration.
 // This middleware limits the number of requests being processed and served concurrently.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/inflightreq/
@@ -426,6 +758,68 @@ type SourceCriterion struct {
 	RequestHost bool `json:"requestHost,omitempty" toml:"requestHost,omitempty" yaml:"requestHost,omitempty" export:"true"`
 }
 
+// Quota periods.
+const (
+	QuotaPeriodMinute = "minute"
+	QuotaPeriodHour   = "hour"
+	QuotaPeriodDay    = "day"
+	QuotaPeriodMonth  = "month"
+)
+
+// +k8s:deepcopy-gen=true
+
+// Quota holds the quota middleware configuration.
+// This middleware limits the number of requests allowed for a given source over fixed calendar windows.
+type Quota struct {
+	// Limit is the maximum number of requests allowed for the given source in a window.
+	Limit int64 `json:"limit,omitempty" toml:"limit,omitempty" yaml:"limit,omitempty" export:"true"`
+
+	// Period defines the length of the windows: minute, hour, day (default) or month.
+	// The windows are aligned on the calendar, in UTC.
+	Period string `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+
+	// SourceCriterion defines what criterion is used to group requests as originating from a common source.
+	// If several strategies are defined at the same time, an error will be raised.
+	// If none are set, the default is to use the request's remote address field (as an ipStrategy).
+	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty" export:"true"`
+
+	// MaxSources defines the maximum number of sources counted in a window (default 100000).
+	// Once it is reached, the requests of the new sources are rejected until the next window.
+	MaxSources int `json:"maxSources,omitempty" toml:"maxSources,omitempty" yaml:"maxSources,omitempty" export:"true"`
+
+	// Store defines where the request counts are persisted, so that they survive restarts.
+	// If not set, the request counts are only stored in memory.
+	Store *QuotaStore `json:"store,omitempty" toml:"store,omitempty" yaml:"store,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Quota.
+func (q *Quota) SetDefaults() {
+	q.Period = QuotaPeriodDay
+	q.MaxSources = 100000
+}
+
+// +k8s:deepcopy-gen=true
+
+// QuotaStore holds the configuration of the persistent store of the Quota middleware.
+type QuotaStore struct {
+	File *QuotaFileStore `json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// QuotaFileStore holds the configuration of a store persisting the request counts in a file.
+type QuotaFileStore struct {
+	// Path defines the path of the file. It must not be shared with another Quota middleware.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`
+	// FlushInterval defines the maximum duration during which the request counts are not yet written to the file.
+	FlushInterval ptypes.Duration `json:"flushInterval,omitempty" toml:"flushInterval,omitempty" yaml:"flushInterval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a QuotaFileStore.
+func (q *QuotaFileStore) SetDefaults() {
+	q.FlushInterval = ptypes.Duration(time.Second)
+}
+
 // +k8s:deepcopy-gen=true
 
 // RateLimit holds the rate limit configuration.
@@ -449,6 +843,19 @@ type RateLimit struct {
 	// If several strategies are defined at the same time, an error will be raised.
 	// If none are set, the default is to use the request's remote address field (as an ipStrategy).
 	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty" export:"true"`
+
+	// Redis defines the Redis server used to store the token buckets,
+	// in order to share the rate limit between several Traefik instances.
+	// If not set, the token buckets are stored in memory.
+	Redis *Redis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" export:"true"`
+
+	// Headers defines whether to add the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers to the responses,
+	// computed from the state of the token bucket of the request source.
+	Headers bool `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
+
+	// Rejection defines the response served when a request is rejected.
+	// It defaults to a 429 Too Many Requests response.
+	Rejection *RateLimitRejection `json:"rejection,omitempty" toml:"rejection,omitempty" yaml:"rejection,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values on a RateLimit.
@@ -459,6 +866,61 @@ func (r *RateLimit) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// RateLimitRejection holds the configuration of the response served when a request is rejected by the RateLimit middleware.
+type RateLimitRejection struct {
+	// StatusCode defines the status code of the response. It defaults to 429.
+	StatusCode int `json:"statusCode,omitempty" toml:"statusCode,omitempty" yaml:"statusCode,omitempty" export:"true"`
+	// Body defines the body of the response.
+	Body string `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty"`
+	// ContentType defines the content type of the response body. It defaults to text/plain; charset=utf-8.
+	ContentType string `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty" export:"true"`
+	// Service defines the name of the service that will serve the response, instead of Body.
+	Service string `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	// Query defines the URL of the response (hosted by service).
+	// The {status} variable can be used in order to insert the status code in the URL.
+	Query string `json:"query,omitempty" toml:"query,omitempty" yaml:"query,omitempty" export:"true"`
+}
+
+// Redis failure policies.
+const (
+	// RedisFailurePolicyAllow allows the requests when Redis is unreachable.
+	RedisFailurePolicyAllow = "allow"
+	// RedisFailurePolicyDeny rejects the requests when Redis is unreachable.
+	RedisFailurePolicyDeny = "deny"
+	// RedisFailurePolicyLocal falls back to in-memory token buckets when Redis is unreachable.
+	RedisFailurePolicyLocal = "local"
+)
+
+// +k8s:deepcopy-gen=true
+
+// Redis holds the Redis configuration.
+type Redis struct {
+	// Endpoints defines the endpoints of the Redis server, or of the nodes of the Redis cluster.
+	Endpoints []string `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// TLS defines the TLS configuration used for the connection to Redis.
+	TLS *types.ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
+	// Username defines the username used to authenticate with Redis.
+	Username string `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty" loggable:"false"`
+	// Password defines the password used to authenticate with Redis.
+	Password string `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" loggable:"false"`
+	// DB defines the Redis database to select.
+	DB int `json:"db,omitempty" toml:"db,omitempty" yaml:"db,omitempty"`
+	// Timeout defines the maximum duration of an operation on Redis.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	// FailurePolicy defines how requests are handled when Redis is unreachable:
+	// allow lets them through, deny rejects them, and local (default) applies the rate limit with in-memory token buckets.
+	FailurePolicy string `json:"failurePolicy,omitempty" toml:"failurePolicy,omitempty" yaml:"failurePolicy,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Redis.
+func (r *Redis) SetDefaults() {
+	r.Endpoints = []string{"localhost:6379"}
+	r.Timeout = ptypes.Duration(500 * time.Millisecond)
+	r.FailurePolicy = RedisFailurePolicyLocal
+}
+
+// +k8s:deepcopy-gen=true
+
 // RedirectRegex holds the redirect regex middleware configuration.
 // This middleware redirects a request using regex matching and replacement.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/redirectregex/#regex
@@ -1,11 +1,20 @@
 package dynamic
 
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
 // +k8s:deepcopy-gen=true
 
 // TCPMiddleware holds the TCPMiddleware configuration.
 type TCPMiddleware struct {
 	InFlightConn *TCPInFlightConn `json:"inFlightConn,omitempty" toml:"inFlightConn,omitempty" yaml:"inFlightConn,omitempty" export:"true"`
 	IPAllowList  *TCPIPAllowList  `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	IPDenyList   *TCPIPDenyList   `json:"ipDenyList,omitempty" toml:"ipDenyList,omitempty" yaml:"ipDenyList,omitempty" export:"true"`
+	RateLimit    *TCPRateLimit    `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+	Timeout      *TCPTimeout      `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -28,3 +37,62 @@ type TCPIPAllowList struct {
 	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
 	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
 }
+
+// +k8s:deepcopy-gen=true
+
+// TCPIPDenyList holds the TCP IPDenyList middleware configuration.
+// This middleware refuses the connections from the given IPs, and accepts the others.
+type TCPIPDenyList struct {
+	// SourceRange defines the denied IPs (or ranges of denied IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// SourceRangeFile defines the path to a file listing additional denied IPs (or ranges of denied IPs), one per line.
+	// The file is watched, and reloaded when it changes.
+	SourceRangeFile string `json:"sourceRangeFile,omitempty" toml:"sourceRangeFile,omitempty" yaml:"sourceRangeFile,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPRateLimit holds the TCP RateLimit middleware configuration.
+// This middleware limits the rate of the new connections for one IP,
+// and optionally the throughput of each connection.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/tcp/ratelimit/
+type TCPRateLimit struct {
+	// Average is the maximum rate, by default in connections/s, allowed for one IP.
+	// It defaults to 0, which means no rate limiting of the new connections.
+	// The rate is actually defined by dividing Average by Period. So for a rate below 1conn/s,
+	// one needs to define a Period larger than a second.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+
+	// Burst is the maximum number of connections allowed to be opened in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+
+	// BytesPerSecond is the maximum throughput of each connection, in bytes/s, in each direction.
+	// It defaults to 0, which means no throttling of the connections.
+	BytesPerSecond int64 `json:"bytesPerSecond,omitempty" toml:"bytesPerSecond,omitempty" yaml:"bytesPerSecond,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPRateLimit.
+func (r *TCPRateLimit) SetDefaults() {
+	r.Burst = 1
+	r.Period = ptypes.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPTimeout holds the TCP Timeout middleware configuration.
+// This middleware closes the connections which are idle for too long, or which are opened for too long.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/tcp/timeout/
+type TCPTimeout struct {
+	// IdleTimeout is the maximum duration without traffic in either direction, after which the connection is closed.
+	// It defaults to 0, which means no idle timeout.
+	IdleTimeout ptypes.Duration `json:"idleTimeout,omitempty" toml:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty" export:"true"`
+
+	// MaxLifetime is the maximum duration of the connection, after which it is closed, even if it is not idle.
+	// It defaults to 0, which means no maximum duration.
+	MaxLifetime ptypes.Duration `json:"maxLifetime,omitempty" toml:"maxLifetime,omitempty" yaml:"maxLifetime,omitempty" export:"true"`
+}
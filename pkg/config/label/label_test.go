@@ -125,6 +125,10 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.middlewares.Middleware17.stripprefix.prefixes":                               "foobar, fiibar",
 		"traefik.http.middlewares.Middleware18.stripprefixregex.regex":                             "foobar, fiibar",
 		"traefik.http.middlewares.Middleware19.compress.minresponsebodybytes":                      "42",
+		"traefik.http.middlewares.Middleware19.compress.encodings":                                 "foobar, fiibar",
+		"traefik.http.middlewares.Middleware19.compress.levels.zstd":                               "42",
+		"traefik.http.middlewares.Middleware19.compress.levels.brotli":                             "42",
+		"traefik.http.middlewares.Middleware19.compress.levels.gzip":                               "42",
 		"traefik.http.middlewares.Middleware20.plugin.tomato.aaa":                                  "foo1",
 		"traefik.http.middlewares.Middleware20.plugin.tomato.bbb":                                  "foo2",
 		"traefik.http.routers.Router0.entrypoints":                                                 "foobar, fiibar",
@@ -200,8 +204,12 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.tcp.services.Service1.loadbalancer.serversTransport":      "foo",
 
 		"traefik.udp.routers.Router0.entrypoints":                "foobar, fiibar",
+		"traefik.udp.routers.Router0.rule":                       "foobar",
+		"traefik.udp.routers.Router0.priority":                   "42",
 		"traefik.udp.routers.Router0.service":                    "foobar",
 		"traefik.udp.routers.Router1.entrypoints":                "foobar, fiibar",
+		"traefik.udp.routers.Router1.rule":                       "foobar",
+		"traefik.udp.routers.Router1.priority":                   "42",
 		"traefik.udp.routers.Router1.service":                    "foobar",
 		"traefik.udp.services.Service0.loadbalancer.server.Port": "42",
 		"traefik.udp.services.Service1.loadbalancer.server.Port": "42",
@@ -284,14 +292,18 @@ func TestDecodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 				},
 				"Router1": {
 					EntryPoints: []string{
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 				},
 			},
 			Services: map[string]*dynamic.UDPService{
@@ -471,6 +483,12 @@ func TestDecodeConfiguration(t *testing.T) {
 				"Middleware19": {
 					Compress: &dynamic.Compress{
 						MinResponseBodyBytes: 42,
+						Encodings:            []string{"foobar", "fiibar"},
+						Levels: &dynamic.CompressLevels{
+							Zstd:   42,
+							Brotli: 42,
+							Gzip:   42,
+						},
 					},
 				},
 				"Middleware2": {
@@ -777,14 +795,18 @@ func TestEncodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 				},
 				"Router1": {
 					EntryPoints: []string{
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 				},
 			},
 			Services: map[string]*dynamic.UDPService{
@@ -963,6 +985,12 @@ func TestEncodeConfiguration(t *testing.T) {
 				"Middleware19": {
 					Compress: &dynamic.Compress{
 						MinResponseBodyBytes: 42,
+						Encodings:            []string{"foobar", "fiibar"},
+						Levels: &dynamic.CompressLevels{
+							Zstd:   42,
+							Brotli: 42,
+							Gzip:   42,
+						},
 					},
 				},
 				"Middleware2": {
@@ -1283,6 +1311,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Average":                                  "42",
 		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Period":                                   "1000000000",
 		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Burst":                                    "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Headers":                                  "false",
 		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHeaderName":        "foobar",
 		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHost":              "true",
 		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.IPStrategy.Depth":         "42",
@@ -1301,6 +1330,10 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.Prefixes":                               "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                             "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware19.Compress.MinResponseBodyBytes":                      "42",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.Encodings":                                 "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.Levels.Zstd":                               "42",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.Levels.Brotli":                             "42",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.Levels.Gzip":                               "42",
 		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.aaa":                                  "foo1",
 		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.bbb":                                  "foo2",
 
@@ -1372,8 +1405,12 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.TCP.Services.Service1.LoadBalancer.ServersTransport": "foo",
 
 		"traefik.UDP.Routers.Router0.EntryPoints":                "foobar, fiibar",
+		"traefik.UDP.Routers.Router0.Priority":                   "42",
+		"traefik.UDP.Routers.Router0.Rule":                       "foobar",
 		"traefik.UDP.Routers.Router0.Service":                    "foobar",
 		"traefik.UDP.Routers.Router1.EntryPoints":                "foobar, fiibar",
+		"traefik.UDP.Routers.Router1.Priority":                   "42",
+		"traefik.UDP.Routers.Router1.Rule":                       "foobar",
 		"traefik.UDP.Routers.Router1.Service":                    "foobar",
 		"traefik.UDP.Services.Service0.LoadBalancer.server.Port": "42",
 		"traefik.UDP.Services.Service1.LoadBalancer.server.Port": "42",
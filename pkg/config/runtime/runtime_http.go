@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"traefik/v3/pkg/config/dynamic"
@@ -108,6 +109,44 @@ type MiddlewareInfo struct {
 	Err    []string `json:"error,omitempty"`
 	Status string   `json:"status,omitempty"`
 	UsedBy []string `json:"usedBy,omitempty"` // list of routers and services using that middleware.
+
+	quotaUsageMu sync.RWMutex
+	quotaUsage   QuotaUsageFunc
+}
+
+// QuotaUsage is the usage of a quota middleware over its current window.
+type QuotaUsage struct {
+	Limit       int64            `json:"limit"`
+	Period      string           `json:"period"`
+	WindowStart time.Time        `json:"windowStart"`
+	WindowEnd   time.Time        `json:"windowEnd"`
+	Sources     map[string]int64 `json:"sources,omitempty"` // request counts keyed by source
+}
+
+// QuotaUsageFunc returns the current usage of a quota middleware.
+type QuotaUsageFunc func(ctx context.Context) (*QuotaUsage, error)
+
+// SetQuotaUsage sets the function reporting the usage of the quota middleware.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *MiddlewareInfo) SetQuotaUsage(usage QuotaUsageFunc) {
+	m.quotaUsageMu.Lock()
+	defer m.quotaUsageMu.Unlock()
+
+	m.quotaUsage = usage
+}
+
+// GetQuotaUsage returns the current usage of the quota middleware, or nil if m is not a quota middleware in use.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *MiddlewareInfo) GetQuotaUsage(ctx context.Context) (*QuotaUsage, error) {
+	m.quotaUsageMu.RLock()
+	usage := m.quotaUsage
+	m.quotaUsageMu.RUnlock()
+
+	if usage == nil {
+		return nil, nil
+	}
+
+	return usage(ctx)
 }
 
 // AddError adds err to s.Err, if it does not already exist.
@@ -36,6 +36,13 @@ type StatusUpdater interface {
 	RegisterStatusUpdater(fn func(up bool)) error
 }
 
+// statusCombiner is implemented by the StatusSetters combining the status set by the active health check
+// with another source of status, such as the PassiveHealthChecker.
+type statusCombiner interface {
+	// combinedStatus returns whether the given child is up according to all the sources of status.
+	combinedStatus(childName string) bool
+}
+
 type metricsHealthCheck interface {
 	ServiceServerUpGauge() gokitmetrics.Gauge
 }
@@ -114,7 +121,6 @@ func (shc *ServiceHealthChecker) Launch(ctx context.Context) {
 				}
 
 				up := true
-				serverUpMetricValue := float64(1)
 
 				if err := shc.executeHealthCheck(ctx, shc.config, target); err != nil {
 					// The context is canceled when the dynamic configuration is refreshed.
@@ -128,14 +134,20 @@ func (shc *ServiceHealthChecker) Launch(ctx context.Context) {
 						Msg("Health check failed.")
 
 					up = false
-					serverUpMetricValue = float64(0)
 				}
 
 				shc.balancer.SetStatus(ctx, proxyName, up)
 
+				// The reported status is the one applied to the load-balancer.
+				if combiner, ok := shc.balancer.(statusCombiner); ok {
+					up = combiner.combinedStatus(proxyName)
+				}
+
 				statusStr := runtime.StatusDown
+				serverUpMetricValue := float64(0)
 				if up {
 					statusStr = runtime.StatusUp
+					serverUpMetricValue = 1
 				}
 
 				shc.info.UpdateServerStatus(target.String(), statusStr)
@@ -0,0 +1,184 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/tcp"
+)
+
+// TCPHealthCheckTarget is a TCP server checked by a ServiceTCPHealthChecker.
+type TCPHealthCheckTarget struct {
+	Address string
+	Dialer  tcp.Dialer
+}
+
+// ServiceTCPHealthChecker periodically checks the TCP servers of a service.
+type ServiceTCPHealthChecker struct {
+	balancer    StatusSetter
+	info        *runtime.TCPServiceInfo
+	serviceName string
+
+	config   *dynamic.TCPServerHealthCheck
+	interval time.Duration
+	timeout  time.Duration
+
+	metrics metricsHealthCheck
+
+	targets map[string]*TCPHealthCheckTarget
+}
+
+// NewServiceTCPHealthChecker creates a new ServiceTCPHealthChecker.
+func NewServiceTCPHealthChecker(ctx context.Context, metrics metricsHealthCheck, config *dynamic.TCPServerHealthCheck, service StatusSetter, info *runtime.TCPServiceInfo, serviceName string, targets map[string]*TCPHealthCheckTarget) *ServiceTCPHealthChecker {
+	logger := log.Ctx(ctx)
+
+	interval := time.Duration(config.Interval)
+	if interval <= 0 {
+		logger.Error().Msg("Health check interval smaller than zero")
+		interval = time.Duration(dynamic.DefaultHealthCheckInterval)
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		logger.Error().Msg("Health check timeout smaller than zero")
+		timeout = time.Duration(dynamic.DefaultHealthCheckTimeout)
+	}
+
+	if timeout >= interval {
+		logger.Warn().Msgf("Health check timeout should be lower than the health check interval. Interval set to timeout + 1 second (%s).", interval)
+		interval = timeout + time.Second
+	}
+
+	return &ServiceTCPHealthChecker{
+		balancer:    service,
+		info:        info,
+		serviceName: serviceName,
+		config:      config,
+		interval:    interval,
+		timeout:     timeout,
+		metrics:     metrics,
+		targets:     targets,
+	}
+}
+
+// Launch periodically checks the servers, until the context is canceled.
+func (thc *ServiceTCPHealthChecker) Launch(ctx context.Context) {
+	ticker := time.NewTicker(thc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			for serverName, target := range thc.targets {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+
+				up := true
+				serverUpMetricValue := float64(1)
+
+				if err := thc.executeHealthCheck(ctx, target); err != nil {
+					// The context is canceled when the dynamic configuration is refreshed.
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+
+					log.Ctx(ctx).Warn().
+						Str("targetAddress", target.Address).
+						Err(err).
+						Msg("Health check failed.")
+
+					up = false
+					serverUpMetricValue = float64(0)
+				}
+
+				thc.balancer.SetStatus(ctx, serverName, up)
+
+				statusStr := runtime.StatusDown
+				if up {
+					statusStr = runtime.StatusUp
+				}
+
+				thc.info.UpdateServerStatus(target.Address, statusStr)
+
+				thc.metrics.ServiceServerUpGauge().
+					With("service", thc.serviceName, "url", target.Address).
+					Set(serverUpMetricValue)
+			}
+		}
+	}
+}
+
+// executeHealthCheck returns an error with a meaningful description if the health check failed.
+func (thc *ServiceTCPHealthChecker) executeHealthCheck(ctx context.Context, target *TCPHealthCheckTarget) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(thc.timeout))
+	defer cancel()
+
+	err := thc.checkHealthTCP(ctx, target)
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return context.Canceled
+	}
+
+	return err
+}
+
+// checkHealthTCP returns an error with a meaningful description if the health check failed.
+func (thc *ServiceTCPHealthChecker) checkHealthTCP(ctx context.Context, target *TCPHealthCheckTarget) error {
+	address := target.Address
+	if thc.config.Port != 0 {
+		host, _, err := net.SplitHostPort(target.Address)
+		if err != nil {
+			return fmt.Errorf("parsing server address: %w", err)
+		}
+
+		address = net.JoinHostPort(host, strconv.Itoa(thc.config.Port))
+	}
+
+	conn, err := target.Dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", address, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("setting connection deadline: %w", err)
+		}
+	}
+
+	if thc.config.Send != "" {
+		if _, err := conn.Write([]byte(thc.config.Send)); err != nil {
+			return fmt.Errorf("sending payload: %w", err)
+		}
+	}
+
+	if thc.config.Expect == "" {
+		return nil
+	}
+
+	expect := []byte(thc.config.Expect)
+	received := make([]byte, len(expect))
+	if _, err := io.ReadFull(conn, received); err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if !bytes.Equal(received, expect) {
+		return fmt.Errorf("received unexpected response: %q", received)
+	}
+
+	return nil
+}
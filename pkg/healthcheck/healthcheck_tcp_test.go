@@ -0,0 +1,252 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/tcp"
+	"traefik/v3/pkg/testhelpers"
+)
+
+func TestServiceTCPHealthChecker_executeHealthCheck(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    *dynamic.TCPServerHealthCheck
+		closed    bool
+		usePort   bool
+		expectErr bool
+	}{
+		{
+			desc:   "connect only",
+			config: &dynamic.TCPServerHealthCheck{},
+		},
+		{
+			desc:      "connect only to a closed server",
+			config:    &dynamic.TCPServerHealthCheck{},
+			closed:    true,
+			expectErr: true,
+		},
+		{
+			desc:   "send and expect",
+			config: &dynamic.TCPServerHealthCheck{Send: "PING\n", Expect: "PONG"},
+		},
+		{
+			desc:      "send and expect unexpected response",
+			config:    &dynamic.TCPServerHealthCheck{Send: "PING\n", Expect: "PANG"},
+			expectErr: true,
+		},
+		{
+			desc:      "expect longer than the response",
+			config:    &dynamic.TCPServerHealthCheck{Send: "PING\n", Expect: "PONGPONG"},
+			expectErr: true,
+		},
+		{
+			desc:    "custom port",
+			config:  &dynamic.TCPServerHealthCheck{Send: "PING\n", Expect: "PONG"},
+			usePort: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			address := startPingServer(t, func() bool { return true })
+
+			config := test.config
+			config.Timeout = ptypes.Duration(time.Second)
+
+			target := &TCPHealthCheckTarget{Address: address, Dialer: newTestDialer(t)}
+
+			if test.usePort {
+				// The server address targets a closed port, but the health check port is the one of the server.
+				_, port, err := net.SplitHostPort(address)
+				require.NoError(t, err)
+
+				config.Port, err = strconv.Atoi(port)
+				require.NoError(t, err)
+
+				target.Address = "127.0.0.1:1"
+			}
+
+			if test.closed {
+				target.Address = "127.0.0.1:1"
+			}
+
+			hc := NewServiceTCPHealthChecker(context.Background(), nil, config, nil, nil, "test", nil)
+
+			err := hc.executeHealthCheck(context.Background(), target)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestServiceTCPHealthChecker_Launch(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		sequence              []bool
+		expNumRemovedServers  int
+		expNumUpsertedServers int
+		expGaugeValue         float64
+		targetStatus          string
+	}{
+		{
+			desc:                  "healthy server staying healthy",
+			sequence:              []bool{true},
+			expNumUpsertedServers: 1,
+			expGaugeValue:         1,
+			targetStatus:          runtime.StatusUp,
+		},
+		{
+			desc:                 "healthy server becoming sick",
+			sequence:             []bool{false},
+			expNumRemovedServers: 1,
+			expGaugeValue:        0,
+			targetStatus:         runtime.StatusDown,
+		},
+		{
+			desc:                  "healthy server toggling to sick and back to healthy",
+			sequence:              []bool{false, true},
+			expNumRemovedServers:  1,
+			expNumUpsertedServers: 1,
+			expGaugeValue:         1,
+			targetStatus:          runtime.StatusUp,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The context is passed to the health check and
+			// canonically canceled by the test server once all expected connections have been received.
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			sequence := &HealthSequence[int]{}
+			for _, healthy := range test.sequence {
+				value := 0
+				if healthy {
+					value = 1
+				}
+				sequence.sequence = append(sequence.sequence, value)
+			}
+
+			address := startPingServer(t, func() bool {
+				if sequence.IsEmpty() {
+					cancel()
+					// This ensures that the health-checker will handle the context cancellation error before receiving the response.
+					time.Sleep(500 * time.Millisecond)
+					return false
+				}
+				return sequence.Pop() == 1
+			})
+
+			lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+
+			config := &dynamic.TCPServerHealthCheck{
+				Send:     "PING\n",
+				Expect:   "PONG",
+				Interval: ptypes.Duration(500 * time.Millisecond),
+				Timeout:  ptypes.Duration(499 * time.Millisecond),
+			}
+
+			gauge := &testhelpers.CollectingGauge{}
+			serviceInfo := &runtime.TCPServiceInfo{}
+			targets := map[string]*TCPHealthCheckTarget{
+				"test": {Address: address, Dialer: newTestDialer(t)},
+			}
+			hc := NewServiceTCPHealthChecker(ctx, &MetricsMock{gauge}, config, lb, serviceInfo, "test", targets)
+
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+
+			go func() {
+				hc.Launch(ctx)
+				wg.Done()
+			}()
+
+			timeout := time.Duration(len(test.sequence)*int(time.Second) + int(time.Second))
+			select {
+			case <-time.After(timeout):
+				t.Fatal("test did not complete in time")
+			case <-ctx.Done():
+				wg.Wait()
+			}
+
+			lb.Lock()
+			defer lb.Unlock()
+
+			assert.Equal(t, test.expNumRemovedServers, lb.numRemovedServers, "removed servers")
+			assert.Equal(t, test.expNumUpsertedServers, lb.numUpsertedServers, "upserted servers")
+			assert.Equal(t, test.expGaugeValue, gauge.GaugeValue, "ServerUp Gauge")
+			assert.Equal(t, map[string]string{address: test.targetStatus}, serviceInfo.GetAllStatus())
+		})
+	}
+}
+
+// startPingServer starts a TCP server answering PONG to PING when healthy returns true, and PANG otherwise.
+func startPingServer(t *testing.T, healthy func() bool) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				_ = conn.SetDeadline(time.Now().Add(time.Second))
+
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil || line != "PING\n" {
+					return
+				}
+
+				if healthy() {
+					_, _ = conn.Write([]byte("PONG"))
+					return
+				}
+				_, _ = conn.Write([]byte("PANG"))
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func newTestDialer(t *testing.T) tcp.Dialer {
+	t.Helper()
+
+	dialerManager := tcp.NewDialerManager(nil)
+	dialerManager.Update(map[string]*dynamic.TCPServersTransport{"default@internal": {}})
+
+	dialer, err := dialerManager.Get("default@internal", false)
+	require.NoError(t, err)
+
+	return dialer
+}
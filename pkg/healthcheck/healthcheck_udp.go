@@ -0,0 +1,187 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+)
+
+// maxDatagramSize is the maximum size of a UDP datagram.
+const maxDatagramSize = 65535
+
+// ServiceUDPHealthChecker periodically checks the UDP servers of a service.
+type ServiceUDPHealthChecker struct {
+	balancer    StatusSetter
+	info        *runtime.UDPServiceInfo
+	serviceName string
+
+	config   *dynamic.UDPServerHealthCheck
+	expect   *regexp.Regexp
+	interval time.Duration
+	timeout  time.Duration
+
+	metrics metricsHealthCheck
+
+	// targets are the addresses of the servers to check, keyed by server name.
+	targets map[string]string
+}
+
+// NewServiceUDPHealthChecker creates a new ServiceUDPHealthChecker.
+// It returns an error if the expected response is not a valid regular expression.
+func NewServiceUDPHealthChecker(ctx context.Context, metrics metricsHealthCheck, config *dynamic.UDPServerHealthCheck, service StatusSetter, info *runtime.UDPServiceInfo, serviceName string, targets map[string]string) (*ServiceUDPHealthChecker, error) {
+	logger := log.Ctx(ctx)
+
+	var expect *regexp.Regexp
+	if config.Expect != "" {
+		var err error
+		expect, err = regexp.Compile(config.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("compiling health check expected response: %w", err)
+		}
+	}
+
+	interval := time.Duration(config.Interval)
+	if interval <= 0 {
+		logger.Error().Msg("Health check interval smaller than zero")
+		interval = time.Duration(dynamic.DefaultHealthCheckInterval)
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		logger.Error().Msg("Health check timeout smaller than zero")
+		timeout = time.Duration(dynamic.DefaultHealthCheckTimeout)
+	}
+
+	if timeout >= interval {
+		logger.Warn().Msgf("Health check timeout should be lower than the health check interval. Interval set to timeout + 1 second (%s).", interval)
+		interval = timeout + time.Second
+	}
+
+	return &ServiceUDPHealthChecker{
+		balancer:    service,
+		info:        info,
+		serviceName: serviceName,
+		config:      config,
+		expect:      expect,
+		interval:    interval,
+		timeout:     timeout,
+		metrics:     metrics,
+		targets:     targets,
+	}, nil
+}
+
+// Launch periodically checks the servers, until the context is canceled.
+func (uhc *ServiceUDPHealthChecker) Launch(ctx context.Context) {
+	ticker := time.NewTicker(uhc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			for serverName, address := range uhc.targets {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+
+				up := true
+				serverUpMetricValue := float64(1)
+
+				if err := uhc.executeHealthCheck(ctx, address); err != nil {
+					// The context is canceled when the dynamic configuration is refreshed.
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+
+					log.Ctx(ctx).Warn().
+						Str("targetAddress", address).
+						Err(err).
+						Msg("Health check failed.")
+
+					up = false
+					serverUpMetricValue = float64(0)
+				}
+
+				uhc.balancer.SetStatus(ctx, serverName, up)
+
+				statusStr := runtime.StatusDown
+				if up {
+					statusStr = runtime.StatusUp
+				}
+
+				uhc.info.UpdateServerStatus(address, statusStr)
+
+				uhc.metrics.ServiceServerUpGauge().
+					With("service", uhc.serviceName, "url", address).
+					Set(serverUpMetricValue)
+			}
+		}
+	}
+}
+
+// executeHealthCheck returns an error with a meaningful description if the health check failed.
+func (uhc *ServiceUDPHealthChecker) executeHealthCheck(ctx context.Context, address string) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(uhc.timeout))
+	defer cancel()
+
+	err := uhc.checkHealthUDP(ctx, address)
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return context.Canceled
+	}
+
+	return err
+}
+
+// checkHealthUDP returns an error with a meaningful description if the health check failed.
+func (uhc *ServiceUDPHealthChecker) checkHealthUDP(ctx context.Context, address string) error {
+	if uhc.config.Port != 0 {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("parsing server address: %w", err)
+		}
+
+		address = net.JoinHostPort(host, strconv.Itoa(uhc.config.Port))
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", address, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return fmt.Errorf("setting connection deadline: %w", err)
+		}
+	}
+
+	if _, err := conn.Write([]byte(uhc.config.Send)); err != nil {
+		return fmt.Errorf("sending payload: %w", err)
+	}
+
+	// As UDP is connectionless, receiving a response is the only evidence that the server is alive.
+	received := make([]byte, maxDatagramSize)
+	n, err := conn.Read(received)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if uhc.expect != nil && !uhc.expect.Match(received[:n]) {
+		return fmt.Errorf("received unexpected response: %q", received[:n])
+	}
+
+	return nil
+}
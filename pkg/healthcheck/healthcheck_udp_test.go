@@ -0,0 +1,228 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/testhelpers"
+)
+
+func TestServiceUDPHealthChecker_executeHealthCheck(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    *dynamic.UDPServerHealthCheck
+		silent    bool
+		usePort   bool
+		expectErr bool
+	}{
+		{
+			desc:   "any response",
+			config: &dynamic.UDPServerHealthCheck{Send: "PING"},
+		},
+		{
+			desc:      "no response",
+			config:    &dynamic.UDPServerHealthCheck{Send: "PING"},
+			silent:    true,
+			expectErr: true,
+		},
+		{
+			desc:   "response matching the expected pattern",
+			config: &dynamic.UDPServerHealthCheck{Send: "PING", Expect: "^P[O]NG$"},
+		},
+		{
+			desc:      "response not matching the expected pattern",
+			config:    &dynamic.UDPServerHealthCheck{Send: "PING", Expect: "^PANG$"},
+			expectErr: true,
+		},
+		{
+			desc:    "custom port",
+			config:  &dynamic.UDPServerHealthCheck{Send: "PING", Expect: "PONG"},
+			usePort: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			address := startUDPPingServer(t, func() bool { return !test.silent }, func() bool { return true })
+
+			config := test.config
+			config.Timeout = ptypes.Duration(200 * time.Millisecond)
+
+			if test.usePort {
+				// The server address targets another port, but the health check port is the one of the server.
+				_, port, err := net.SplitHostPort(address)
+				require.NoError(t, err)
+
+				config.Port, err = strconv.Atoi(port)
+				require.NoError(t, err)
+
+				address = "127.0.0.1:1"
+			}
+
+			hc, err := NewServiceUDPHealthChecker(context.Background(), nil, config, nil, nil, "test", nil)
+			require.NoError(t, err)
+
+			err = hc.executeHealthCheck(context.Background(), address)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewServiceUDPHealthChecker_invalidExpect(t *testing.T) {
+	_, err := NewServiceUDPHealthChecker(context.Background(), nil, &dynamic.UDPServerHealthCheck{Expect: "("}, nil, nil, "test", nil)
+	assert.Error(t, err)
+}
+
+func TestServiceUDPHealthChecker_Launch(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		sequence              []bool
+		expNumRemovedServers  int
+		expNumUpsertedServers int
+		expGaugeValue         float64
+		targetStatus          string
+	}{
+		{
+			desc:                  "healthy server staying healthy",
+			sequence:              []bool{true},
+			expNumUpsertedServers: 1,
+			expGaugeValue:         1,
+			targetStatus:          runtime.StatusUp,
+		},
+		{
+			desc:                 "healthy server becoming sick",
+			sequence:             []bool{false},
+			expNumRemovedServers: 1,
+			expGaugeValue:        0,
+			targetStatus:         runtime.StatusDown,
+		},
+		{
+			desc:                  "healthy server toggling to sick and back to healthy",
+			sequence:              []bool{false, true},
+			expNumRemovedServers:  1,
+			expNumUpsertedServers: 1,
+			expGaugeValue:         1,
+			targetStatus:          runtime.StatusUp,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The context is passed to the health check and
+			// canonically canceled by the test server once all expected datagrams have been received.
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			sequence := &HealthSequence[int]{}
+			for _, healthy := range test.sequence {
+				value := 0
+				if healthy {
+					value = 1
+				}
+				sequence.sequence = append(sequence.sequence, value)
+			}
+
+			answer := func() bool {
+				if sequence.IsEmpty() {
+					cancel()
+					return false
+				}
+				return true
+			}
+
+			address := startUDPPingServer(t, answer, func() bool {
+				return sequence.Pop() == 1
+			})
+
+			lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+
+			config := &dynamic.UDPServerHealthCheck{
+				Send:     "PING",
+				Expect:   "PONG",
+				Interval: ptypes.Duration(500 * time.Millisecond),
+				Timeout:  ptypes.Duration(499 * time.Millisecond),
+			}
+
+			gauge := &testhelpers.CollectingGauge{}
+			serviceInfo := &runtime.UDPServiceInfo{}
+			hc, err := NewServiceUDPHealthChecker(ctx, &MetricsMock{gauge}, config, lb, serviceInfo, "test", map[string]string{"test": address})
+			require.NoError(t, err)
+
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+
+			go func() {
+				hc.Launch(ctx)
+				wg.Done()
+			}()
+
+			timeout := time.Duration(len(test.sequence)*int(time.Second) + int(time.Second))
+			select {
+			case <-time.After(timeout):
+				t.Fatal("test did not complete in time")
+			case <-ctx.Done():
+				wg.Wait()
+			}
+
+			lb.Lock()
+			defer lb.Unlock()
+
+			assert.Equal(t, test.expNumRemovedServers, lb.numRemovedServers, "removed servers")
+			assert.Equal(t, test.expNumUpsertedServers, lb.numUpsertedServers, "upserted servers")
+			assert.Equal(t, test.expGaugeValue, gauge.GaugeValue, "ServerUp Gauge")
+			assert.Equal(t, map[string]string{address: test.targetStatus}, serviceInfo.GetAllStatus())
+		})
+	}
+}
+
+// startUDPPingServer starts a UDP server answering to PING datagrams, when answer returns true.
+// It answers PONG when healthy returns true, and PANG otherwise.
+func startUDPPingServer(t *testing.T, answer, healthy func() bool) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+
+			if string(buffer[:n]) != "PING" || !answer() {
+				continue
+			}
+
+			if healthy() {
+				_, _ = conn.WriteTo([]byte("PONG"), addr)
+				continue
+			}
+			_, _ = conn.WriteTo([]byte("PANG"), addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
@@ -0,0 +1,263 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+)
+
+// PassiveHealthChecker ejects the servers of a load-balancer which fail to serve consecutive requests.
+// A request is considered as failed when the server answers with a 5XX status code,
+// which includes the Bad Gateway and Gateway Timeout responses written on transport errors.
+// When the service also has an active health check, the PassiveHealthChecker is its StatusSetter,
+// so that a server is only up when both health checks agree.
+type PassiveHealthChecker struct {
+	ctx context.Context
+
+	balancer    StatusSetter
+	info        *runtime.ServiceInfo
+	serviceName string
+
+	failureThreshold int
+	baseEjectionTime time.Duration
+	maxEjectionTime  time.Duration
+
+	metrics metricsHealthCheck
+
+	serversMu sync.Mutex
+	servers   map[string]*passiveServer
+}
+
+// passiveServer holds the passive health check state of a server.
+type passiveServer struct {
+	target string
+	// activeUp is the status of the server set by the active health check, if any.
+	activeUp bool
+
+	consecutiveFailures int
+	ejected             bool
+	// ejections is the multiplier applied to the base ejection time,
+	// it grows with each ejection and decays while the server stays healthy.
+	ejections  int
+	restoredAt time.Time
+}
+
+// NewPassiveHealthChecker creates a new PassiveHealthChecker.
+func NewPassiveHealthChecker(ctx context.Context, metrics metricsHealthCheck, config *dynamic.PassiveServerHealthCheck, service StatusSetter, info *runtime.ServiceInfo, serviceName string) *PassiveHealthChecker {
+	logger := log.Ctx(ctx)
+
+	failureThreshold := config.FailureThreshold
+	if failureThreshold <= 0 {
+		logger.Error().Msg("Passive health check failure threshold smaller than or equal to zero")
+		failureThreshold = dynamic.DefaultPassiveHealthCheckFailureThreshold
+	}
+
+	baseEjectionTime := time.Duration(config.BaseEjectionTime)
+	if baseEjectionTime <= 0 {
+		logger.Error().Msg("Passive health check base ejection time smaller than or equal to zero")
+		baseEjectionTime = time.Duration(dynamic.DefaultPassiveHealthCheckBaseEjectionTime)
+	}
+
+	maxEjectionTime := time.Duration(config.MaxEjectionTime)
+	if maxEjectionTime < baseEjectionTime {
+		logger.Warn().Msgf("Passive health check max ejection time should be greater than the base ejection time. Max ejection time set to the base ejection time (%s).", baseEjectionTime)
+		maxEjectionTime = baseEjectionTime
+	}
+
+	return &PassiveHealthChecker{
+		ctx:              ctx,
+		balancer:         service,
+		info:             info,
+		serviceName:      serviceName,
+		failureThreshold: failureThreshold,
+		baseEjectionTime: baseEjectionTime,
+		maxEjectionTime:  maxEjectionTime,
+		metrics:          metrics,
+		servers:          make(map[string]*passiveServer),
+	}
+}
+
+// WrapServer returns a handler recording the outcome of the requests served by the given server handler.
+func (p *PassiveHealthChecker) WrapServer(name, target string, next http.Handler) http.Handler {
+	p.serversMu.Lock()
+	p.servers[name] = &passiveServer{target: target, activeUp: true}
+	p.serversMu.Unlock()
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		p.recordResult(name, recorder.status < http.StatusInternalServerError)
+	})
+}
+
+// recordResult updates the state of the given server with the outcome of a request,
+// and ejects the server when it reaches the failure threshold.
+func (p *PassiveHealthChecker) recordResult(name string, success bool) {
+	p.serversMu.Lock()
+	defer p.serversMu.Unlock()
+
+	server, ok := p.servers[name]
+	if !ok || server.ejected {
+		return
+	}
+
+	if success {
+		server.consecutiveFailures = 0
+		return
+	}
+
+	server.consecutiveFailures++
+	if server.consecutiveFailures < p.failureThreshold {
+		return
+	}
+
+	p.eject(name, server)
+}
+
+// eject removes the server from the load-balancer for a duration growing with its number of recent ejections.
+// It must be called with the serversMu lock held.
+func (p *PassiveHealthChecker) eject(name string, server *passiveServer) {
+	if server.ejections > 0 {
+		// The multiplier decays by one for each base ejection time elapsed since the server was restored.
+		decay := int(time.Since(server.restoredAt) / p.baseEjectionTime)
+		server.ejections -= decay
+		if server.ejections < 0 {
+			server.ejections = 0
+		}
+	}
+
+	server.ejections++
+	server.ejected = true
+	server.consecutiveFailures = 0
+
+	ejectionTime := p.baseEjectionTime * time.Duration(server.ejections)
+	if ejectionTime > p.maxEjectionTime || ejectionTime <= 0 {
+		ejectionTime = p.maxEjectionTime
+	}
+
+	log.Ctx(p.ctx).Warn().
+		Str("targetURL", server.target).
+		Msgf("Passive health check failed, ejecting server for %s.", ejectionTime)
+
+	p.setStatus(name, server.target, false)
+
+	time.AfterFunc(ejectionTime, func() {
+		p.restore(name)
+	})
+}
+
+// restore puts back the ejected server into the load-balancer.
+func (p *PassiveHealthChecker) restore(name string) {
+	p.serversMu.Lock()
+	defer p.serversMu.Unlock()
+
+	server, ok := p.servers[name]
+	if !ok || !server.ejected {
+		return
+	}
+
+	server.ejected = false
+	server.restoredAt = time.Now()
+
+	if !server.activeUp {
+		log.Ctx(p.ctx).Debug().
+			Str("targetURL", server.target).
+			Msg("Ejection time elapsed, server still down according to the active health check.")
+		return
+	}
+
+	log.Ctx(p.ctx).Debug().
+		Str("targetURL", server.target).
+		Msg("Ejection time elapsed, restoring server.")
+
+	// The load-balancer brings the server back progressively, if it has a slow start window.
+	p.setStatus(name, server.target, true)
+}
+
+// SetStatus sets the status of the given server according to the active health check.
+// The server is only set up on the load-balancer if it is not ejected by the passive health check.
+func (p *PassiveHealthChecker) SetStatus(ctx context.Context, childName string, up bool) {
+	p.serversMu.Lock()
+	defer p.serversMu.Unlock()
+
+	server, ok := p.servers[childName]
+	if !ok {
+		p.balancer.SetStatus(ctx, childName, up)
+		return
+	}
+
+	server.activeUp = up
+	p.balancer.SetStatus(ctx, childName, up && !server.ejected)
+}
+
+// combinedStatus returns whether the given server is up according to both the active and the passive health checks.
+func (p *PassiveHealthChecker) combinedStatus(childName string) bool {
+	p.serversMu.Lock()
+	defer p.serversMu.Unlock()
+
+	server, ok := p.servers[childName]
+	if !ok {
+		return true
+	}
+
+	return server.activeUp && !server.ejected
+}
+
+func (p *PassiveHealthChecker) setStatus(name, target string, up bool) {
+	p.balancer.SetStatus(p.ctx, name, up)
+
+	statusStr := runtime.StatusDown
+	serverUpMetricValue := float64(0)
+	if up {
+		statusStr = runtime.StatusUp
+		serverUpMetricValue = 1
+	}
+
+	if p.info != nil {
+		p.info.UpdateServerStatus(target, statusStr)
+	}
+
+	if p.metrics != nil {
+		p.metrics.ServiceServerUpGauge().
+			With("service", p.serviceName, "url", target).
+			Set(serverUpMetricValue)
+	}
+}
+
+// statusRecorder records the status code written by the server handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hijacks the connection.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", s.ResponseWriter)
+	}
+
+	return hj.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,221 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/testhelpers"
+)
+
+func TestPassiveHealthChecker_WrapServer(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		statusCodes          []int
+		expNumRemovedServers int
+		expGaugeValue        float64
+		targetStatus         string
+	}{
+		{
+			desc:          "successful responses",
+			statusCodes:   []int{http.StatusOK, http.StatusNotFound, http.StatusOK},
+			expGaugeValue: 0,
+			targetStatus:  runtime.StatusUp,
+		},
+		{
+			desc:          "failures below the threshold",
+			statusCodes:   []int{http.StatusInternalServerError, http.StatusBadGateway},
+			expGaugeValue: 0,
+			targetStatus:  runtime.StatusUp,
+		},
+		{
+			desc:          "non-consecutive failures",
+			statusCodes:   []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK, http.StatusGatewayTimeout},
+			expGaugeValue: 0,
+			targetStatus:  runtime.StatusUp,
+		},
+		{
+			desc:                 "consecutive failures reaching the threshold",
+			statusCodes:          []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout},
+			expNumRemovedServers: 1,
+			expGaugeValue:        0,
+			targetStatus:         runtime.StatusDown,
+		},
+		{
+			desc:                 "failures while ejected",
+			statusCodes:          []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			expNumRemovedServers: 1,
+			expGaugeValue:        0,
+			targetStatus:         runtime.StatusDown,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+			gauge := &testhelpers.CollectingGauge{}
+			serviceInfo := &runtime.ServiceInfo{}
+			serviceInfo.UpdateServerStatus("http://127.0.0.1:80", runtime.StatusUp)
+
+			config := &dynamic.PassiveServerHealthCheck{
+				FailureThreshold: 3,
+				BaseEjectionTime: ptypes.Duration(time.Hour),
+				MaxEjectionTime:  ptypes.Duration(time.Hour),
+			}
+
+			phc := NewPassiveHealthChecker(context.Background(), &MetricsMock{gauge}, config, lb, serviceInfo, "test")
+
+			var statusCode int
+			handler := phc.WrapServer("test", "http://127.0.0.1:80", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(statusCode)
+			}))
+
+			for _, code := range test.statusCodes {
+				statusCode = code
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+				assert.Equal(t, code, recorder.Code)
+			}
+
+			lb.Lock()
+			defer lb.Unlock()
+
+			assert.Equal(t, test.expNumRemovedServers, lb.numRemovedServers, "removed servers")
+			assert.Equal(t, 0, lb.numUpsertedServers, "upserted servers")
+			assert.Equal(t, test.expGaugeValue, gauge.GaugeValue, "ServerUp Gauge")
+			assert.Equal(t, map[string]string{"http://127.0.0.1:80": test.targetStatus}, serviceInfo.GetAllStatus())
+		})
+	}
+}
+
+func TestPassiveHealthChecker_restore(t *testing.T) {
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	gauge := &testhelpers.CollectingGauge{}
+	serviceInfo := &runtime.ServiceInfo{}
+
+	config := &dynamic.PassiveServerHealthCheck{
+		FailureThreshold: 1,
+		BaseEjectionTime: ptypes.Duration(50 * time.Millisecond),
+		MaxEjectionTime:  ptypes.Duration(time.Second),
+	}
+
+	phc := NewPassiveHealthChecker(context.Background(), &MetricsMock{gauge}, config, lb, serviceInfo, "test")
+
+	handler := phc.WrapServer("test", "http://127.0.0.1:80", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, map[string]string{"http://127.0.0.1:80": runtime.StatusDown}, serviceInfo.GetAllStatus())
+
+	// The server is restored while holding the lock of the passive health checker.
+	assert.Eventually(t, func() bool {
+		return phc.combinedStatus("test")
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, lb.numUpsertedServers, "upserted servers")
+	assert.Equal(t, float64(1), gauge.GaugeValue, "ServerUp Gauge")
+	assert.Equal(t, map[string]string{"http://127.0.0.1:80": runtime.StatusUp}, serviceInfo.GetAllStatus())
+}
+
+func TestPassiveHealthChecker_ejectionTime(t *testing.T) {
+	config := &dynamic.PassiveServerHealthCheck{
+		FailureThreshold: 1,
+		BaseEjectionTime: ptypes.Duration(time.Minute),
+		MaxEjectionTime:  ptypes.Duration(3 * time.Minute),
+	}
+
+	phc := NewPassiveHealthChecker(context.Background(), nil, config, &testLoadBalancer{RWMutex: &sync.RWMutex{}}, nil, "test")
+	phc.WrapServer("test", "http://127.0.0.1:80", http.NotFoundHandler())
+
+	server := phc.servers["test"]
+
+	// Consecutive ejections make the ejection time grow, up to the max ejection time.
+	for _, expected := range []int{1, 2, 3, 4} {
+		server.ejected = false
+		server.restoredAt = time.Now()
+
+		phc.recordResult("test", false)
+		assert.Equal(t, expected, server.ejections)
+	}
+
+	// Staying healthy makes the ejection multiplier decay.
+	server.ejected = false
+	server.restoredAt = time.Now().Add(-2*time.Minute - time.Second)
+
+	phc.recordResult("test", false)
+	assert.Equal(t, 3, server.ejections)
+}
+
+func TestPassiveHealthChecker_SetStatus(t *testing.T) {
+	lb := &statusLoadBalancer{status: make(map[string]bool)}
+
+	config := &dynamic.PassiveServerHealthCheck{
+		FailureThreshold: 1,
+		BaseEjectionTime: ptypes.Duration(time.Hour),
+		MaxEjectionTime:  ptypes.Duration(time.Hour),
+	}
+
+	phc := NewPassiveHealthChecker(context.Background(), nil, config, lb, nil, "test")
+	phc.WrapServer("test", "http://127.0.0.1:80", http.NotFoundHandler())
+
+	// The active health check cannot bring back an ejected server.
+	phc.recordResult("test", false)
+	phc.SetStatus(context.Background(), "test", true)
+
+	assert.False(t, lb.get("test"))
+	assert.False(t, phc.combinedStatus("test"))
+
+	// The end of the ejection does not bring back a server down according to the active health check.
+	phc.SetStatus(context.Background(), "test", false)
+	phc.restore("test")
+
+	assert.False(t, lb.get("test"))
+	assert.False(t, phc.combinedStatus("test"))
+
+	// The server is up once both health checks agree.
+	phc.SetStatus(context.Background(), "test", true)
+
+	assert.True(t, lb.get("test"))
+	assert.True(t, phc.combinedStatus("test"))
+}
+
+func TestStatusRecorder_Hijack(t *testing.T) {
+	recorder := &statusRecorder{ResponseWriter: httptest.NewRecorder()}
+
+	_, _, err := recorder.Hijack()
+	assert.Error(t, err)
+}
+
+// statusLoadBalancer records the last status set for each child.
+type statusLoadBalancer struct {
+	mu     sync.Mutex
+	status map[string]bool
+}
+
+func (lb *statusLoadBalancer) SetStatus(_ context.Context, childName string, up bool) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	lb.status[childName] = up
+}
+
+func (lb *statusLoadBalancer) get(childName string) bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	return lb.status[childName]
+}
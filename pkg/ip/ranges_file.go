@@ -0,0 +1,137 @@
+package ip
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/fsnotify.v1"
+	"traefik/v3/pkg/safe"
+)
+
+var (
+	rangesFilesMu sync.Mutex
+	rangesFiles   = make(map[string]*RangesFile)
+)
+
+// RangesFile holds the IPs (or ranges of IPs by using CIDR notation) listed in a file, one per line.
+// The file is watched, and the ranges are reloaded when it changes.
+type RangesFile struct {
+	path    string
+	checker atomic.Pointer[Checker]
+}
+
+// WatchRangesFile returns the ranges of the given file, watched for changes.
+// A file is watched once for the lifetime of the process,
+// as the middlewares using it are built again on every configuration change.
+func WatchRangesFile(path string) (*RangesFile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rangesFilesMu.Lock()
+	defer rangesFilesMu.Unlock()
+
+	if rangesFile, ok := rangesFiles[path]; ok {
+		return rangesFile, nil
+	}
+
+	rangesFile := &RangesFile{path: path}
+	if err := rangesFile.load(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+
+	// The directory is watched, to be notified when the file is replaced,
+	// as done by most editors and by Kubernetes for the mounted ConfigMaps.
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("adding file watcher: %w", err)
+	}
+
+	safe.Go(func() { rangesFile.watch(watcher) })
+
+	rangesFiles[path] = rangesFile
+
+	return rangesFile, nil
+}
+
+// Contains checks if provided address is in the ranges of the file.
+func (f *RangesFile) Contains(addr string) (bool, error) {
+	checker := f.checker.Load()
+	if checker == nil {
+		// The file holds no ranges.
+		return false, nil
+	}
+
+	return checker.Contains(addr)
+}
+
+func (f *RangesFile) watch(watcher *fsnotify.Watcher) {
+	logger := log.With().Str("file", f.path).Logger()
+
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if err := f.load(); err != nil {
+				logger.Error().Err(err).Msg("Error reloading source ranges, keeping the previous ones")
+				continue
+			}
+
+			logger.Debug().Msg("Source ranges reloaded")
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+			logger.Error().Err(err).Msg("Source ranges file watcher error")
+		}
+	}
+}
+
+// load reads the ranges of the file.
+// The empty lines, and the lines starting with #, are ignored.
+func (f *RangesFile) load() error {
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("reading source ranges file: %w", err)
+	}
+
+	var ranges []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ranges = append(ranges, line)
+	}
+
+	if len(ranges) == 0 {
+		f.checker.Store(nil)
+		return nil
+	}
+
+	checker, err := NewChecker(ranges)
+	if err != nil {
+		return fmt.Errorf("parsing source ranges file %s: %w", f.path, err)
+	}
+
+	f.checker.Store(checker)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package ip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchRangesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges")
+	err := os.WriteFile(path, []byte("# Ranges\n\n10.0.0.0/24\n"), 0o600)
+	require.NoError(t, err)
+
+	rangesFile, err := WatchRangesFile(path)
+	require.NoError(t, err)
+
+	same, err := WatchRangesFile(path)
+	require.NoError(t, err)
+	assert.Same(t, rangesFile, same)
+
+	contains, err := rangesFile.Contains("10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, contains)
+
+	contains, err = rangesFile.Contains("10.0.1.1")
+	require.NoError(t, err)
+	assert.False(t, contains)
+
+	// The file is replaced, as done by most editors.
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, []byte("10.0.1.0/24\n"), 0o600)
+	require.NoError(t, err)
+	err = os.Rename(tmpPath, path)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		contains, err := rangesFile.Contains("10.0.1.1")
+		return err == nil && contains
+	}, 5*time.Second, 10*time.Millisecond)
+
+	contains, err = rangesFile.Contains("10.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, contains)
+
+	// The invalid ranges are ignored, the previous ones are kept.
+	err = os.WriteFile(path, []byte("foo\n"), 0o600)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	contains, err = rangesFile.Contains("10.0.1.1")
+	require.NoError(t, err)
+	assert.True(t, contains)
+
+	// An empty file holds no ranges.
+	err = os.WriteFile(path, nil, 0o600)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		contains, err := rangesFile.Contains("10.0.1.1")
+		return err == nil && !contains
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatchRangesFile_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges")
+	err := os.WriteFile(path, []byte("foo\n"), 0o600)
+	require.NoError(t, err)
+
+	_, err = WatchRangesFile(path)
+	assert.Error(t, err)
+
+	_, err = WatchRangesFile(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
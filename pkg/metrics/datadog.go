@@ -43,6 +43,8 @@ const (
 	ddServiceServerUpName     = "service.server.up"
 	ddServiceReqsBytesName    = "service.requests.bytes.total"
 	ddServiceRespsBytesName   = "service.responses.bytes.total"
+
+	ddTCPRateLimitRejectedConnsName = "tcp.ratelimit.rejected.connections.total"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -60,10 +62,11 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 	initDatadogClient(ctx, config)
 
 	registry := &standardRegistry{
-		configReloadsCounter:           datadogClient.NewCounter(ddConfigReloadsName, 1.0),
-		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
-		openConnectionsGauge:           datadogClient.NewGauge(ddOpenConnsName),
-		tlsCertsNotAfterTimestampGauge: datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
+		configReloadsCounter:             datadogClient.NewCounter(ddConfigReloadsName, 1.0),
+		lastConfigReloadSuccessGauge:     datadogClient.NewGauge(ddLastConfigReloadSuccessName),
+		openConnectionsGauge:             datadogClient.NewGauge(ddOpenConnsName),
+		tlsCertsNotAfterTimestampGauge:   datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
+		tcpRateLimitRejectedConnsCounter: datadogClient.NewCounter(ddTCPRateLimitRejectedConnsName, 1.0),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -49,6 +49,8 @@ const (
 	influxDBServiceServerUpName     = "traefik.service.server.up"
 	influxDBServiceReqsBytesName    = "traefik.service.requests.bytes.total"
 	influxDBServiceRespsBytesName   = "traefik.service.responses.bytes.total"
+
+	influxDBTCPRateLimitRejectedConnsName = "traefik.tcp.ratelimit.rejected.connections.total"
 )
 
 // RegisterInfluxDB2 creates metrics exporter for InfluxDB2.
@@ -79,10 +81,11 @@ func RegisterInfluxDB2(ctx context.Context, config *types.InfluxDB2) Registry {
 	}
 
 	registry := &standardRegistry{
-		configReloadsCounter:           influxDB2Store.NewCounter(influxDBConfigReloadsName),
-		lastConfigReloadSuccessGauge:   influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
-		openConnectionsGauge:           influxDB2Store.NewGauge(influxDBOpenConnsName),
-		tlsCertsNotAfterTimestampGauge: influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		configReloadsCounter:             influxDB2Store.NewCounter(influxDBConfigReloadsName),
+		lastConfigReloadSuccessGauge:     influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
+		openConnectionsGauge:             influxDB2Store.NewGauge(influxDBOpenConnsName),
+		tlsCertsNotAfterTimestampGauge:   influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		tcpRateLimitRejectedConnsCounter: influxDB2Store.NewCounter(influxDBTCPRateLimitRejectedConnsName),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -54,6 +54,10 @@ type Registry interface {
 	ServiceServerUpGauge() metrics.Gauge
 	ServiceReqsBytesCounter() metrics.Counter
 	ServiceRespsBytesCounter() metrics.Counter
+
+	// TCP middleware metrics
+
+	TCPRateLimitRejectedConnsCounter() metrics.Counter
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -87,6 +91,7 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var serviceServerUpGauge []metrics.Gauge
 	var serviceReqsBytesCounter []metrics.Counter
 	var serviceRespsBytesCounter []metrics.Counter
+	var tcpRateLimitRejectedConnsCounter []metrics.Counter
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -152,61 +157,66 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.ServiceRespsBytesCounter() != nil {
 			serviceRespsBytesCounter = append(serviceRespsBytesCounter, r.ServiceRespsBytesCounter())
 		}
+		if r.TCPRateLimitRejectedConnsCounter() != nil {
+			tcpRateLimitRejectedConnsCounter = append(tcpRateLimitRejectedConnsCounter, r.TCPRateLimitRejectedConnsCounter())
+		}
 	}
 
 	return &standardRegistry{
-		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
-		routerEnabled:                  len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		openConnectionsGauge:           multi.NewGauge(openConnectionsGauge...),
-		tlsCertsNotAfterTimestampGauge: multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
-		entryPointReqsCounter:          NewMultiCounterWithHeaders(entryPointReqsCounter...),
-		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
-		entryPointReqDurationHistogram: MultiHistogram(entryPointReqDurationHistogram),
-		entryPointReqsBytesCounter:     multi.NewCounter(entryPointReqsBytesCounter...),
-		entryPointRespsBytesCounter:    multi.NewCounter(entryPointRespsBytesCounter...),
-		routerReqsCounter:              NewMultiCounterWithHeaders(routerReqsCounter...),
-		routerReqsTLSCounter:           multi.NewCounter(routerReqsTLSCounter...),
-		routerReqDurationHistogram:     MultiHistogram(routerReqDurationHistogram),
-		routerReqsBytesCounter:         multi.NewCounter(routerReqsBytesCounter...),
-		routerRespsBytesCounter:        multi.NewCounter(routerRespsBytesCounter...),
-		serviceReqsCounter:             NewMultiCounterWithHeaders(serviceReqsCounter...),
-		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
-		serviceReqDurationHistogram:    MultiHistogram(serviceReqDurationHistogram),
-		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
-		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
-		serviceReqsBytesCounter:        multi.NewCounter(serviceReqsBytesCounter...),
-		serviceRespsBytesCounter:       multi.NewCounter(serviceRespsBytesCounter...),
+		epEnabled:                        len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0,
+		svcEnabled:                       len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
+		routerEnabled:                    len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0,
+		configReloadsCounter:             multi.NewCounter(configReloadsCounter...),
+		lastConfigReloadSuccessGauge:     multi.NewGauge(lastConfigReloadSuccessGauge...),
+		openConnectionsGauge:             multi.NewGauge(openConnectionsGauge...),
+		tlsCertsNotAfterTimestampGauge:   multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		entryPointReqsCounter:            NewMultiCounterWithHeaders(entryPointReqsCounter...),
+		entryPointReqsTLSCounter:         multi.NewCounter(entryPointReqsTLSCounter...),
+		entryPointReqDurationHistogram:   MultiHistogram(entryPointReqDurationHistogram),
+		entryPointReqsBytesCounter:       multi.NewCounter(entryPointReqsBytesCounter...),
+		entryPointRespsBytesCounter:      multi.NewCounter(entryPointRespsBytesCounter...),
+		routerReqsCounter:                NewMultiCounterWithHeaders(routerReqsCounter...),
+		routerReqsTLSCounter:             multi.NewCounter(routerReqsTLSCounter...),
+		routerReqDurationHistogram:       MultiHistogram(routerReqDurationHistogram),
+		routerReqsBytesCounter:           multi.NewCounter(routerReqsBytesCounter...),
+		routerRespsBytesCounter:          multi.NewCounter(routerRespsBytesCounter...),
+		serviceReqsCounter:               NewMultiCounterWithHeaders(serviceReqsCounter...),
+		serviceReqsTLSCounter:            multi.NewCounter(serviceReqsTLSCounter...),
+		serviceReqDurationHistogram:      MultiHistogram(serviceReqDurationHistogram),
+		serviceRetriesCounter:            multi.NewCounter(serviceRetriesCounter...),
+		serviceServerUpGauge:             multi.NewGauge(serviceServerUpGauge...),
+		serviceReqsBytesCounter:          multi.NewCounter(serviceReqsBytesCounter...),
+		serviceRespsBytesCounter:         multi.NewCounter(serviceRespsBytesCounter...),
+		tcpRateLimitRejectedConnsCounter: multi.NewCounter(tcpRateLimitRejectedConnsCounter...),
 	}
 }
 
 type standardRegistry struct {
-	epEnabled                      bool
-	routerEnabled                  bool
-	svcEnabled                     bool
-	configReloadsCounter           metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	openConnectionsGauge           metrics.Gauge
-	tlsCertsNotAfterTimestampGauge metrics.Gauge
-	entryPointReqsCounter          CounterWithHeaders
-	entryPointReqsTLSCounter       metrics.Counter
-	entryPointReqDurationHistogram ScalableHistogram
-	entryPointReqsBytesCounter     metrics.Counter
-	entryPointRespsBytesCounter    metrics.Counter
-	routerReqsCounter              CounterWithHeaders
-	routerReqsTLSCounter           metrics.Counter
-	routerReqDurationHistogram     ScalableHistogram
-	routerReqsBytesCounter         metrics.Counter
-	routerRespsBytesCounter        metrics.Counter
-	serviceReqsCounter             CounterWithHeaders
-	serviceReqsTLSCounter          metrics.Counter
-	serviceReqDurationHistogram    ScalableHistogram
-	serviceRetriesCounter          metrics.Counter
-	serviceServerUpGauge           metrics.Gauge
-	serviceReqsBytesCounter        metrics.Counter
-	serviceRespsBytesCounter       metrics.Counter
+	epEnabled                        bool
+	routerEnabled                    bool
+	svcEnabled                       bool
+	configReloadsCounter             metrics.Counter
+	lastConfigReloadSuccessGauge     metrics.Gauge
+	openConnectionsGauge             metrics.Gauge
+	tlsCertsNotAfterTimestampGauge   metrics.Gauge
+	entryPointReqsCounter            CounterWithHeaders
+	entryPointReqsTLSCounter         metrics.Counter
+	entryPointReqDurationHistogram   ScalableHistogram
+	entryPointReqsBytesCounter       metrics.Counter
+	entryPointRespsBytesCounter      metrics.Counter
+	routerReqsCounter                CounterWithHeaders
+	routerReqsTLSCounter             metrics.Counter
+	routerReqDurationHistogram       ScalableHistogram
+	routerReqsBytesCounter           metrics.Counter
+	routerRespsBytesCounter          metrics.Counter
+	serviceReqsCounter               CounterWithHeaders
+	serviceReqsTLSCounter            metrics.Counter
+	serviceReqDurationHistogram      ScalableHistogram
+	serviceRetriesCounter            metrics.Counter
+	serviceServerUpGauge             metrics.Gauge
+	serviceReqsBytesCounter          metrics.Counter
+	serviceRespsBytesCounter         metrics.Counter
+	tcpRateLimitRejectedConnsCounter metrics.Counter
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
@@ -305,6 +315,10 @@ func (r *standardRegistry) ServiceRespsBytesCounter() metrics.Counter {
 	return r.serviceRespsBytesCounter
 }
 
+func (r *standardRegistry) TCPRateLimitRejectedConnsCounter() metrics.Counter {
+	return r.tcpRateLimitRejectedConnsCounter
+}
+
 // ScalableHistogram is a Histogram with a predefined time unit,
 // used when producing observations without explicitly setting the observed value.
 type ScalableHistogram interface {
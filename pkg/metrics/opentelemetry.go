@@ -57,6 +57,8 @@ func RegisterOpenTelemetry(ctx context.Context, config *types.OpenTelemetry) Reg
 		lastConfigReloadSuccessGauge:   newOTLPGaugeFrom(meter, configLastReloadSuccessName, "Last config reload success", "ms"),
 		openConnectionsGauge:           newOTLPGaugeFrom(meter, openConnectionsName, "How many open connections exist, by entryPoint and protocol", "1"),
 		tlsCertsNotAfterTimestampGauge: newOTLPGaugeFrom(meter, tlsCertsNotAfterTimestampName, "Certificate expiration timestamp", "ms"),
+		tcpRateLimitRejectedConnsCounter: newOTLPCounterFrom(meter, tcpRateLimitRejectedConnsTotalName,
+			"How many TCP connections were rejected by a rate limit middleware."),
 	}
 
 	if config.AddEntryPointsLabels {
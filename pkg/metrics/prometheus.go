@@ -55,6 +55,10 @@ const (
 	serviceServerUpName        = metricServicePrefix + "server_up"
 	serviceReqsBytesTotalName  = metricServicePrefix + "requests_bytes_total"
 	serviceRespsBytesTotalName = metricServicePrefix + "responses_bytes_total"
+
+	// TCP middleware level.
+	metricTCPRateLimitPrefix           = MetricNamePrefix + "tcp_ratelimit_"
+	tcpRateLimitRejectedConnsTotalName = metricTCPRateLimitPrefix + "rejected_connections_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -126,22 +130,28 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: openConnectionsName,
 		Help: "How many open connections exist, by entryPoint and protocol",
 	}, []string{"entrypoint", "protocol"})
+	tcpRateLimitRejectedConns := newCounterFrom(stdprometheus.CounterOpts{
+		Name: tcpRateLimitRejectedConnsTotalName,
+		Help: "How many TCP connections were rejected by a rate limit middleware.",
+	}, []string{"middleware"})
 
 	promState.vectors = []vector{
 		configReloads.cv,
 		lastConfigReloadSuccess.gv,
 		tlsCertsNotAfterTimestamp.gv,
 		openConnections.gv,
+		tcpRateLimitRejectedConns.cv,
 	}
 
 	reg := &standardRegistry{
-		epEnabled:                      config.AddEntryPointsLabels,
-		routerEnabled:                  config.AddRoutersLabels,
-		svcEnabled:                     config.AddServicesLabels,
-		configReloadsCounter:           configReloads,
-		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
-		tlsCertsNotAfterTimestampGauge: tlsCertsNotAfterTimestamp,
-		openConnectionsGauge:           openConnections,
+		epEnabled:                        config.AddEntryPointsLabels,
+		routerEnabled:                    config.AddRoutersLabels,
+		svcEnabled:                       config.AddServicesLabels,
+		configReloadsCounter:             configReloads,
+		lastConfigReloadSuccessGauge:     lastConfigReloadSuccess,
+		tlsCertsNotAfterTimestampGauge:   tlsCertsNotAfterTimestamp,
+		openConnectionsGauge:             openConnections,
+		tcpRateLimitRejectedConnsCounter: tcpRateLimitRejectedConns,
 	}
 
 	if config.AddEntryPointsLabels {
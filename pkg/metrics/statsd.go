@@ -42,6 +42,8 @@ const (
 	statsdServiceServerUpName     = "service.server.up"
 	statsdServiceReqsBytesName    = "service.requests.bytes.total"
 	statsdServiceRespsBytesName   = "service.responses.bytes.total"
+
+	statsdTCPRateLimitRejectedConnsName = "tcp.ratelimit.rejected.connections.total"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -58,10 +60,11 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 	}
 
 	registry := &standardRegistry{
-		configReloadsCounter:           statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
-		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
-		tlsCertsNotAfterTimestampGauge: statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
-		openConnectionsGauge:           statsdClient.NewGauge(statsdOpenConnectionsName),
+		configReloadsCounter:             statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
+		lastConfigReloadSuccessGauge:     statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
+		tlsCertsNotAfterTimestampGauge:   statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
+		openConnectionsGauge:             statsdClient.NewGauge(statsdOpenConnectionsName),
+		tcpRateLimitRejectedConnsCounter: statsdClient.NewCounter(statsdTCPRateLimitRejectedConnsName, 1.0),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -77,6 +77,23 @@ const (
 	TLSCipher = "TLSCipher"
 	// TLSClientSubject is the string representation of the TLS client certificate's Subject.
 	TLSClientSubject = "TLSClientSubject"
+
+	// GeoCountry is the map key used for the country code of the client IP, as looked up by the GeoIP middleware.
+	GeoCountry = "GeoCountry"
+	// GeoASN is the map key used for the autonomous system number of the client IP, as looked up by the GeoIP middleware.
+	GeoASN = "GeoASN"
+
+	// CacheStatus is the map key used for the cache status of the response, as reported by the Cache middleware.
+	CacheStatus = "CacheStatus"
+
+	// TLSServerName is the map key used for the server name (SNI) requested by the client of a TCP connection.
+	TLSServerName = "TLSServerName"
+	// BytesReceived is the map key used for the number of bytes received from the client of a TCP connection.
+	BytesReceived = "BytesReceived"
+	// BytesSent is the map key used for the number of bytes sent to the client of a TCP connection.
+	BytesSent = "BytesSent"
+	// CloseReason is the map key used for the reason why a TCP connection was closed.
+	CloseReason = "CloseReason"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -121,6 +138,13 @@ func init() {
 	allCoreKeys[TLSVersion] = struct{}{}
 	allCoreKeys[TLSCipher] = struct{}{}
 	allCoreKeys[TLSClientSubject] = struct{}{}
+	allCoreKeys[GeoCountry] = struct{}{}
+	allCoreKeys[GeoASN] = struct{}{}
+	allCoreKeys[CacheStatus] = struct{}{}
+	allCoreKeys[TLSServerName] = struct{}{}
+	allCoreKeys[BytesReceived] = struct{}{}
+	allCoreKeys[BytesSent] = struct{}{}
+	allCoreKeys[CloseReason] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
@@ -52,6 +52,40 @@ func (f *CommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return b.Bytes(), err
 }
 
+// TCPCommonLogFormatter provides formatting of the TCP connections in the Traefik common log format.
+type TCPCommonLogFormatter struct{}
+
+// Format formats the log entry of a TCP connection in the Traefik common log format.
+func (f *TCPCommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	timestamp := defaultValue
+	if v, ok := entry.Data[StartUTC]; ok {
+		timestamp = v.(time.Time).Format(commonLogTimeFormat)
+	} else if v, ok := entry.Data[StartLocal]; ok {
+		timestamp = v.(time.Time).Local().Format(commonLogTimeFormat)
+	}
+
+	var elapsedMillis int64
+	if v, ok := entry.Data[Duration]; ok {
+		elapsedMillis = v.(time.Duration).Nanoseconds() / 1000000
+	}
+
+	_, err := fmt.Fprintf(b, "%s - [%s] %s %s %s %s %v %v %s %dms\n",
+		toLog(entry.Data, ClientHost, defaultValue, false),
+		timestamp,
+		toLog(entry.Data, TLSServerName, `"-"`, true),
+		toLog(entry.Data, RouterName, `"-"`, true),
+		toLog(entry.Data, ServiceName, `"-"`, true),
+		toLog(entry.Data, ServiceAddr, `"-"`, true),
+		toLog(entry.Data, BytesReceived, defaultValue, true),
+		toLog(entry.Data, BytesSent, defaultValue, true),
+		toLog(entry.Data, CloseReason, `"-"`, true),
+		elapsedMillis)
+
+	return b.Bytes(), err
+}
+
 func toLog(fields logrus.Fields, key, defaultValue string, quoted bool) interface{} {
 	if v, ok := fields[key]; ok {
 		if v == nil {
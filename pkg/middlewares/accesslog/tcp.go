@@ -0,0 +1,320 @@
+package accesslog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sirupsen/logrus"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/tcp"
+	"traefik/v3/pkg/types"
+)
+
+// TCPHandler writes each connection handled by the TCP routers to the TCP access log.
+type TCPHandler struct {
+	config         *types.AccessLog
+	logger         *logrus.Logger
+	file           io.WriteCloser
+	mu             sync.Mutex
+	logHandlerChan chan CoreLogData
+	wg             sync.WaitGroup
+}
+
+// NewTCPHandler creates a new TCPHandler.
+// The TCP access log uses the format, the filters, the fields, and the buffering size of the access log,
+// and is written to its own file.
+func NewTCPHandler(config *types.AccessLog) (*TCPHandler, error) {
+	var file io.WriteCloser = noopCloser{os.Stdout}
+	if config.TCP != nil && len(config.TCP.FilePath) > 0 {
+		f, err := openAccessLogFile(config.TCP.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening TCP access log file: %w", err)
+		}
+		file = f
+	}
+
+	var formatter logrus.Formatter
+
+	switch config.Format {
+	case CommonFormat:
+		formatter = new(TCPCommonLogFormatter)
+	case JSONFormat:
+		formatter = new(logrus.JSONFormatter)
+	default:
+		log.Error().Msgf("Unsupported TCP access log format: %q, defaulting to common format instead.", config.Format)
+		formatter = new(TCPCommonLogFormatter)
+	}
+
+	logHandler := &TCPHandler{
+		config: config,
+		logger: &logrus.Logger{
+			Out:       file,
+			Formatter: formatter,
+			Hooks:     make(logrus.LevelHooks),
+			Level:     logrus.InfoLevel,
+		},
+		file:           file,
+		logHandlerChan: make(chan CoreLogData, config.BufferingSize),
+	}
+
+	if config.BufferingSize > 0 {
+		logHandler.wg.Add(1)
+		go func() {
+			defer logHandler.wg.Done()
+			for core := range logHandler.logHandlerChan {
+				logHandler.logTheConnection(core)
+			}
+		}()
+	}
+
+	return logHandler, nil
+}
+
+// ServeTCP handles the connection with the next handler, and then logs it.
+func (h *TCPHandler) ServeTCP(conn tcp.WriteCloser, next tcp.Handler) {
+	now := time.Now().UTC()
+
+	core := CoreLogData{
+		StartUTC:   now,
+		StartLocal: now.Local(),
+	}
+
+	core[ClientAddr] = conn.RemoteAddr().String()
+	core[ClientHost], core[ClientPort] = silentSplitHostPort(conn.RemoteAddr().String())
+
+	if sn, ok := conn.(interface{ ServerName() string }); ok && sn.ServerName() != "" {
+		core[TLSServerName] = sn.ServerName()
+	}
+
+	logConn := &tcpLogConn{WriteCloser: conn, core: core}
+
+	defer func() {
+		core[BytesReceived] = logConn.received.Load()
+		core[BytesSent] = logConn.sent.Load()
+		core[CloseReason] = logConn.closeReason()
+
+		if h.config.BufferingSize > 0 {
+			h.logHandlerChan <- core
+			return
+		}
+		h.logTheConnection(core)
+	}()
+
+	next.ServeTCP(logConn)
+}
+
+// Close closes the Logger (i.e. the file, drain logHandlerChan, etc).
+func (h *TCPHandler) Close() error {
+	close(h.logHandlerChan)
+	h.wg.Wait()
+	return h.file.Close()
+}
+
+// Rotate closes and reopens the log file to allow for rotation by an external source.
+func (h *TCPHandler) Rotate() error {
+	if h.config.TCP == nil || h.config.TCP.FilePath == "" {
+		return nil
+	}
+
+	if h.file != nil {
+		defer func(f io.Closer) { _ = f.Close() }(h.file)
+	}
+
+	var err error
+	h.file, err = os.OpenFile(h.config.TCP.FilePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o664)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.logger.Out = h.file
+	return nil
+}
+
+func (h *TCPHandler) logTheConnection(core CoreLogData) {
+	// n.b. take care to perform time arithmetic using UTC to avoid errors at DST boundaries.
+	totalDuration := time.Now().UTC().Sub(core[StartUTC].(time.Time))
+	core[Duration] = totalDuration
+
+	if !h.keepAccessLog(totalDuration) {
+		return
+	}
+
+	fields := logrus.Fields{}
+
+	for k, v := range core {
+		if h.config.Fields.Keep(k) {
+			fields[k] = v
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.logger.WithFields(fields).Println()
+}
+
+// keepAccessLog applies the duration filter, the status codes and retry attempts filters only apply to HTTP.
+func (h *TCPHandler) keepAccessLog(duration time.Duration) bool {
+	if h.config.Filters == nil || h.config.Filters.MinDuration == 0 {
+		return true
+	}
+
+	return ptypes.Duration(duration) > h.config.Filters.MinDuration
+}
+
+// NewTCPFieldHandler creates a TCP field handler, setting the given field of the TCP access log of the connections.
+func NewTCPFieldHandler(next tcp.Handler, name string, value interface{}) tcp.Handler {
+	return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		if logConn := getTCPLogConn(conn); logConn != nil {
+			logConn.core[name] = value
+		}
+
+		next.ServeTCP(conn)
+	})
+}
+
+// SetTCPCloseReason sets the reason why the given TCP connection is closed,
+// unless a reason has already been recorded.
+func SetTCPCloseReason(conn net.Conn, reason string) {
+	if logConn := getTCPLogConn(conn); logConn != nil {
+		logConn.setCloseReason(reason)
+	}
+}
+
+// getTCPLogConn returns the TCP access log connection wrapped by the given connection,
+// following the connections wrapping another one through the NetConn method, such as the TLS connections.
+func getTCPLogConn(conn net.Conn) *tcpLogConn {
+	for conn != nil {
+		switch c := conn.(type) {
+		case *tcpLogConn:
+			return c
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// NewTCPProxyHandler creates a handler setting the service fields of the TCP access log of the connections
+// handled by the given proxy, and recording why the proxy ends the connections.
+func NewTCPProxyHandler(next tcp.Handler, serviceName, serviceAddr string) tcp.Handler {
+	return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		logConn := getTCPLogConn(conn)
+		if logConn == nil {
+			next.ServeTCP(conn)
+			return
+		}
+
+		logConn.core[ServiceName] = serviceName
+		logConn.core[ServiceAddr] = serviceAddr
+
+		next.ServeTCP(&proxiedConn{WriteCloser: conn, logConn: logConn})
+	})
+}
+
+// proxiedConn records why the connection is ended, as seen by the proxy,
+// i.e. after the TLS termination and the middlewares.
+type proxiedConn struct {
+	tcp.WriteCloser
+
+	logConn *tcpLogConn
+}
+
+func (c *proxiedConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		c.logConn.setCloseReason("client closed")
+	case err != nil:
+		c.logConn.setCloseReason(fmt.Sprintf("client error: %v", err))
+	}
+
+	return n, err
+}
+
+func (c *proxiedConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	if err != nil {
+		c.logConn.setCloseReason(fmt.Sprintf("client error: %v", err))
+	}
+
+	return n, err
+}
+
+// CloseWrite is called by the proxy when the backend has ended the connection.
+func (c *proxiedConn) CloseWrite() error {
+	c.logConn.setCloseReason("backend closed")
+
+	return c.WriteCloser.CloseWrite()
+}
+
+// Close is called by the proxy when the connection has been handled,
+// before any read or write when the connection to the backend failed.
+func (c *proxiedConn) Close() error {
+	c.logConn.setCloseReason("backend connection error")
+
+	return c.WriteCloser.Close()
+}
+
+// NetConn returns the underlying connection.
+func (c *proxiedConn) NetConn() net.Conn {
+	return c.WriteCloser
+}
+
+// tcpLogConn counts the bytes received from and sent to the client,
+// and holds the TCP access log data of the connection.
+type tcpLogConn struct {
+	tcp.WriteCloser
+
+	core     CoreLogData
+	received atomic.Int64
+	sent     atomic.Int64
+
+	mu     sync.Mutex
+	reason string
+}
+
+func (c *tcpLogConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	c.received.Add(int64(n))
+
+	return n, err
+}
+
+func (c *tcpLogConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	c.sent.Add(int64(n))
+
+	return n, err
+}
+
+func (c *tcpLogConn) setCloseReason(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reason == "" {
+		c.reason = reason
+	}
+}
+
+func (c *tcpLogConn) closeReason() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reason == "" {
+		return "closed by traefik"
+	}
+
+	return c.reason
+}
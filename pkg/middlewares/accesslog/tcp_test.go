@@ -0,0 +1,225 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/tcp"
+	"traefik/v3/pkg/types"
+)
+
+func TestTCPHandler_JSON(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		handler  tcp.Handler
+		client   func(t *testing.T, conn net.Conn)
+		expected map[string]interface{}
+		missing  []string
+	}{
+		{
+			desc: "client closed",
+			handler: NewTCPProxyHandler(tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				defer conn.Close()
+
+				_, _ = conn.Write([]byte("hello"))
+				_, _ = io.ReadAll(conn)
+			}), "service@file", "10.0.0.1:5432"),
+			client: func(t *testing.T, conn net.Conn) {
+				t.Helper()
+
+				_, err := io.ReadFull(conn, make([]byte, 5))
+				require.NoError(t, err)
+
+				_, err = conn.Write([]byte("foo"))
+				require.NoError(t, err)
+			},
+			expected: map[string]interface{}{
+				ServiceName:   "service@file",
+				ServiceAddr:   "10.0.0.1:5432",
+				BytesReceived: float64(3),
+				BytesSent:     float64(5),
+				CloseReason:   "client closed",
+			},
+		},
+		{
+			desc: "backend closed",
+			handler: NewTCPProxyHandler(tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				defer conn.Close()
+
+				_, _ = conn.Write([]byte("hello"))
+				_ = conn.CloseWrite()
+			}), "service@file", "10.0.0.1:5432"),
+			client: func(t *testing.T, conn net.Conn) {
+				t.Helper()
+
+				_, err := io.ReadFull(conn, make([]byte, 5))
+				require.NoError(t, err)
+			},
+			expected: map[string]interface{}{
+				BytesReceived: float64(0),
+				BytesSent:     float64(5),
+				CloseReason:   "backend closed",
+			},
+		},
+		{
+			desc: "backend connection error",
+			handler: NewTCPProxyHandler(tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				conn.Close()
+			}), "service@file", "10.0.0.1:5432"),
+			expected: map[string]interface{}{
+				ServiceName: "service@file",
+				CloseReason: "backend connection error",
+			},
+		},
+		{
+			desc: "rejected by a middleware",
+			handler: tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				SetTCPCloseReason(conn, "rejected by test")
+				conn.Close()
+			}),
+			expected: map[string]interface{}{
+				BytesReceived: float64(0),
+				BytesSent:     float64(0),
+				CloseReason:   "rejected by test",
+			},
+			missing: []string{ServiceName, ServiceAddr},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			logFilePath := filepath.Join(t.TempDir(), "tcp.log")
+
+			logger, err := NewTCPHandler(&types.AccessLog{
+				Format: JSONFormat,
+				TCP:    &types.TCPAccessLog{FilePath: logFilePath},
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = logger.Close() })
+
+			serverConn, clientConn := net.Pipe()
+
+			go func() {
+				defer clientConn.Close()
+
+				if test.client != nil {
+					test.client(t, clientConn)
+				}
+			}()
+
+			handler := NewTCPFieldHandler(test.handler, RouterName, "router@file")
+			logger.ServeTCP(&testConn{Conn: serverConn, serverName: "foo.bar"}, handler)
+
+			logData, err := os.ReadFile(logFilePath)
+			require.NoError(t, err)
+
+			fields := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(logData, &fields))
+
+			assert.Equal(t, "router@file", fields[RouterName])
+			assert.Equal(t, "foo.bar", fields[TLSServerName])
+			assert.Equal(t, "pipe", fields[ClientAddr])
+			assert.NotEmpty(t, fields[StartUTC])
+			assert.NotEmpty(t, fields[Duration])
+
+			for field, value := range test.expected {
+				assert.Equal(t, value, fields[field], field)
+			}
+
+			for _, field := range test.missing {
+				assert.NotContains(t, fields, field)
+			}
+		})
+	}
+}
+
+func TestTCPHandler_CommonFormat(t *testing.T) {
+	logFilePath := filepath.Join(t.TempDir(), "tcp.log")
+
+	logger, err := NewTCPHandler(&types.AccessLog{
+		Format: CommonFormat,
+		TCP:    &types.TCPAccessLog{FilePath: logFilePath},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+
+	serverConn, clientConn := net.Pipe()
+	go func() {
+		_, _ = clientConn.Write([]byte("foo"))
+		_ = clientConn.Close()
+	}()
+
+	handler := NewTCPFieldHandler(NewTCPProxyHandler(tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		defer conn.Close()
+
+		_, _ = io.ReadAll(conn)
+	}), "service@file", "10.0.0.1:5432"), RouterName, "router@file")
+
+	logger.ServeTCP(&testConn{Conn: serverConn}, handler)
+
+	logData, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	assert.Regexp(t, regexp.MustCompile(`^pipe - \[[^]]+\] "-" "router@file" "service@file" "10.0.0.1:5432" 3 0 "client closed" \d+ms\n$`), string(logData))
+}
+
+func TestTCPHandler_MinDuration(t *testing.T) {
+	logFilePath := filepath.Join(t.TempDir(), "tcp.log")
+
+	logger, err := NewTCPHandler(&types.AccessLog{
+		Format:  JSONFormat,
+		Filters: &types.AccessLogFilters{MinDuration: ptypes.Duration(100 * time.Millisecond)},
+		TCP:     &types.TCPAccessLog{FilePath: logFilePath},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+
+	handler := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		conn.Close()
+	})
+
+	serverConn, _ := net.Pipe()
+	logger.ServeTCP(&testConn{Conn: serverConn}, handler)
+
+	logData, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+	assert.Empty(t, logData)
+
+	handler = func(conn tcp.WriteCloser) {
+		time.Sleep(150 * time.Millisecond)
+		conn.Close()
+	}
+
+	serverConn, _ = net.Pipe()
+	logger.ServeTCP(&testConn{Conn: serverConn}, handler)
+
+	logData, err = os.ReadFile(logFilePath)
+	require.NoError(t, err)
+	assert.NotEmpty(t, logData)
+}
+
+type testConn struct {
+	net.Conn
+
+	serverName string
+}
+
+func (c *testConn) CloseWrite() error {
+	return c.Conn.Close()
+}
+
+func (c *testConn) ServerName() string {
+	return c.serverName
+}
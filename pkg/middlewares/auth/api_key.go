@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/crypto/bcrypt"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/tracing"
+)
+
+const (
+	apiKeyTypeName = "APIKey"
+
+	sha256KeyPrefix = "{SHA256}"
+
+	// apiKeyCacheSize is the maximum number of verified bcrypt-hashed keys kept in memory.
+	apiKeyCacheSize = 1000
+)
+
+type apiKeyAuth struct {
+	next        http.Handler
+	name        string
+	headerName  string
+	queryParam  string
+	cookieName  string
+	headerField string
+	removeKey   bool
+
+	// sha256Keys holds the owners of the SHA-256-hashed keys, by digest.
+	sha256Keys map[[sha256.Size]byte]string
+	// bcryptKeys holds the bcrypt-hashed keys, by owner.
+	// As bcrypt is, by design, slow, these keys are sent prefixed by their owner,
+	// so that at most one hash is computed for each request.
+	bcryptKeys map[string][]byte
+
+	// verified caches the owners of the bcrypt-hashed keys already verified, by digest,
+	// as bcrypt is, by design, too slow to be computed on every request.
+	verifiedMu sync.Mutex
+	verified   map[[sha256.Size]byte]string
+}
+
+// NewAPIKey creates an APIKey middleware.
+func NewAPIKey(ctx context.Context, next http.Handler, config dynamic.APIKey, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, apiKeyTypeName).Debug().Msg("Creating middleware")
+
+	keys, err := loadUsers(config.KeysFile, config.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key must be defined")
+	}
+
+	ak := &apiKeyAuth{
+		next:        next,
+		name:        name,
+		headerName:  config.HeaderName,
+		queryParam:  config.QueryParam,
+		cookieName:  config.CookieName,
+		headerField: config.HeaderField,
+		removeKey:   config.RemoveKey,
+		sha256Keys:  make(map[[sha256.Size]byte]string),
+		bcryptKeys:  make(map[string][]byte),
+		verified:    make(map[[sha256.Size]byte]string),
+	}
+
+	if ak.headerName == "" && ak.queryParam == "" && ak.cookieName == "" {
+		ak.headerName = "X-API-Key"
+	}
+
+	for _, key := range keys {
+		owner, hash, found := strings.Cut(key, ":")
+		if !found || owner == "" {
+			// The entry is not logged, as it may be a key.
+			return nil, errors.New("error parsing API key: the owner:hashed-key format must be used")
+		}
+
+		switch {
+		case strings.HasPrefix(hash, sha256KeyPrefix):
+			digest, err := hex.DecodeString(strings.TrimPrefix(hash, sha256KeyPrefix))
+			if err != nil || len(digest) != sha256.Size {
+				return nil, fmt.Errorf("invalid SHA-256 hash for the API key of %s", owner)
+			}
+
+			ak.sha256Keys[[sha256.Size]byte(digest)] = owner
+
+		case strings.HasPrefix(hash, "$2"):
+			if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+				return nil, fmt.Errorf("invalid bcrypt hash for the API key of %s: %w", owner, err)
+			}
+
+			if _, ok := ak.bcryptKeys[owner]; ok {
+				return nil, fmt.Errorf("several bcrypt-hashed API keys for %s: the owner must identify the key", owner)
+			}
+
+			ak.bcryptKeys[owner] = []byte(hash)
+
+		default:
+			return nil, fmt.Errorf("unsupported hash for the API key of %s", owner)
+		}
+	}
+
+	return ak, nil
+}
+
+func (a *apiKeyAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *apiKeyAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), a.name, apiKeyTypeName)
+
+	owner, ok := a.authenticate(a.extractKey(req))
+	if !ok {
+		logger.Debug().Msg("Authentication failed")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	logger.Debug().Msg("Authentication succeeded")
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.Core[accesslog.ClientUsername] = owner
+	}
+
+	if a.headerField != "" {
+		req.Header[a.headerField] = []string{owner}
+	}
+
+	if a.removeKey {
+		logger.Debug().Msg("Removing API key")
+		a.remove(req)
+	}
+
+	a.next.ServeHTTP(rw, req)
+}
+
+// extractKey returns the key of the request, looked up in the header, the query parameter, and the cookie, in this order.
+func (a *apiKeyAuth) extractKey(req *http.Request) string {
+	if a.headerName != "" {
+		if key := req.Header.Get(a.headerName); key != "" {
+			return key
+		}
+	}
+
+	if a.queryParam != "" {
+		if key := req.URL.Query().Get(a.queryParam); key != "" {
+			return key
+		}
+	}
+
+	if a.cookieName != "" {
+		if cookie, err := req.Cookie(a.cookieName); err == nil {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+// authenticate returns the owner of the given key, if it is authorized.
+func (a *apiKeyAuth) authenticate(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	digest := sha256.Sum256([]byte(key))
+
+	if owner, ok := a.sha256Keys[digest]; ok {
+		return owner, true
+	}
+
+	// The bcrypt-hashed keys are sent in the owner:key format.
+	owner, secret, found := strings.Cut(key, ":")
+	if !found {
+		return "", false
+	}
+
+	hash, ok := a.bcryptKeys[owner]
+	if !ok {
+		return "", false
+	}
+
+	a.verifiedMu.Lock()
+	verified := a.verified[digest] == owner
+	a.verifiedMu.Unlock()
+
+	if verified {
+		return owner, true
+	}
+
+	if bcrypt.CompareHashAndPassword(hash, []byte(secret)) != nil {
+		return "", false
+	}
+
+	a.verifiedMu.Lock()
+	if len(a.verified) >= apiKeyCacheSize {
+		a.verified = make(map[[sha256.Size]byte]string)
+	}
+	a.verified[digest] = owner
+	a.verifiedMu.Unlock()
+
+	return owner, true
+}
+
+// remove removes the key from the request.
+func (a *apiKeyAuth) remove(req *http.Request) {
+	if a.headerName != "" {
+		req.Header.Del(a.headerName)
+	}
+
+	if a.queryParam != "" {
+		query := req.URL.Query()
+		if query.Has(a.queryParam) {
+			query.Del(a.queryParam)
+			req.URL.RawQuery = query.Encode()
+			req.RequestURI = req.URL.RequestURI()
+		}
+	}
+
+	if a.cookieName != "" {
+		removeCookie(req, a.cookieName)
+	}
+}
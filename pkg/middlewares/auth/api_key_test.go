@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestAPIKey(t *testing.T) {
+	digest := sha256.Sum256([]byte("sha-key"))
+	sha256Key := "sha-owner:{SHA256}" + hex.EncodeToString(digest[:])
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("bcrypt-key"), bcrypt.MinCost)
+	require.NoError(t, err)
+	bcryptKey := "bcrypt-owner:" + string(hash)
+
+	testCases := []struct {
+		desc       string
+		config     dynamic.APIKey
+		target     string
+		header     http.Header
+		cookie     *http.Cookie
+		expCode    int
+		expHeaders map[string]string
+		expURI     string
+	}{
+		{
+			desc:    "missing key",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key}},
+			target:  "/",
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:    "unknown key",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key, bcryptKey}},
+			target:  "/",
+			header:  http.Header{"X-Api-Key": {"unknown"}},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:    "SHA-256-hashed key in the default header",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key, bcryptKey}, HeaderField: "X-Owner"},
+			target:  "/",
+			header:  http.Header{"X-Api-Key": {"sha-key"}},
+			expCode: http.StatusOK,
+			expHeaders: map[string]string{
+				"X-Owner":   "sha-owner",
+				"X-Api-Key": "sha-key",
+			},
+		},
+		{
+			desc:    "bcrypt-hashed key in the default header",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key, bcryptKey}, HeaderField: "X-Owner"},
+			target:  "/",
+			header:  http.Header{"X-Api-Key": {"bcrypt-owner:bcrypt-key"}},
+			expCode: http.StatusOK,
+			expHeaders: map[string]string{
+				"X-Owner": "bcrypt-owner",
+			},
+		},
+		{
+			desc:    "bcrypt-hashed key without its owner",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key, bcryptKey}},
+			target:  "/",
+			header:  http.Header{"X-Api-Key": {"bcrypt-key"}},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:    "bcrypt-hashed key with another owner",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key, bcryptKey}},
+			target:  "/",
+			header:  http.Header{"X-Api-Key": {"sha-owner:bcrypt-key"}},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:    "key in another header",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key}, HeaderName: "X-Token", RemoveKey: true},
+			target:  "/",
+			header:  http.Header{"X-Token": {"sha-key"}},
+			expCode: http.StatusOK,
+			expHeaders: map[string]string{
+				"X-Token": "",
+			},
+		},
+		{
+			desc:    "key in the query",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key}, QueryParam: "api_key", RemoveKey: true},
+			target:  "/foo?api_key=sha-key&bar=baz",
+			expCode: http.StatusOK,
+			expURI:  "/foo?bar=baz",
+		},
+		{
+			desc:    "key in a cookie",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key}, CookieName: "api_key", RemoveKey: true},
+			target:  "/",
+			cookie:  &http.Cookie{Name: "api_key", Value: "sha-key"},
+			expCode: http.StatusOK,
+			expHeaders: map[string]string{
+				"Cookie": "",
+			},
+		},
+		{
+			desc:    "key in an unexpected location",
+			config:  dynamic.APIKey{Keys: dynamic.Users{sha256Key}, HeaderName: "X-Token"},
+			target:  "/foo?api_key=sha-key",
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwarded *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req
+			})
+
+			handler, err := NewAPIKey(context.Background(), next, test.config, "api-key-test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost"+test.target, nil)
+			for name, values := range test.header {
+				req.Header[name] = values
+			}
+			if test.cookie != nil {
+				req.AddCookie(test.cookie)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expCode, recorder.Code)
+
+			if test.expCode != http.StatusOK {
+				return
+			}
+
+			for name, value := range test.expHeaders {
+				assert.Equal(t, value, forwarded.Header.Get(name), name)
+			}
+
+			if test.expURI != "" {
+				assert.Equal(t, test.expURI, forwarded.RequestURI)
+				assert.Equal(t, test.expURI, forwarded.URL.RequestURI())
+			}
+		})
+	}
+}
+
+func TestAPIKey_keysFile(t *testing.T) {
+	digest := sha256.Sum256([]byte("file-key"))
+
+	keysFile := filepath.Join(t.TempDir(), "keys")
+	err := os.WriteFile(keysFile, []byte("# Keys\nfile-owner:{SHA256}"+hex.EncodeToString(digest[:])+"\n"), 0o600)
+	require.NoError(t, err)
+
+	var owner string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		owner = req.Header.Get("X-Owner")
+	})
+
+	handler, err := NewAPIKey(context.Background(), next, dynamic.APIKey{KeysFile: keysFile, HeaderField: "X-Owner"}, "api-key-test")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("X-API-Key", "file-key")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "file-owner", owner)
+}
+
+func TestNewAPIKey_invalidKeys(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	testCases := []struct {
+		desc string
+		keys dynamic.Users
+	}{
+		{
+			desc: "no keys",
+		},
+		{
+			desc: "missing owner",
+			keys: dynamic.Users{"{SHA256}2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+		},
+		{
+			desc: "invalid SHA-256 hash",
+			keys: dynamic.Users{"owner:{SHA256}foo"},
+		},
+		{
+			desc: "invalid bcrypt hash",
+			keys: dynamic.Users{"owner:$2y$foo"},
+		},
+		{
+			desc: "several bcrypt hashes for the same owner",
+			keys: dynamic.Users{
+				"owner:$2a$04$dt7pxLvLkwGL6nJXs3ZrEuDv7VqS/398pZWfJo3wXxpEcUIZdE5J.",
+				"owner:$2a$04$7HFNMtLU26F8ePiBNT7t8OMLrhsnbOUh0HViEPiMF/TLrsXWYmS5u",
+			},
+		},
+		{
+			desc: "plain key",
+			keys: dynamic.Users{"owner:key"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewAPIKey(context.Background(), next, dynamic.APIKey{Keys: test.keys}, "api-key-test")
+			assert.Error(t, err)
+		})
+	}
+}
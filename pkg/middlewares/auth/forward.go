@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -47,6 +49,8 @@ type forwardAuth struct {
 	client                   http.Client
 	trustForwardHeader       bool
 	authRequestHeaders       []string
+	cache                    *forwardAuthCache
+	extAuthz                 *extAuthzClient
 }
 
 // NewForward creates a forward auth middleware.
@@ -70,8 +74,10 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		Timeout: 30 * time.Second,
 	}
 
+	var tlsConfig *tls.Config
 	if config.TLS != nil {
-		tlsConfig, err := config.TLS.CreateTLSConfig(ctx)
+		var err error
+		tlsConfig, err = config.TLS.CreateTLSConfig(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("unable to create client TLS configuration: %w", err)
 		}
@@ -81,6 +87,23 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		fa.client.Transport = tr
 	}
 
+	if config.ExtAuthz != nil {
+		var connKey []byte
+		if config.TLS != nil {
+			var err error
+			connKey, err = json.Marshal(config.TLS)
+			if err != nil {
+				return nil, fmt.Errorf("marshaling TLS configuration: %w", err)
+			}
+		}
+
+		client, err := newExtAuthzClient(ctx, config.Address, tlsConfig, string(connKey), config.ExtAuthz)
+		if err != nil {
+			return nil, err
+		}
+		fa.extAuthz = client
+	}
+
 	if config.AuthResponseHeadersRegex != "" {
 		re, err := regexp.Compile(config.AuthResponseHeadersRegex)
 		if err != nil {
@@ -89,6 +112,14 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		fa.authResponseHeadersRegex = re
 	}
 
+	if config.Cache != nil {
+		cache, err := newForwardAuthCache(config.Cache)
+		if err != nil {
+			return nil, fmt.Errorf("error creating cache: %w", err)
+		}
+		fa.cache = cache
+	}
+
 	return connectionheader.Remover(fa), nil
 }
 
@@ -99,7 +130,13 @@ func (fa *forwardAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logger := middlewares.GetLogger(req.Context(), fa.name, forwardedTypeName)
 
-	forwardReq, err := http.NewRequest(http.MethodGet, fa.address, nil)
+	forwardURL := fa.address
+	if fa.extAuthz != nil {
+		// The authentication request only holds the headers sent to the ext_authz server.
+		forwardURL = "grpc://" + fa.address
+	}
+
+	forwardReq, err := http.NewRequest(http.MethodGet, forwardURL, nil)
 	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
 	if err != nil {
 		logMessage := fmt.Sprintf("Error calling %s. Cause %s", fa.address, err)
@@ -116,56 +153,97 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	writeHeader(req, forwardReq, fa.trustForwardHeader, fa.authRequestHeaders)
 
-	forwardResponse, forwardErr := fa.client.Do(forwardReq)
-	if forwardErr != nil {
-		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, forwardErr)
-		logger.Debug().Msg(logMessage)
-		tracing.SetErrorWithEvent(req, logMessage)
+	var cacheKey string
+	var cached bool
+	if fa.cache != nil {
+		cacheKey, cached = fa.cache.key(forwardReq)
+	}
 
-		rw.WriteHeader(http.StatusInternalServerError)
-		return
+	if cached {
+		if response, ok := fa.cache.get(cacheKey); ok {
+			logger.Debug().Msg("Using cached authentication server response")
+			fa.applyResponse(rw, req, response)
+			return
+		}
 	}
 
-	body, readError := io.ReadAll(forwardResponse.Body)
-	if readError != nil {
-		logMessage := fmt.Sprintf("Error reading body %s. Cause: %s", fa.address, readError)
+	var response *forwardAuthResponse
+	if fa.extAuthz != nil {
+		response, err = fa.extAuthz.check(req.Context(), req, forwardReq)
+	} else {
+		response, err = fa.call(forwardReq)
+	}
+	if err != nil {
+		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, err)
 		logger.Debug().Msg(logMessage)
 		tracing.SetErrorWithEvent(req, logMessage)
 
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	defer forwardResponse.Body.Close()
 
-	// Pass the forward response's body and selected headers if it
-	// didn't return a response within the range of [200, 300).
-	if forwardResponse.StatusCode < http.StatusOK || forwardResponse.StatusCode >= http.StatusMultipleChoices {
-		logger.Debug().Msgf("Remote error %s. StatusCode: %d", fa.address, forwardResponse.StatusCode)
+	if cached && response.cacheable() {
+		fa.cache.set(cacheKey, response)
+	}
 
-		utils.CopyHeaders(rw.Header(), forwardResponse.Header)
-		utils.RemoveHeaders(rw.Header(), hopHeaders...)
+	fa.applyResponse(rw, req, response)
+}
 
-		// Grab the location header, if any.
-		redirectURL, err := forwardResponse.Location()
+// call sends the authentication request to the authentication server over HTTP.
+func (fa *forwardAuth) call(forwardReq *http.Request) (*forwardAuthResponse, error) {
+	forwardResponse, err := fa.client.Do(forwardReq)
+	if err != nil {
+		return nil, err
+	}
+	defer forwardResponse.Body.Close()
 
-		if err != nil {
-			if !errors.Is(err, http.ErrNoLocation) {
-				logMessage := fmt.Sprintf("Error reading response location header %s. Cause: %s", fa.address, err)
-				logger.Debug().Msg(logMessage)
-				tracing.SetErrorWithEvent(req, logMessage)
+	body, err := io.ReadAll(forwardResponse.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
 
-				rw.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-		} else if redirectURL.String() != "" {
-			// Set the location in our response if one was sent back.
-			rw.Header().Set("Location", redirectURL.String())
+	response := &forwardAuthResponse{
+		statusCode: forwardResponse.StatusCode,
+		header:     forwardResponse.Header,
+		body:       body,
+	}
+
+	if response.allowed() {
+		return response, nil
+	}
+
+	// Grab the location header, if any.
+	redirectURL, err := forwardResponse.Location()
+	if err != nil {
+		if !errors.Is(err, http.ErrNoLocation) {
+			return nil, fmt.Errorf("reading response location header: %w", err)
 		}
+	} else if redirectURL.String() != "" {
+		// Set the resolved location in our response if one was sent back.
+		response.header = response.header.Clone()
+		response.header.Set("Location", redirectURL.String())
+	}
 
-		tracing.LogResponseCode(tracing.GetSpan(req), forwardResponse.StatusCode)
-		rw.WriteHeader(forwardResponse.StatusCode)
+	return response, nil
+}
 
-		if _, err = rw.Write(body); err != nil {
+// applyResponse rejects the request, or forwards it to the next handler,
+// depending on the given response of the authentication server.
+func (fa *forwardAuth) applyResponse(rw http.ResponseWriter, req *http.Request, response *forwardAuthResponse) {
+	logger := middlewares.GetLogger(req.Context(), fa.name, forwardedTypeName)
+
+	// Pass the forward response's body and selected headers if it
+	// didn't return a response within the range of [200, 300).
+	if !response.allowed() {
+		logger.Debug().Msgf("Remote error %s. StatusCode: %d", fa.address, response.statusCode)
+
+		utils.CopyHeaders(rw.Header(), response.header)
+		utils.RemoveHeaders(rw.Header(), hopHeaders...)
+
+		tracing.LogResponseCode(tracing.GetSpan(req), response.statusCode)
+		rw.WriteHeader(response.statusCode)
+
+		if _, err := rw.Write(response.body); err != nil {
 			logger.Error().Err(err).Send()
 		}
 		return
@@ -174,8 +252,8 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	for _, headerName := range fa.authResponseHeaders {
 		headerKey := http.CanonicalHeaderKey(headerName)
 		req.Header.Del(headerKey)
-		if len(forwardResponse.Header[headerKey]) > 0 {
-			req.Header[headerKey] = append([]string(nil), forwardResponse.Header[headerKey]...)
+		if len(response.header[headerKey]) > 0 {
+			req.Header[headerKey] = append([]string(nil), response.header[headerKey]...)
 		}
 	}
 
@@ -186,13 +264,25 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			}
 		}
 
-		for headerKey, headerValues := range forwardResponse.Header {
+		for headerKey, headerValues := range response.header {
 			if fa.authResponseHeadersRegex.MatchString(headerKey) {
 				req.Header[headerKey] = append([]string(nil), headerValues...)
 			}
 		}
 	}
 
+	for _, headerName := range response.removedHeaders {
+		req.Header.Del(headerName)
+	}
+
+	for _, h := range response.requestHeaders {
+		h.apply(req.Header)
+	}
+
+	for _, h := range response.responseHeaders {
+		h.apply(rw.Header())
+	}
+
 	req.RequestURI = req.URL.RequestURI()
 	fa.next.ServeHTTP(rw, req)
 }
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/vulcand/oxy/v2/forward"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+// forwardAuthResponse is a response of the authentication server, as applied to the requests.
+type forwardAuthResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+
+	// The header mutations requested by an ext_authz server, for the allowed requests.
+	requestHeaders  []headerValueOption
+	removedHeaders  []string
+	responseHeaders []headerValueOption
+}
+
+// allowed reports whether the authentication server allowed the request.
+func (r *forwardAuthResponse) allowed() bool {
+	return r.statusCode >= http.StatusOK && r.statusCode < http.StatusMultipleChoices
+}
+
+// cacheable reports whether the response can be reused for other requests with the same cache key.
+// The server errors are never cached, and the authentication server can opt out with the Cache-Control header.
+// The responses setting cookies are never cached either, as the cookies are meant for a single client.
+func (r *forwardAuthResponse) cacheable() bool {
+	if r.statusCode >= http.StatusInternalServerError {
+		return false
+	}
+
+	if len(r.header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	for _, value := range r.header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "no-store", "no-cache":
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+type forwardAuthCacheEntry struct {
+	response  *forwardAuthResponse
+	expiresAt time.Time
+}
+
+// forwardAuthCache is a size-bounded cache of the authentication server responses,
+// keyed by the requested host, method and URI, and by the configured headers and cookies of the authentication requests.
+type forwardAuthCache struct {
+	ttl        time.Duration
+	keyHeaders []string
+	keyCookies []string
+	ignoreURI  bool
+	entries    *lru.Cache
+}
+
+func newForwardAuthCache(config *dynamic.ForwardAuthCache) (*forwardAuthCache, error) {
+	if len(config.KeyHeaders) == 0 && len(config.KeyCookies) == 0 {
+		return nil, errors.New("at least one key header or key cookie must be defined")
+	}
+
+	if config.TTL <= 0 {
+		return nil, fmt.Errorf("invalid TTL: %s", time.Duration(config.TTL))
+	}
+
+	if config.MaxEntries <= 0 {
+		return nil, fmt.Errorf("invalid max entries: %d", config.MaxEntries)
+	}
+
+	entries, err := lru.New(config.MaxEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &forwardAuthCache{
+		ttl:        time.Duration(config.TTL),
+		keyHeaders: config.KeyHeaders,
+		keyCookies: config.KeyCookies,
+		ignoreURI:  config.IgnoreURI,
+		entries:    entries,
+	}, nil
+}
+
+// key returns the cache key of the given authentication request,
+// and false if the request has none of the key headers and cookies,
+// in which case it cannot be told apart from the requests of other anonymous clients.
+func (c *forwardAuthCache) key(forwardReq *http.Request) (string, bool) {
+	hash := sha256.New()
+
+	// The middleware can be shared by several routers,
+	// so a response for a host, a method or a URI must not be reused for another one.
+	forwardedHeaders := []string{forward.XForwardedHost, xForwardedMethod}
+	if !c.ignoreURI {
+		forwardedHeaders = append(forwardedHeaders, xForwardedURI)
+	}
+
+	for _, name := range forwardedHeaders {
+		value := forwardReq.Header.Get(name)
+		_, _ = fmt.Fprintf(hash, "f%d:%s%d:%s", len(name), name, len(value), value)
+	}
+
+	var found bool
+	for _, name := range c.keyHeaders {
+		for _, value := range forwardReq.Header.Values(name) {
+			_, _ = fmt.Fprintf(hash, "h%d:%s%d:%s", len(name), name, len(value), value)
+			found = true
+		}
+	}
+
+	for _, name := range c.keyCookies {
+		if cookie, err := forwardReq.Cookie(name); err == nil {
+			_, _ = fmt.Fprintf(hash, "c%d:%s%d:%s", len(name), name, len(cookie.Value), cookie.Value)
+			found = true
+		}
+	}
+
+	if !found {
+		return "", false
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+func (c *forwardAuthCache) get(key string) (*forwardAuthResponse, bool) {
+	value, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	entry := value.(forwardAuthCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.entries.Remove(key)
+		return nil, false
+	}
+
+	return entry.response, true
+}
+
+func (c *forwardAuthCache) set(key string, response *forwardAuthResponse) {
+	if response.allowed() {
+		// Only the headers of the allowed responses are applied to the requests.
+		allowed := *response
+		allowed.body = nil
+		response = &allowed
+	}
+
+	c.entries.Add(key, forwardAuthCacheEntry{
+		response:  response,
+		expiresAt: time.Now().Add(c.ttl),
+	})
+}
@@ -0,0 +1,472 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vulcand/oxy/v2/forward"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+)
+
+// extAuthzCheckMethod is the method of the Envoy ext_authz service (envoy.service.auth.v3).
+const extAuthzCheckMethod = "/envoy.service.auth.v3.Authorization/Check"
+
+// extAuthzDefaultTimeout is the default maximum duration of a call to the ext_authz server.
+const extAuthzDefaultTimeout = 30 * time.Second
+
+// extAuthzConns holds the gRPC connections to the ext_authz servers, keyed by address and TLS configuration,
+// so that the middlewares rebuilt on each configuration reload share the same connection.
+// A connection is closed once it is no longer used by any middleware.
+var extAuthzConns = middlewares.NewShared(func(conn *grpc.ClientConn) {
+	if err := conn.Close(); err != nil {
+		log.Debug().Err(err).Msg("Error while closing ext_authz gRPC connection")
+	}
+})
+
+// Header append actions (envoy.config.core.v3.HeaderValueOption.HeaderAppendAction).
+// The default APPEND_IF_EXISTS_OR_ADD (0) and OVERWRITE_IF_EXISTS_OR_ADD (2) actions both overwrite the existing values,
+// as the header values of the OK responses replace the existing ones by default.
+const (
+	addIfAbsent       = 1
+	overwriteIfExists = 3
+)
+
+// headerValueOption is a header mutation requested by an ext_authz server.
+type headerValueOption struct {
+	key   string
+	value string
+	// append is whether to append the value to the existing ones.
+	// When not set, the append action is used, and the existing values are replaced by default.
+	append       *bool
+	appendAction uint64
+}
+
+// apply applies the mutation to the given header.
+func (h headerValueOption) apply(header http.Header) {
+	if h.append != nil {
+		if *h.append {
+			header.Add(h.key, h.value)
+		} else {
+			header.Set(h.key, h.value)
+		}
+		return
+	}
+
+	_, exists := header[http.CanonicalHeaderKey(h.key)]
+
+	switch h.appendAction {
+	case addIfAbsent:
+		if !exists {
+			header.Set(h.key, h.value)
+		}
+	case overwriteIfExists:
+		if exists {
+			header.Set(h.key, h.value)
+		}
+	default:
+		header.Set(h.key, h.value)
+	}
+}
+
+// extAuthzClient is a client of the Envoy ext_authz gRPC Check API.
+// The messages are encoded with the subset of the envoy.service.auth.v3 protocol buffers used by the middleware.
+type extAuthzClient struct {
+	conn              *grpc.ClientConn
+	timeout           time.Duration
+	contextExtensions map[string]string
+}
+
+// newExtAuthzClient creates a client of the ext_authz server at the given address,
+// whose connection is released when the given context is done.
+// The connKey identifies the TLS configuration of the connection.
+func newExtAuthzClient(ctx context.Context, address string, tlsConfig *tls.Config, connKey string, config *dynamic.ForwardAuthExtAuthz) (*extAuthzClient, error) {
+	conn, err := extAuthzConns.Acquire(ctx, address+connKey, func() (*grpc.ClientConn, error) {
+		transportCredentials := insecure.NewCredentials()
+		if tlsConfig != nil {
+			transportCredentials = credentials.NewTLS(tlsConfig)
+		}
+
+		// The connection is established lazily, and closed when idle.
+		return grpc.Dial(address,
+			grpc.WithTransportCredentials(transportCredentials),
+			grpc.WithIdleTimeout(time.Minute),
+			grpc.WithDefaultCallOptions(grpc.ForceCodec(extAuthzCodec{})),
+		)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating gRPC client for %s: %w", address, err)
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = extAuthzDefaultTimeout
+	}
+
+	return &extAuthzClient{conn: conn, timeout: timeout, contextExtensions: config.ContextExtensions}, nil
+}
+
+// check asks the ext_authz server whether the request is allowed,
+// based on the authentication request holding the headers to send.
+func (c *extAuthzClient) check(ctx context.Context, req, forwardReq *http.Request) (*forwardAuthResponse, error) {
+	checkReq := newCheckRequest(req, forwardReq, c.contextExtensions)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var checkResp checkResponse
+	if err := c.conn.Invoke(ctx, extAuthzCheckMethod, checkReq, &checkResp); err != nil {
+		return nil, err
+	}
+
+	if checkResp.code == int32(codes.OK) {
+		response := &forwardAuthResponse{statusCode: http.StatusOK, header: make(http.Header)}
+		if checkResp.ok != nil {
+			response.requestHeaders = checkResp.ok.headers
+			response.removedHeaders = checkResp.ok.headersToRemove
+			response.responseHeaders = checkResp.ok.responseHeadersToAdd
+		}
+		return response, nil
+	}
+
+	// As for Envoy, the requests are forbidden when the server does not provide a status.
+	response := &forwardAuthResponse{statusCode: http.StatusForbidden, header: make(http.Header)}
+	if checkResp.denied != nil {
+		if checkResp.denied.status >= 100 && checkResp.denied.status < 600 {
+			response.statusCode = int(checkResp.denied.status)
+		}
+
+		for _, h := range checkResp.denied.headers {
+			h.apply(response.header)
+		}
+
+		response.body = []byte(checkResp.denied.body)
+	}
+
+	return response, nil
+}
+
+// checkRequest is an envoy.service.auth.v3.CheckRequest.
+type checkRequest struct {
+	sourceAddress      string
+	destinationAddress string
+	method             string
+	path               string
+	host               string
+	scheme             string
+	query              string
+	fragment           string
+	protocol           string
+	headers            map[string]string
+	contextExtensions  map[string]string
+	time               time.Time
+}
+
+func newCheckRequest(req, forwardReq *http.Request, contextExtensions map[string]string) *checkRequest {
+	checkReq := &checkRequest{
+		sourceAddress:     req.RemoteAddr,
+		method:            forwardReq.Header.Get(xForwardedMethod),
+		path:              forwardReq.Header.Get(xForwardedURI),
+		host:              forwardReq.Header.Get(forward.XForwardedHost),
+		scheme:            forwardReq.Header.Get(forward.XForwardedProto),
+		query:             req.URL.RawQuery,
+		fragment:          req.URL.Fragment,
+		protocol:          req.Proto,
+		headers:           make(map[string]string),
+		contextExtensions: contextExtensions,
+		time:              time.Now(),
+	}
+
+	if addr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		checkReq.destinationAddress = addr.String()
+	}
+
+	// As for Envoy, the header names are lowercased, the values of a header are comma-separated,
+	// and the pseudo-headers are included.
+	for name, values := range forwardReq.Header {
+		checkReq.headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	checkReq.headers[":method"] = checkReq.method
+	checkReq.headers[":path"] = checkReq.path
+	checkReq.headers[":authority"] = checkReq.host
+	checkReq.headers[":scheme"] = checkReq.scheme
+
+	return checkReq
+}
+
+func (r *checkRequest) marshal() []byte {
+	var httpReq []byte
+	httpReq = appendString(httpReq, 2, r.method)
+	httpReq = appendStringMap(httpReq, 3, r.headers)
+	httpReq = appendString(httpReq, 4, r.path)
+	httpReq = appendString(httpReq, 5, r.host)
+	httpReq = appendString(httpReq, 6, r.scheme)
+	httpReq = appendString(httpReq, 7, r.query)
+	httpReq = appendString(httpReq, 8, r.fragment)
+	httpReq = appendString(httpReq, 10, r.protocol)
+
+	var timestamp []byte
+	timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, uint64(r.time.Unix()))
+	timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, uint64(r.time.Nanosecond()))
+
+	var request []byte
+	request = appendMessage(request, 1, timestamp)
+	request = appendMessage(request, 2, httpReq)
+
+	var attributes []byte
+	attributes = appendPeer(attributes, 1, r.sourceAddress)
+	attributes = appendPeer(attributes, 2, r.destinationAddress)
+	attributes = appendMessage(attributes, 4, request)
+	attributes = appendStringMap(attributes, 10, r.contextExtensions)
+
+	return appendMessage(nil, 1, attributes)
+}
+
+// appendPeer appends an envoy.service.auth.v3.AttributeContext.Peer holding the given socket address.
+func appendPeer(b []byte, num protowire.Number, address string) []byte {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return b
+	}
+
+	var socketAddress []byte
+	socketAddress = appendString(socketAddress, 2, host)
+	if portValue, err := strconv.ParseUint(port, 10, 32); err == nil {
+		socketAddress = protowire.AppendTag(socketAddress, 3, protowire.VarintType)
+		socketAddress = protowire.AppendVarint(socketAddress, portValue)
+	}
+
+	return appendMessage(b, num, appendMessage(nil, 1, appendMessage(nil, 1, socketAddress)))
+}
+
+// checkResponse is an envoy.service.auth.v3.CheckResponse.
+type checkResponse struct {
+	code   int32
+	denied *deniedHTTPResponse
+	ok     *okHTTPResponse
+}
+
+type deniedHTTPResponse struct {
+	status  uint64
+	headers []headerValueOption
+	body    string
+}
+
+type okHTTPResponse struct {
+	headers              []headerValueOption
+	headersToRemove      []string
+	responseHeadersToAdd []headerValueOption
+}
+
+func (r *checkResponse) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, value []byte, _ uint64) error {
+		switch num {
+		case 1:
+			return consumeFields(value, func(num protowire.Number, _ []byte, v uint64) error {
+				if num == 1 {
+					r.code = int32(v)
+				}
+				return nil
+			})
+		case 2:
+			r.denied = &deniedHTTPResponse{}
+			return r.denied.unmarshal(value)
+		case 3:
+			r.ok = &okHTTPResponse{}
+			return r.ok.unmarshal(value)
+		}
+		return nil
+	})
+}
+
+func (r *deniedHTTPResponse) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, value []byte, _ uint64) error {
+		switch num {
+		case 1:
+			return consumeFields(value, func(num protowire.Number, _ []byte, v uint64) error {
+				if num == 1 {
+					r.status = v
+				}
+				return nil
+			})
+		case 2:
+			h, err := unmarshalHeaderValueOption(value)
+			if err != nil {
+				return err
+			}
+			r.headers = append(r.headers, h)
+		case 3:
+			r.body = string(value)
+		}
+		return nil
+	})
+}
+
+func (r *okHTTPResponse) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, value []byte, _ uint64) error {
+		switch num {
+		case 2:
+			h, err := unmarshalHeaderValueOption(value)
+			if err != nil {
+				return err
+			}
+			r.headers = append(r.headers, h)
+		case 5:
+			r.headersToRemove = append(r.headersToRemove, string(value))
+		case 6:
+			h, err := unmarshalHeaderValueOption(value)
+			if err != nil {
+				return err
+			}
+			r.responseHeadersToAdd = append(r.responseHeadersToAdd, h)
+		}
+		return nil
+	})
+}
+
+func unmarshalHeaderValueOption(b []byte) (headerValueOption, error) {
+	var h headerValueOption
+	err := consumeFields(b, func(num protowire.Number, value []byte, v uint64) error {
+		switch num {
+		case 1:
+			return consumeFields(value, func(num protowire.Number, value []byte, _ uint64) error {
+				switch num {
+				case 1:
+					h.key = string(value)
+				case 2:
+					h.value = string(value)
+				case 3:
+					// raw_value takes precedence over value.
+					h.value = string(value)
+				}
+				return nil
+			})
+		case 2:
+			var appendValue bool
+			h.append = &appendValue
+			return consumeFields(value, func(num protowire.Number, _ []byte, v uint64) error {
+				if num == 1 {
+					appendValue = v != 0
+				}
+				return nil
+			})
+		case 3:
+			h.appendAction = v
+		}
+		return nil
+	})
+	if err != nil {
+		return h, err
+	}
+
+	if h.key == "" {
+		return h, errors.New("missing header key")
+	}
+
+	return h, nil
+}
+
+// extAuthzCodec encodes the ext_authz messages on the wire.
+type extAuthzCodec struct{}
+
+func (extAuthzCodec) Marshal(v interface{}) ([]byte, error) {
+	req, ok := v.(*checkRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return req.marshal(), nil
+}
+
+func (extAuthzCodec) Unmarshal(data []byte, v interface{}) error {
+	resp, ok := v.(*checkResponse)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	return resp.unmarshal(data)
+}
+
+func (extAuthzCodec) Name() string {
+	return "proto"
+}
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendStringMap(b []byte, num protowire.Number, m map[string]string) []byte {
+	for key, value := range m {
+		var entry []byte
+		entry = appendString(entry, 1, key)
+		entry = appendString(entry, 2, value)
+		b = appendMessage(b, num, entry)
+	}
+
+	return b
+}
+
+// consumeFields calls fn for each field of the given message,
+// with the value of the length-delimited fields, or the value of the varint fields.
+// The other fields are skipped.
+func consumeFields(b []byte, fn func(num protowire.Number, value []byte, v uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var err error
+		switch typ {
+		case protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			if n >= 0 {
+				err = fn(num, nil, v)
+			}
+		case protowire.BytesType:
+			var value []byte
+			value, n = protowire.ConsumeBytes(b)
+			if n >= 0 {
+				err = fn(num, value, 0)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+// rawCodec passes the messages through, to let the tests encode and decode them.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	return *v.(*[]byte), nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// httpRequestAttributes is the subset of the HTTP request attributes of a CheckRequest checked by the tests.
+type httpRequestAttributes struct {
+	method            string
+	path              string
+	host              string
+	headers           map[string]string
+	contextExtensions map[string]string
+}
+
+func parseCheckRequest(t *testing.T, b []byte) httpRequestAttributes {
+	t.Helper()
+
+	attrs := httpRequestAttributes{
+		headers:           make(map[string]string),
+		contextExtensions: make(map[string]string),
+	}
+
+	err := consumeFields(b, func(num protowire.Number, attributes []byte, _ uint64) error {
+		return consumeFields(attributes, func(num protowire.Number, value []byte, _ uint64) error {
+			switch num {
+			case 4:
+				return consumeFields(value, func(num protowire.Number, httpReq []byte, _ uint64) error {
+					if num != 2 {
+						return nil
+					}
+
+					return consumeFields(httpReq, func(num protowire.Number, value []byte, _ uint64) error {
+						switch num {
+						case 2:
+							attrs.method = string(value)
+						case 3:
+							parseMapEntry(t, value, attrs.headers)
+						case 4:
+							attrs.path = string(value)
+						case 5:
+							attrs.host = string(value)
+						}
+						return nil
+					})
+				})
+			case 10:
+				parseMapEntry(t, value, attrs.contextExtensions)
+			}
+			return nil
+		})
+	})
+	require.NoError(t, err)
+
+	return attrs
+}
+
+func parseMapEntry(t *testing.T, b []byte, m map[string]string) {
+	t.Helper()
+
+	var key, value string
+	err := consumeFields(b, func(num protowire.Number, v []byte, _ uint64) error {
+		if num == 1 {
+			key = string(v)
+		} else {
+			value = string(v)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	m[key] = value
+}
+
+func headerValue(key, value string, appendValue bool) []byte {
+	header := appendString(appendString(nil, 1, key), 2, value)
+	option := appendMessage(nil, 1, header)
+
+	if appendValue {
+		option = appendMessage(option, 2, protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1))
+	}
+
+	return option
+}
+
+func okCheckResponse(headers [][]byte, headersToRemove []string, responseHeaders [][]byte) []byte {
+	var ok []byte
+	for _, h := range headers {
+		ok = appendMessage(ok, 2, h)
+	}
+	for _, name := range headersToRemove {
+		ok = appendString(ok, 5, name)
+	}
+	for _, h := range responseHeaders {
+		ok = appendMessage(ok, 6, h)
+	}
+
+	return appendMessage(appendMessage(nil, 1, nil), 3, ok)
+}
+
+func deniedCheckResponse(statusCode uint64, headers [][]byte, body string) []byte {
+	code := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), uint64(codes.PermissionDenied))
+
+	var denied []byte
+	if statusCode != 0 {
+		denied = appendMessage(denied, 1, protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), statusCode))
+	}
+	for _, h := range headers {
+		denied = appendMessage(denied, 2, h)
+	}
+	denied = appendString(denied, 3, body)
+
+	return appendMessage(appendMessage(nil, 1, code), 2, denied)
+}
+
+func startExtAuthzServer(t *testing.T, check func(req []byte) ([]byte, error)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			if method != extAuthzCheckMethod {
+				return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+			}
+
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+
+			resp, err := check(req)
+			if err != nil {
+				return err
+			}
+
+			return stream.SendMsg(&resp)
+		}),
+	)
+	t.Cleanup(server.Stop)
+
+	go func() { _ = server.Serve(listener) }()
+
+	return listener.Addr().String()
+}
+
+func TestForwardAuthExtAuthz(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		response   []byte
+		err        error
+		expCode    int
+		expBody    string
+		expHeaders http.Header
+		expForward http.Header
+		expRemoved []string
+	}{
+		{
+			desc: "allowed with header mutations",
+			response: okCheckResponse(
+				[][]byte{headerValue("X-User", "alice", false), headerValue("X-Group", "admin", true)},
+				[]string{"X-Remove"},
+				[][]byte{headerValue("X-Response", "foo", false)},
+			),
+			expCode:    http.StatusOK,
+			expHeaders: http.Header{"X-Response": {"foo"}},
+			expForward: http.Header{"X-User": {"alice"}, "X-Group": {"client", "admin"}},
+			expRemoved: []string{"X-Remove"},
+		},
+		{
+			desc: "denied",
+			response: deniedCheckResponse(http.StatusUnauthorized,
+				[][]byte{headerValue("WWW-Authenticate", `Bearer realm="traefik"`, false)},
+				"denied",
+			),
+			expCode:    http.StatusUnauthorized,
+			expBody:    "denied",
+			expHeaders: http.Header{"Www-Authenticate": {`Bearer realm="traefik"`}},
+		},
+		{
+			desc:     "denied without status",
+			response: deniedCheckResponse(0, nil, ""),
+			expCode:  http.StatusForbidden,
+		},
+		{
+			desc:    "server error",
+			err:     status.Error(codes.Unavailable, "unavailable"),
+			expCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var attrs httpRequestAttributes
+			address := startExtAuthzServer(t, func(req []byte) ([]byte, error) {
+				attrs = parseCheckRequest(t, req)
+				return test.response, test.err
+			})
+
+			var forwarded http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req.Header
+			})
+
+			auth := dynamic.ForwardAuth{
+				Address: address,
+				ExtAuthz: &dynamic.ForwardAuthExtAuthz{
+					ContextExtensions: map[string]string{"route": "test"},
+				},
+			}
+			middleware, err := NewForward(context.Background(), next, auth, "authTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/foo?bar=baz", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("X-User", "forged")
+			req.Header.Set("X-Group", "client")
+			req.Header.Set("X-Remove", "foo")
+
+			recorder := httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expCode, recorder.Code)
+			assert.Equal(t, test.expBody, recorder.Body.String())
+			for name, values := range test.expHeaders {
+				assert.Equal(t, values, recorder.Header()[name], name)
+			}
+
+			assert.Equal(t, http.MethodPost, attrs.method)
+			assert.Equal(t, "/foo?bar=baz", attrs.path)
+			assert.Equal(t, "example.com", attrs.host)
+			assert.Equal(t, "Bearer token", attrs.headers["authorization"])
+			assert.Equal(t, "example.com", attrs.headers[":authority"])
+			assert.Equal(t, map[string]string{"route": "test"}, attrs.contextExtensions)
+
+			if test.expCode != http.StatusOK {
+				assert.Nil(t, forwarded)
+				return
+			}
+
+			for name, values := range test.expForward {
+				assert.Equal(t, values, forwarded[name], name)
+			}
+			for _, name := range test.expRemoved {
+				assert.Empty(t, forwarded.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestForwardAuthExtAuthz_timeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	address := startExtAuthzServer(t, func(req []byte) ([]byte, error) {
+		<-release
+		return okCheckResponse(nil, nil, nil), nil
+	})
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	auth := dynamic.ForwardAuth{
+		Address: address,
+		ExtAuthz: &dynamic.ForwardAuthExtAuthz{
+			Timeout: ptypes.Duration(100 * time.Millisecond),
+		},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	middleware.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestForwardAuthExtAuthz_sharedConnection(t *testing.T) {
+	address := startExtAuthzServer(t, func(req []byte) ([]byte, error) {
+		return okCheckResponse(nil, nil, nil), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	config := &dynamic.ForwardAuthExtAuthz{}
+
+	first, err := newExtAuthzClient(ctx, address, nil, "", config)
+	require.NoError(t, err)
+
+	second, err := newExtAuthzClient(ctx, address, nil, "", config)
+	require.NoError(t, err)
+	assert.Same(t, first.conn, second.conn)
+	assert.Equal(t, extAuthzDefaultTimeout, second.timeout)
+
+	other, err := newExtAuthzClient(ctx, address, nil, `{"insecureSkipVerify":true}`, config)
+	require.NoError(t, err)
+	assert.NotSame(t, first.conn, other.conn)
+}
@@ -6,12 +6,15 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/vulcand/oxy/v2/forward"
 	"traefik/v3/pkg/config/dynamic"
 	tracingMiddleware "traefik/v3/pkg/middlewares/tracing"
@@ -245,6 +248,286 @@ func TestForwardAuthFailResponseHeaders(t *testing.T) {
 	assert.Equal(t, "Forbidden\n", string(body))
 }
 
+func TestForwardAuthCache(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		switch r.Header.Get("Authorization") {
+		case "Bearer error":
+			http.Error(w, "Error", http.StatusServiceUnavailable)
+		case "Bearer no-store":
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("X-Auth-User", "no-store")
+		case "Bearer denied":
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			w.Header().Set("X-Auth-User", r.Header.Get("Authorization"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("X-Auth-User"))
+	})
+
+	auth := dynamic.ForwardAuth{
+		Address:             server.URL,
+		AuthResponseHeaders: []string{"X-Auth-User"},
+		Cache: &dynamic.ForwardAuthCache{
+			TTL:        ptypes.Duration(time.Minute),
+			MaxEntries: 2,
+			KeyHeaders: []string{"Authorization"},
+		},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		authorization string
+		expCode       int
+		expBody       string
+		expCalls      int
+	}{
+		{authorization: "Bearer foo", expCode: http.StatusOK, expBody: "Bearer foo", expCalls: 1},
+		{authorization: "Bearer foo", expCode: http.StatusOK, expBody: "Bearer foo", expCalls: 1},
+		{authorization: "Bearer bar", expCode: http.StatusOK, expBody: "Bearer bar", expCalls: 2},
+		{authorization: "Bearer denied", expCode: http.StatusForbidden, expBody: "Forbidden\n", expCalls: 3},
+		{authorization: "Bearer denied", expCode: http.StatusForbidden, expBody: "Forbidden\n", expCalls: 3},
+		// The least recently used entry is evicted.
+		{authorization: "Bearer foo", expCode: http.StatusOK, expBody: "Bearer foo", expCalls: 4},
+		{authorization: "Bearer error", expCode: http.StatusServiceUnavailable, expBody: "Error\n", expCalls: 5},
+		{authorization: "Bearer error", expCode: http.StatusServiceUnavailable, expBody: "Error\n", expCalls: 6},
+		{authorization: "Bearer no-store", expCode: http.StatusOK, expBody: "no-store", expCalls: 7},
+		{authorization: "Bearer no-store", expCode: http.StatusOK, expBody: "no-store", expCalls: 8},
+	}
+
+	for _, test := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("Authorization", test.authorization)
+		req.Header.Set("X-Auth-User", "forged")
+
+		recorder := httptest.NewRecorder()
+		middleware.ServeHTTP(recorder, req)
+
+		assert.Equal(t, test.expCode, recorder.Code, test.authorization)
+		assert.Equal(t, test.expBody, recorder.Body.String(), test.authorization)
+		assert.Equal(t, test.expCalls, calls, test.authorization)
+	}
+}
+
+func TestForwardAuthCache_expiration(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	t.Cleanup(server.Close)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	auth := dynamic.ForwardAuth{
+		Address: server.URL,
+		Cache: &dynamic.ForwardAuthCache{
+			TTL:        ptypes.Duration(50 * time.Millisecond),
+			MaxEntries: 10,
+			KeyCookies: []string{"session"},
+		},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	serve := func() {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "foo"})
+
+		recorder := httptest.NewRecorder()
+		middleware.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	serve()
+	serve()
+	assert.Equal(t, 1, calls)
+
+	time.Sleep(100 * time.Millisecond)
+
+	serve()
+	assert.Equal(t, 2, calls)
+}
+
+func TestForwardAuthCache_anonymousClients(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		// Each anonymous client is sent to the login page, with its own login state.
+		http.SetCookie(w, &http.Cookie{Name: "login_state", Value: strconv.Itoa(calls)})
+		http.Redirect(w, r, "https://auth.example.com/login", http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	auth := dynamic.ForwardAuth{
+		Address: server.URL,
+		Cache: &dynamic.ForwardAuthCache{
+			TTL:        ptypes.Duration(time.Minute),
+			MaxEntries: 10,
+			KeyHeaders: []string{"Authorization"},
+			KeyCookies: []string{"session"},
+		},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		recorder := httptest.NewRecorder()
+		middleware.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+		assert.Equal(t, http.StatusFound, recorder.Code)
+		assert.Equal(t, "login_state="+strconv.Itoa(i), recorder.Header().Get("Set-Cookie"))
+		assert.Equal(t, i, calls)
+	}
+
+	// The responses setting cookies are not cached either for the identified clients.
+	for i := 3; i <= 4; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "expired"})
+
+		recorder := httptest.NewRecorder()
+		middleware.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusFound, recorder.Code)
+		assert.Equal(t, "login_state="+strconv.Itoa(i), recorder.Header().Get("Set-Cookie"))
+		assert.Equal(t, i, calls)
+	}
+}
+
+func TestForwardAuthCache_requestedResource(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		// Only the GET requests to the public path of the first host are allowed.
+		if r.Header.Get("X-Forwarded-Host") != "a.localhost" || r.Header.Get("X-Forwarded-Method") != http.MethodGet ||
+			r.Header.Get("X-Forwarded-Uri") != "/public" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		desc     string
+		cache    dynamic.ForwardAuthCache
+		method   string
+		target   string
+		expCode  int
+		expCalls int
+	}{
+		{
+			desc:     "allowed request",
+			method:   http.MethodGet,
+			target:   "http://a.localhost/public",
+			expCode:  http.StatusOK,
+			expCalls: 1,
+		},
+		{
+			desc:     "other host",
+			method:   http.MethodGet,
+			target:   "http://b.localhost/public",
+			expCode:  http.StatusForbidden,
+			expCalls: 2,
+		},
+		{
+			desc:     "other method",
+			method:   http.MethodDelete,
+			target:   "http://a.localhost/public",
+			expCode:  http.StatusForbidden,
+			expCalls: 2,
+		},
+		{
+			desc:     "other path",
+			method:   http.MethodGet,
+			target:   "http://a.localhost/admin",
+			expCode:  http.StatusForbidden,
+			expCalls: 2,
+		},
+		{
+			desc:     "other path with the URI ignored",
+			cache:    dynamic.ForwardAuthCache{IgnoreURI: true},
+			method:   http.MethodGet,
+			target:   "http://a.localhost/public/other",
+			expCode:  http.StatusOK,
+			expCalls: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			calls = 0
+
+			cache := test.cache
+			cache.TTL = ptypes.Duration(time.Minute)
+			cache.MaxEntries = 10
+			cache.KeyHeaders = []string{"Authorization"}
+
+			middleware, err := NewForward(context.Background(), next, dynamic.ForwardAuth{Address: server.URL, Cache: &cache}, "authTest")
+			require.NoError(t, err)
+
+			// A first request, allowed, fills the cache.
+			req := httptest.NewRequest(http.MethodGet, "http://a.localhost/public", nil)
+			req.Header.Set("Authorization", "Bearer foo")
+
+			recorder := httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, req)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			req = httptest.NewRequest(test.method, test.target, nil)
+			req.Header.Set("Authorization", "Bearer foo")
+
+			recorder = httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expCode, recorder.Code)
+			assert.Equal(t, test.expCalls, calls)
+		})
+	}
+}
+
+func TestNewForward_invalidCache(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		desc  string
+		cache dynamic.ForwardAuthCache
+	}{
+		{
+			desc:  "no key",
+			cache: dynamic.ForwardAuthCache{TTL: ptypes.Duration(time.Minute), MaxEntries: 10},
+		},
+		{
+			desc:  "no TTL",
+			cache: dynamic.ForwardAuthCache{MaxEntries: 10, KeyHeaders: []string{"Authorization"}},
+		},
+		{
+			desc:  "no max entries",
+			cache: dynamic.ForwardAuthCache{TTL: ptypes.Duration(time.Minute), KeyHeaders: []string{"Authorization"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewForward(context.Background(), next, dynamic.ForwardAuth{Address: "http://localhost", Cache: &test.cache}, "authTest")
+			assert.Error(t, err)
+		})
+	}
+}
+
 func Test_writeHeader(t *testing.T) {
 	testCases := []struct {
 		name                      string
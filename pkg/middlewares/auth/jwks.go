@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// jwksMinRefreshInterval is the minimum duration between two fetches of a JSON Web Key Set triggered by unknown keys,
+// so that tokens with random key IDs cannot make Traefik flood the JWKS endpoint.
+const jwksMinRefreshInterval = 10 * time.Second
+
+// jwksFetchTimeout is the maximum duration of a fetch of a JSON Web Key Set.
+const jwksFetchTimeout = 10 * time.Second
+
+var (
+	jwksCachesMu sync.Mutex
+	// jwksCaches holds the JSON Web Key Set caches, keyed by URL,
+	// so that the keys are kept when the middlewares are rebuilt on configuration reload.
+	jwksCaches = make(map[string]*jwksCache)
+)
+
+// jwksCache caches the keys of a JSON Web Key Set, and fetches them again when they are stale,
+// or when a token is signed with an unknown key, which happens when the keys are rotated.
+type jwksCache struct {
+	url string
+
+	// fetches ensures that only one fetch of the JSON Web Key Set is in flight at a time.
+	fetches singleflight.Group
+
+	mu              sync.Mutex
+	client          *http.Client
+	keys            *jose.JSONWebKeySet
+	fetchedAt       time.Time
+	refreshInterval time.Duration
+	minRefresh      time.Duration
+}
+
+// getJWKSCache returns the JSON Web Key Set cache of the given URL, creating it if needed.
+// If client is nil, a default client is used to fetch the JSON Web Key Set.
+func getJWKSCache(url string, client *http.Client, refreshInterval time.Duration) *jwksCache {
+	jwksCachesMu.Lock()
+	defer jwksCachesMu.Unlock()
+
+	if client == nil {
+		client = &http.Client{Timeout: jwksFetchTimeout}
+	}
+
+	cache, ok := jwksCaches[url]
+	if !ok {
+		cache = &jwksCache{
+			url:        url,
+			minRefresh: jwksMinRefreshInterval,
+		}
+		jwksCaches[url] = cache
+	}
+
+	cache.mu.Lock()
+	cache.client = client
+	cache.refreshInterval = refreshInterval
+	cache.mu.Unlock()
+
+	return cache
+}
+
+// Keys returns the keys matching the given key ID, or all the keys if the key ID is empty.
+// The cached keys are served while the JSON Web Key Set is fetched again, unless they do not match the key ID.
+func (c *jwksCache) Keys(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	c.mu.Lock()
+	keys := c.keys
+	needsFetch := c.needsFetch(kid)
+	c.mu.Unlock()
+
+	if needsFetch {
+		// The fetch is not bound to the request, so that it is not canceled along with it,
+		// and shared by the concurrent requests.
+		fetched := c.fetches.DoChan(c.url, func() (interface{}, error) {
+			return nil, c.fetch(ctx)
+		})
+
+		if keys == nil || kid != "" && len(keys.Key(kid)) == 0 {
+			select {
+			case <-fetched:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+
+			c.mu.Lock()
+			keys = c.keys
+			c.mu.Unlock()
+		}
+	}
+
+	if keys == nil {
+		return nil, fmt.Errorf("JSON Web Key Set %s not available", c.url)
+	}
+
+	if kid == "" {
+		return keys.Keys, nil
+	}
+
+	return keys.Key(kid), nil
+}
+
+// needsFetch returns whether the JSON Web Key Set must be fetched. It must be called with the mu lock held.
+func (c *jwksCache) needsFetch(kid string) bool {
+	if c.fetchedAt.IsZero() {
+		return true
+	}
+
+	sinceFetch := time.Since(c.fetchedAt)
+	if sinceFetch <= c.minRefresh {
+		return false
+	}
+
+	if c.keys == nil || sinceFetch > c.refreshInterval {
+		return true
+	}
+
+	// The keys may have been rotated.
+	return kid != "" && len(c.keys.Key(kid)) == 0
+}
+
+// fetch fetches the JSON Web Key Set, and logs the failures with the logger of the given context.
+// The previous keys, if any, are kept until the JSON Web Key Set can be fetched again.
+func (c *jwksCache) fetch(ctx context.Context) error {
+	c.mu.Lock()
+	client := c.client
+	c.mu.Unlock()
+
+	fetchCtx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+
+	keys, err := fetchJWKS(fetchCtx, client, c.url)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Failed fetches are not retried before the minimum refresh interval.
+	c.fetchedAt = time.Now()
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("url", c.url).Msg("Could not fetch JSON Web Key Set")
+		return err
+	}
+
+	c.keys = keys
+
+	return nil
+}
+
+// fetchJWKS fetches the JSON Web Key Set at the given URL.
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (*jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating JSON Web Key Set request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JSON Web Key Set: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JSON Web Key Set: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading JSON Web Key Set: %w", err)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return nil, fmt.Errorf("decoding JSON Web Key Set: %w", err)
+	}
+
+	return &keys, nil
+}
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/opentracing/opentracing-go/ext"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/tracing"
+)
+
+const (
+	jwtTypeName = "JWT"
+)
+
+var (
+	hmacAlgorithms = map[string]struct{}{
+		string(jose.HS256): {},
+		string(jose.HS384): {},
+		string(jose.HS512): {},
+	}
+	publicKeyAlgorithms = map[string]struct{}{
+		string(jose.RS256): {},
+		string(jose.RS384): {},
+		string(jose.RS512): {},
+		string(jose.PS256): {},
+		string(jose.PS384): {},
+		string(jose.PS512): {},
+		string(jose.ES256): {},
+		string(jose.ES384): {},
+		string(jose.ES512): {},
+		string(jose.EdDSA): {},
+	}
+)
+
+type jwtAuth struct {
+	next          http.Handler
+	name          string
+	secret        []byte
+	publicKeys    []interface{}
+	jwks          *jwksCache
+	issuer        string
+	audience      []string
+	clockSkew     time.Duration
+	forwardClaims map[string]string
+	removeHeader  bool
+}
+
+// NewJWT creates a JWT middleware.
+func NewJWT(ctx context.Context, next http.Handler, config dynamic.JWT, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, jwtTypeName).Debug().Msg("Creating middleware")
+
+	if config.Secret == "" && len(config.PublicKeys) == 0 && config.JWKSURL == "" {
+		return nil, errors.New("one of secret, publicKeys or jwksURL must be defined")
+	}
+
+	ja := &jwtAuth{
+		next:          next,
+		name:          name,
+		issuer:        config.Issuer,
+		audience:      config.Audience,
+		clockSkew:     time.Duration(config.ClockSkew),
+		forwardClaims: config.ForwardClaims,
+		removeHeader:  config.RemoveHeader,
+	}
+
+	if config.Secret != "" {
+		ja.secret = []byte(config.Secret)
+	}
+
+	for _, publicKey := range config.PublicKeys {
+		key, err := parsePublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		ja.publicKeys = append(ja.publicKeys, key)
+	}
+
+	if config.JWKSURL != "" {
+		refreshInterval := time.Duration(config.JWKSRefreshInterval)
+		if refreshInterval <= 0 {
+			refreshInterval = 15 * time.Minute
+		}
+
+		ja.jwks = getJWKSCache(config.JWKSURL, nil, refreshInterval)
+	}
+
+	return ja, nil
+}
+
+func (j *jwtAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return j.name, tracing.SpanKindNoneEnum
+}
+
+func (j *jwtAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), j.name, jwtTypeName)
+	ctx := logger.WithContext(req.Context())
+
+	claims, err := j.validate(ctx, req)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Authentication failed")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	logger.Debug().Msg("Authentication succeeded")
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		if sub, ok := claims["sub"].(string); ok {
+			logData.Core[accesslog.ClientUsername] = sub
+		}
+	}
+
+	for header, claim := range j.forwardClaims {
+		// The header is removed, so that it cannot be forged by the client.
+		req.Header.Del(header)
+
+		if value, ok := claimValue(claims, claim); ok {
+			req.Header.Set(header, value)
+		}
+	}
+
+	if j.removeHeader {
+		logger.Debug().Msg("Removing authorization header")
+		req.Header.Del(authorizationHeader)
+	}
+
+	j.next.ServeHTTP(rw, req)
+}
+
+// validate validates the bearer token of the request, and returns its claims.
+func (j *jwtAuth) validate(ctx context.Context, req *http.Request) (map[string]interface{}, error) {
+	authorization := req.Header.Get(authorizationHeader)
+
+	scheme, rawToken, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, errors.New("missing bearer token")
+	}
+
+	return j.validateToken(ctx, strings.TrimSpace(rawToken))
+}
+
+// validateToken validates the given token, and returns its claims.
+func (j *jwtAuth) validateToken(ctx context.Context, rawToken string) (map[string]interface{}, error) {
+	token, err := jwt.ParseSigned(rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("parsing token: %w", err)
+	}
+
+	if len(token.Headers) != 1 {
+		return nil, errors.New("unexpected number of signatures")
+	}
+
+	keys, err := j.keys(ctx, token.Headers[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var standardClaims jwt.Claims
+	var claims map[string]interface{}
+	for _, key := range keys {
+		if err = token.Claims(key, &standardClaims, &claims); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("verifying token signature: %w", err)
+	}
+
+	// The tokens without expiration would be valid forever.
+	if standardClaims.Expiry == nil {
+		return nil, errors.New("missing exp claim")
+	}
+
+	err = standardClaims.ValidateWithLeeway(jwt.Expected{Issuer: j.issuer, Time: time.Now()}, j.clockSkew)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(j.audience) > 0 && !matchAudience(standardClaims.Audience, j.audience) {
+		return nil, jwt.ErrInvalidAudience
+	}
+
+	return claims, nil
+}
+
+// keys returns the keys which can have been used to sign a token with the given header.
+func (j *jwtAuth) keys(ctx context.Context, header jose.Header) ([]interface{}, error) {
+	if _, ok := hmacAlgorithms[header.Algorithm]; ok {
+		if j.secret == nil {
+			return nil, fmt.Errorf("no secret to verify %s signature", header.Algorithm)
+		}
+
+		return []interface{}{j.secret}, nil
+	}
+
+	if _, ok := publicKeyAlgorithms[header.Algorithm]; !ok {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", header.Algorithm)
+	}
+
+	keys := make([]interface{}, 0, len(j.publicKeys))
+	keys = append(keys, j.publicKeys...)
+
+	if j.jwks != nil {
+		jwks, err := j.jwks.Keys(ctx, header.KeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range jwks {
+			if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+				continue
+			}
+
+			if key.Use != "" && key.Use != "sig" {
+				continue
+			}
+
+			// Symmetric keys have no public part.
+			if public := key.Public(); public.Key != nil {
+				keys = append(keys, public.Key)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key to verify %s signature", header.Algorithm)
+	}
+
+	return keys, nil
+}
+
+// matchAudience returns whether the token audience contains one of the accepted audiences.
+func matchAudience(tokenAudience jwt.Audience, accepted []string) bool {
+	for _, audience := range accepted {
+		if tokenAudience.Contains(audience) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// claimValue returns the value of the given claim as a header value.
+// The values which are not strings are JSON-encoded.
+func claimValue(claims map[string]interface{}, name string) (string, bool) {
+	value, ok := claims[name]
+	if !ok || value == nil {
+		return "", false
+	}
+
+	if str, ok := value.(string); ok {
+		return str, true
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+
+	return string(data), true
+}
+
+// parsePublicKey parses a PEM-encoded public key or certificate.
+func parsePublicKey(data string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("decoding public key: no PEM data found")
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate: %w", err)
+		}
+
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+
+		return key, nil
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+
+		return key, nil
+	}
+}
@@ -0,0 +1,388 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	edPublicKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.JWT
+		authorization string
+		header        http.Header
+		expCode       int
+		expHeaders    map[string]string
+	}{
+		{
+			desc:          "missing token",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "",
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "not a bearer token",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "Basic dGVzdDp0ZXN0",
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "malformed token",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "Bearer foo",
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "HS256 token",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusOK,
+		},
+		{
+			desc:          "HS256 token with wrong secret",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("other"), jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "RS256 token",
+			config:        dynamic.JWT{PublicKeys: []string{encodePublicKey(t, &rsaKey.PublicKey)}},
+			authorization: "Bearer " + signToken(t, jose.RS256, rsaKey, jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusOK,
+		},
+		{
+			desc:          "ES256 token",
+			config:        dynamic.JWT{PublicKeys: []string{encodePublicKey(t, &ecKey.PublicKey)}},
+			authorization: "Bearer " + signToken(t, jose.ES256, ecKey, jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusOK,
+		},
+		{
+			desc:          "EdDSA token",
+			config:        dynamic.JWT{PublicKeys: []string{encodePublicKey(t, edPublicKey)}},
+			authorization: "Bearer " + signToken(t, jose.EdDSA, edKey, jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusOK,
+		},
+		{
+			desc:          "RS256 token signed with another key",
+			config:        dynamic.JWT{PublicKeys: []string{encodePublicKey(t, &ecKey.PublicKey)}},
+			authorization: "Bearer " + signToken(t, jose.RS256, rsaKey, jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "HS256 token with public keys only",
+			config:        dynamic.JWT{PublicKeys: []string{encodePublicKey(t, &rsaKey.PublicKey)}},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "expired token",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Expiry: jwt.NewNumericDate(now.Add(-time.Minute))}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "expired token within the clock skew",
+			config:        dynamic.JWT{Secret: "secret", ClockSkew: ptypes.Duration(2 * time.Minute)},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Expiry: jwt.NewNumericDate(now.Add(-time.Minute))}, nil),
+			expCode:       http.StatusOK,
+		},
+		{
+			desc:          "token without expiration",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "Bearer " + signClaims(t, jose.HS256, []byte("secret"), jwt.Claims{Subject: "foo"}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "token not valid yet",
+			config:        dynamic.JWT{Secret: "secret"},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{NotBefore: jwt.NewNumericDate(now.Add(time.Minute))}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "expected issuer",
+			config:        dynamic.JWT{Secret: "secret", Issuer: "https://issuer.example.com"},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Issuer: "https://issuer.example.com"}, nil),
+			expCode:       http.StatusOK,
+		},
+		{
+			desc:          "unexpected issuer",
+			config:        dynamic.JWT{Secret: "secret", Issuer: "https://issuer.example.com"},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Issuer: "https://other.example.com"}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc:          "accepted audience",
+			config:        dynamic.JWT{Secret: "secret", Audience: []string{"foo", "bar"}},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Audience: jwt.Audience{"bar", "baz"}}, nil),
+			expCode:       http.StatusOK,
+		},
+		{
+			desc:          "unaccepted audience",
+			config:        dynamic.JWT{Secret: "secret", Audience: []string{"foo", "bar"}},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Audience: jwt.Audience{"baz"}}, nil),
+			expCode:       http.StatusUnauthorized,
+		},
+		{
+			desc: "forwarded claims",
+			config: dynamic.JWT{
+				Secret: "secret",
+				ForwardClaims: map[string]string{
+					"X-User":   "sub",
+					"X-Groups": "groups",
+					"X-Plan":   "plan",
+				},
+				RemoveHeader: true,
+			},
+			authorization: "Bearer " + signToken(t, jose.HS256, []byte("secret"), jwt.Claims{Subject: "foo"}, map[string]interface{}{"groups": []string{"admin", "dev"}}),
+			header:        http.Header{"X-Plan": []string{"gold"}},
+			expCode:       http.StatusOK,
+			expHeaders: map[string]string{
+				"X-User":        "foo",
+				"X-Groups":      `["admin","dev"]`,
+				"X-Plan":        "",
+				"Authorization": "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwarded http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req.Header.Clone()
+			})
+
+			handler, err := NewJWT(context.Background(), next, test.config, "jwt-test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			for name, values := range test.header {
+				req.Header[name] = values
+			}
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expCode, recorder.Code)
+
+			if test.expCode == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer error="invalid_token"`, recorder.Header().Get("WWW-Authenticate"))
+				return
+			}
+
+			for name, value := range test.expHeaders {
+				assert.Equal(t, value, forwarded.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestJWT_JWKS(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	jwks := jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{{Key: &key1.PublicKey, KeyID: "key1", Algorithm: string(jose.RS256), Use: "sig"}},
+	}
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		_ = json.NewEncoder(rw).Encode(jwks)
+	}))
+	t.Cleanup(server.Close)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := NewJWT(context.Background(), next, dynamic.JWT{JWKSURL: server.URL}, "jwt-test")
+	require.NoError(t, err)
+
+	cache := getJWKSCache(server.URL, nil, time.Hour)
+	cache.mu.Lock()
+	cache.minRefresh = 0
+	cache.mu.Unlock()
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	token1 := signTokenWithKeyID(t, jose.RS256, key1, "key1")
+	token2 := signTokenWithKeyID(t, jose.ES256, key2, "key2")
+
+	assert.Equal(t, http.StatusOK, serve(token1))
+	assert.Equal(t, http.StatusOK, serve(token1))
+	assert.Equal(t, int32(1), fetches.Load(), "the keys should be cached")
+
+	// The keys are rotated.
+	mu.Lock()
+	jwks.Keys = []jose.JSONWebKey{{Key: &key2.PublicKey, KeyID: "key2", Algorithm: string(jose.ES256), Use: "sig"}}
+	mu.Unlock()
+
+	assert.Equal(t, http.StatusOK, serve(token2))
+	assert.Equal(t, int32(2), fetches.Load(), "the keys should be fetched for an unknown key ID")
+
+	assert.Equal(t, http.StatusUnauthorized, serve(token1))
+}
+
+func TestJWKSCache_concurrentFetches(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if fetches.Add(1) == 1 {
+			<-release
+		} else {
+			time.Sleep(time.Second)
+		}
+
+		_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "key1"}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	cache := &jwksCache{url: server.URL, client: server.Client(), refreshInterval: time.Hour}
+
+	// The fetch outlives the request which triggered it.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = cache.Keys(ctx, "key1")
+	require.ErrorIs(t, err, context.Canceled)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			keys, err := cache.Keys(context.Background(), "key1")
+			assert.NoError(t, err)
+			assert.Len(t, keys, 1)
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// The cached keys are served while the stale key set is fetched again.
+	cache.mu.Lock()
+	cache.fetchedAt = time.Now().Add(-2 * time.Hour)
+	cache.mu.Unlock()
+
+	start := time.Now()
+
+	keys, err := cache.Keys(context.Background(), "key1")
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestNewJWT_noKey(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := NewJWT(context.Background(), next, dynamic.JWT{Issuer: "foo"}, "jwt-test")
+	assert.Error(t, err)
+
+	_, err = NewJWT(context.Background(), next, dynamic.JWT{PublicKeys: []string{"foo"}}, "jwt-test")
+	assert.Error(t, err)
+}
+
+// signToken signs the given claims, which expire in an hour unless their expiration is set.
+func signToken(t *testing.T, alg jose.SignatureAlgorithm, key interface{}, claims jwt.Claims, privateClaims map[string]interface{}) string {
+	t.Helper()
+
+	if claims.Expiry == nil {
+		claims.Expiry = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	}
+
+	return signClaims(t, alg, key, claims, privateClaims)
+}
+
+func signClaims(t *testing.T, alg jose.SignatureAlgorithm, key interface{}, claims jwt.Claims, privateClaims map[string]interface{}) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, (&jose.SignerOptions{}).WithType("JWT"))
+	require.NoError(t, err)
+
+	builder := jwt.Signed(signer).Claims(claims)
+	if privateClaims != nil {
+		builder = builder.Claims(privateClaims)
+	}
+
+	token, err := builder.CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func signTokenWithKeyID(t *testing.T, alg jose.SignatureAlgorithm, key interface{}, kid string) string {
+	t.Helper()
+
+	return signToken(t, alg, jose.JSONWebKey{Key: key, KeyID: kid}, jwt.Claims{Subject: "foo"}, nil)
+}
+
+func encodePublicKey(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	data, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: data}))
+}
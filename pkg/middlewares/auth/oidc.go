@@ -0,0 +1,747 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/tracing"
+)
+
+const (
+	oidcTypeName = "OIDC"
+
+	// oidcStateMaxAge is the maximum duration of an authentication on the provider.
+	oidcStateMaxAge = 10 * time.Minute
+	// oidcMaxCookieSize is the maximum size of a cookie supported by most browsers.
+	oidcMaxCookieSize = 4096
+	// oidcDiscoveryTimeout is the maximum duration of the discovery of the provider configuration.
+	oidcDiscoveryTimeout = 10 * time.Second
+	// oidcCSRFTokenField is the name of the form field holding the CSRF token of the logout requests.
+	oidcCSRFTokenField = "csrf_token"
+)
+
+// oidcLogoutPage is the page asking the users to confirm their logout,
+// which is only done on a POST request with the CSRF token of their session.
+const oidcLogoutPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Log out</title></head>
+<body>
+<form method="post" action="%s">
+<input type="hidden" name="` + oidcCSRFTokenField + `" value="%s">
+<button type="submit">Log out</button>
+</form>
+</body>
+</html>
+`
+
+// oidcProvider holds the discovered configuration of an OpenID Connect provider.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+
+	// verifier validates the ID tokens issued by the provider.
+	verifier *jwtAuth
+}
+
+type oidcAuth struct {
+	next               http.Handler
+	name               string
+	issuer             string
+	clientID           string
+	clientSecret       string
+	scopes             []string
+	redirectURL        string
+	callbackPath       string
+	logoutPath         string
+	cookieName         string
+	cookieDomain       string
+	sessionMaxAge      time.Duration
+	forwardClaims      map[string]string
+	forwardAccessToken bool
+	client             *http.Client
+	cookies            *cookieCodec
+
+	// discoveries ensures that only one discovery of the provider configuration is in flight at a time.
+	discoveries singleflight.Group
+
+	mu             sync.Mutex
+	provider       *oidcProvider
+	discoveredAt   time.Time
+	discoveryError error
+}
+
+// NewOIDC creates an OpenID Connect authentication middleware.
+func NewOIDC(ctx context.Context, next http.Handler, config dynamic.OIDC, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, oidcTypeName).Debug().Msg("Creating middleware")
+
+	if config.Issuer == "" {
+		return nil, errors.New("issuer must be defined")
+	}
+
+	if config.ClientID == "" {
+		return nil, errors.New("clientID must be defined")
+	}
+
+	if config.SessionSecret == "" {
+		return nil, errors.New("sessionSecret must be defined")
+	}
+
+	cookies, err := newCookieCodec(config.SessionSecret)
+	if err != nil {
+		return nil, fmt.Errorf("creating session cookie codec: %w", err)
+	}
+
+	oa := &oidcAuth{
+		next:               next,
+		name:               name,
+		issuer:             config.Issuer,
+		clientID:           config.ClientID,
+		clientSecret:       config.ClientSecret,
+		callbackPath:       config.CallbackPath,
+		logoutPath:         config.LogoutPath,
+		cookieName:         config.SessionCookieName,
+		cookieDomain:       config.SessionCookieDomain,
+		sessionMaxAge:      time.Duration(config.SessionMaxAge),
+		forwardClaims:      config.ForwardClaims,
+		forwardAccessToken: config.ForwardAccessToken,
+		cookies:            cookies,
+		client:             &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if config.RedirectURL != "" {
+		redirectURL, err := url.Parse(config.RedirectURL)
+		if err != nil || !redirectURL.IsAbs() || redirectURL.Host == "" {
+			return nil, fmt.Errorf("redirectURL must be an absolute URL: %q", config.RedirectURL)
+		}
+
+		// Without a path, the provider would redirect to the root path, which cannot be told apart from the other requests.
+		if redirectURL.Path == "" || redirectURL.Path == "/" {
+			return nil, fmt.Errorf("redirectURL must have a callback path: %q", config.RedirectURL)
+		}
+
+		// The callback is served on the path of the configured redirection URL.
+		oa.redirectURL = config.RedirectURL
+		oa.callbackPath = redirectURL.Path
+	}
+
+	if oa.callbackPath == "" {
+		oa.callbackPath = "/oidc/callback"
+	}
+
+	if oa.cookieName == "" {
+		oa.cookieName = "_traefik_oidc"
+	}
+
+	if oa.sessionMaxAge <= 0 {
+		oa.sessionMaxAge = 24 * time.Hour
+	}
+
+	// The openid scope is required to get an ID token.
+	oa.scopes = []string{"openid"}
+	for _, scope := range config.Scopes {
+		if scope != "openid" {
+			oa.scopes = append(oa.scopes, scope)
+		}
+	}
+
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create client TLS configuration: %w", err)
+		}
+
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tlsConfig
+		oa.client.Transport = tr
+	}
+
+	return oa, nil
+}
+
+func (o *oidcAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, tracing.SpanKindNoneEnum
+}
+
+func (o *oidcAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), o.name, oidcTypeName)
+	ctx := logger.WithContext(req.Context())
+
+	provider, err := o.getProvider(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not discover the OpenID Connect provider")
+		tracing.SetErrorWithEvent(req, "Could not discover the OpenID Connect provider")
+
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	switch req.URL.Path {
+	case o.callbackPath:
+		o.serveCallback(ctx, rw, req, provider)
+		return
+	case o.logoutPath:
+		if o.logoutPath != "" {
+			o.serveLogout(ctx, rw, req, provider)
+			return
+		}
+	}
+
+	session, err := o.loadSession(req)
+	if err != nil {
+		logger.Debug().Err(err).Msg("No valid session")
+		o.authenticate(ctx, rw, req, provider)
+		return
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  session.AccessToken,
+		RefreshToken: session.RefreshToken,
+		Expiry:       session.Expiry,
+	}
+
+	if !token.Valid() {
+		if err = o.refresh(ctx, req, provider, session); err != nil {
+			logger.Debug().Err(err).Msg("Could not refresh the session")
+			o.authenticate(ctx, rw, req, provider)
+			return
+		}
+
+		logger.Debug().Msg("Session refreshed")
+
+		if err = o.saveSession(rw, req, session); err != nil {
+			logger.Error().Err(err).Msg("Could not save the session")
+			tracing.SetErrorWithEvent(req, "Could not save the session")
+
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		if sub, ok := session.Claims["sub"].(string); ok {
+			logData.Core[accesslog.ClientUsername] = sub
+		}
+	}
+
+	for header, claim := range o.forwardClaims {
+		// The header is removed, so that it cannot be forged by the client.
+		req.Header.Del(header)
+
+		if value, ok := claimValue(session.Claims, claim); ok {
+			req.Header.Set(header, value)
+		}
+	}
+
+	if o.forwardAccessToken {
+		req.Header.Set(authorizationHeader, "Bearer "+session.AccessToken)
+	}
+
+	// The session cookie is only meant for this middleware.
+	removeCookie(req, o.cookieName)
+
+	o.next.ServeHTTP(rw, req)
+}
+
+// authenticate redirects the user to the provider, to start the authorization code flow.
+func (o *oidcAuth) authenticate(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	// Only the navigations can be redirected to the provider.
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	state := &oidcState{
+		RedirectURI: req.URL.RequestURI(),
+		CreatedAt:   time.Now(),
+	}
+
+	var err error
+	for _, value := range []*string{&state.State, &state.CodeVerifier, &state.Nonce} {
+		if *value, err = randomString(); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = o.setCookie(rw, req, o.stateCookieName(), state, o.callbackPath, oidcStateMaxAge)
+	}
+
+	if err != nil {
+		middlewares.GetLogger(ctx, o.name, oidcTypeName).Error().Err(err).Msg("Could not start the authentication")
+		tracing.SetErrorWithEvent(req, "Could not start the authentication")
+
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(state.CodeVerifier))
+
+	authURL := o.oauth2Config(req, provider).AuthCodeURL(state.State,
+		oauth2.SetAuthURLParam("nonce", state.Nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	http.Redirect(rw, req, authURL, http.StatusFound)
+}
+
+// serveCallback handles the redirection of the user by the provider, at the end of the authorization code flow.
+func (o *oidcAuth) serveCallback(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	logger := middlewares.GetLogger(ctx, o.name, oidcTypeName)
+
+	query := req.URL.Query()
+	if errorCode := query.Get("error"); errorCode != "" {
+		logger.Debug().Str("error", errorCode).Str("errorDescription", query.Get("error_description")).Msg("Authentication failed on the provider")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	var state oidcState
+	if err := o.readCookie(req, o.stateCookieName(), &state); err != nil {
+		logger.Debug().Err(err).Msg("Invalid authentication state")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(state.State), []byte(query.Get("state"))) != 1 || time.Since(state.CreatedAt) > oidcStateMaxAge {
+		logger.Debug().Msg("Invalid authentication state")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// The state can only be used once.
+	o.deleteCookie(rw, req, o.stateCookieName(), o.callbackPath)
+
+	token, err := o.oauth2Config(req, provider).Exchange(o.clientContext(ctx), query.Get("code"),
+		oauth2.SetAuthURLParam("code_verifier", state.CodeVerifier),
+	)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Could not exchange the authorization code")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := o.verifyIDToken(ctx, provider, token)
+	if err == nil {
+		if nonce, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(nonce), []byte(state.Nonce)) != 1 {
+			err = errors.New("invalid ID token nonce")
+		}
+	}
+	if err != nil {
+		logger.Debug().Err(err).Msg("Invalid ID token")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	session := &oidcSession{CreatedAt: time.Now()}
+	o.updateSession(session, token, claims)
+
+	session.CSRFToken, err = randomString()
+	if err == nil {
+		err = o.saveSession(rw, req, session)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not save the session")
+		tracing.SetErrorWithEvent(req, "Could not save the session")
+
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Debug().Msg("Authentication succeeded")
+
+	// The redirection URI is protected by the state cookie encryption, but is still checked to be a local path.
+	redirectURI := state.RedirectURI
+	if !strings.HasPrefix(redirectURI, "/") || strings.HasPrefix(redirectURI, "//") {
+		redirectURI = "/"
+	}
+
+	http.Redirect(rw, req, redirectURI, http.StatusFound)
+}
+
+// serveLogout deletes the session, and redirects the user to the provider logout endpoint, if any.
+// The logout must be confirmed with a POST request holding the CSRF token of the session,
+// so that the users cannot be logged out by another site.
+func (o *oidcAuth) serveLogout(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	logger := middlewares.GetLogger(ctx, o.name, oidcTypeName)
+
+	session, err := o.loadSession(req)
+	if err != nil {
+		// There is no session to protect.
+		o.deleteCookie(rw, req, o.cookieName, "/")
+		http.Redirect(rw, req, "/", http.StatusFound)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		o.serveLogoutPage(ctx, rw, req, session)
+		return
+	case http.MethodPost:
+	default:
+		rw.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(rw, req.Body, 1<<12)
+	csrfToken := req.PostFormValue(oidcCSRFTokenField)
+
+	if session.CSRFToken == "" || subtle.ConstantTimeCompare([]byte(session.CSRFToken), []byte(csrfToken)) != 1 {
+		logger.Debug().Msg("Invalid logout CSRF token")
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	o.deleteCookie(rw, req, o.cookieName, "/")
+
+	if provider.EndSessionEndpoint == "" {
+		http.Redirect(rw, req, "/", http.StatusSeeOther)
+		return
+	}
+
+	endSessionURL, err := url.Parse(provider.EndSessionEndpoint)
+	if err != nil {
+		http.Redirect(rw, req, "/", http.StatusSeeOther)
+		return
+	}
+
+	query := endSessionURL.Query()
+	query.Set("client_id", o.clientID)
+	endSessionURL.RawQuery = query.Encode()
+
+	http.Redirect(rw, req, endSessionURL.String(), http.StatusSeeOther)
+}
+
+// serveLogoutPage serves the page asking the user to confirm the logout.
+func (o *oidcAuth) serveLogoutPage(ctx context.Context, rw http.ResponseWriter, req *http.Request, session *oidcSession) {
+	// The sessions created before the CSRF tokens were introduced have none.
+	if session.CSRFToken == "" {
+		var err error
+		session.CSRFToken, err = randomString()
+		if err == nil {
+			err = o.saveSession(rw, req, session)
+		}
+		if err != nil {
+			middlewares.GetLogger(ctx, o.name, oidcTypeName).Error().Err(err).Msg("Could not save the session")
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	// The page cannot be framed, so that the users cannot be tricked into confirming the logout.
+	rw.Header().Set("X-Frame-Options", "DENY")
+	rw.Header().Set("Content-Security-Policy", "default-src 'none'; form-action 'self'; frame-ancestors 'none'")
+
+	_, _ = fmt.Fprintf(rw, oidcLogoutPage, html.EscapeString(o.logoutPath), html.EscapeString(session.CSRFToken))
+}
+
+// refresh refreshes the tokens of the session.
+func (o *oidcAuth) refresh(ctx context.Context, req *http.Request, provider *oidcProvider, session *oidcSession) error {
+	if session.RefreshToken == "" {
+		return errors.New("session expired")
+	}
+
+	token, err := o.oauth2Config(req, provider).TokenSource(o.clientContext(ctx), &oauth2.Token{RefreshToken: session.RefreshToken}).Token()
+	if err != nil {
+		return err
+	}
+
+	var claims map[string]interface{}
+	if rawIDToken, _ := token.Extra("id_token").(string); rawIDToken != "" {
+		claims, err = o.verifyIDToken(ctx, provider, token)
+		if err != nil {
+			return err
+		}
+	}
+
+	o.updateSession(session, token, claims)
+
+	return nil
+}
+
+// verifyIDToken verifies the ID token obtained with the given token, and returns its claims.
+func (o *oidcAuth) verifyIDToken(ctx context.Context, provider *oidcProvider, token *oauth2.Token) (map[string]interface{}, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New("missing ID token")
+	}
+
+	return provider.verifier.validateToken(ctx, rawIDToken)
+}
+
+// updateSession updates the session with the given token, and the given ID token claims, if any.
+func (o *oidcAuth) updateSession(session *oidcSession, token *oauth2.Token, claims map[string]interface{}) {
+	session.AccessToken = token.AccessToken
+	session.Expiry = token.Expiry
+
+	// The refresh token is not always renewed.
+	if token.RefreshToken != "" {
+		session.RefreshToken = token.RefreshToken
+	}
+
+	if claims == nil {
+		return
+	}
+
+	// Only the needed claims are kept, to limit the size of the cookie.
+	session.Claims = make(map[string]interface{})
+	if sub, ok := claims["sub"]; ok {
+		session.Claims["sub"] = sub
+	}
+
+	for _, claim := range o.forwardClaims {
+		if value, ok := claims[claim]; ok {
+			session.Claims[claim] = value
+		}
+	}
+}
+
+func (o *oidcAuth) loadSession(req *http.Request) (*oidcSession, error) {
+	var session oidcSession
+	if err := o.readCookie(req, o.cookieName, &session); err != nil {
+		return nil, err
+	}
+
+	if time.Since(session.CreatedAt) > o.sessionMaxAge {
+		return nil, errors.New("session expired")
+	}
+
+	return &session, nil
+}
+
+func (o *oidcAuth) saveSession(rw http.ResponseWriter, req *http.Request, session *oidcSession) error {
+	maxAge := o.sessionMaxAge - time.Since(session.CreatedAt)
+
+	return o.setCookie(rw, req, o.cookieName, session, "/", maxAge)
+}
+
+func (o *oidcAuth) stateCookieName() string {
+	return o.cookieName + "_state"
+}
+
+func (o *oidcAuth) readCookie(req *http.Request, name string, value interface{}) error {
+	cookie, err := req.Cookie(name)
+	if err != nil {
+		return err
+	}
+
+	return o.cookies.decode(name, cookie.Value, value)
+}
+
+func (o *oidcAuth) setCookie(rw http.ResponseWriter, req *http.Request, name string, value interface{}, path string, maxAge time.Duration) error {
+	encoded, err := o.cookies.encode(name, value)
+	if err != nil {
+		return err
+	}
+
+	if len(encoded) > oidcMaxCookieSize {
+		return fmt.Errorf("cookie %s too large: %d bytes", name, len(encoded))
+	}
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     name,
+		Value:    encoded,
+		Path:     path,
+		Domain:   o.cookieDomain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   requestScheme(req) == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+func (o *oidcAuth) deleteCookie(rw http.ResponseWriter, req *http.Request, name, path string) {
+	http.SetCookie(rw, &http.Cookie{
+		Name:     name,
+		Path:     path,
+		Domain:   o.cookieDomain,
+		MaxAge:   -1,
+		Secure:   requestScheme(req) == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// oauth2Config returns the OAuth 2.0 configuration,
+// with the configured redirection URL, or else the one of the request host.
+func (o *oidcAuth) oauth2Config(req *http.Request, provider *oidcProvider) *oauth2.Config {
+	redirectURL := o.redirectURL
+	if redirectURL == "" {
+		redirectURL = requestScheme(req) + "://" + req.Host + o.callbackPath
+	}
+
+	return &oauth2.Config{
+		ClientID:     o.clientID,
+		ClientSecret: o.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  provider.AuthorizationEndpoint,
+			TokenURL: provider.TokenEndpoint,
+		},
+		RedirectURL: redirectURL,
+		Scopes:      o.scopes,
+	}
+}
+
+// clientContext returns a context making the OAuth 2.0 calls use the provider client.
+func (o *oidcAuth) clientContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, o.client)
+}
+
+// getProvider returns the provider configuration, discovering it if needed.
+// The discovery is not bound to the request, so that it is not canceled along with it,
+// and shared by the concurrent requests.
+func (o *oidcAuth) getProvider(ctx context.Context) (*oidcProvider, error) {
+	o.mu.Lock()
+	provider, discoveredAt, discoveryError := o.provider, o.discoveredAt, o.discoveryError
+	o.mu.Unlock()
+
+	if provider != nil {
+		return provider, nil
+	}
+
+	// Failed discoveries are not retried before the minimum refresh interval.
+	if time.Since(discoveredAt) <= jwksMinRefreshInterval {
+		return nil, discoveryError
+	}
+
+	discovered := o.discoveries.DoChan(o.issuer, func() (interface{}, error) {
+		discoveryCtx, cancel := context.WithTimeout(context.Background(), oidcDiscoveryTimeout)
+		defer cancel()
+
+		provider, err := o.discover(discoveryCtx)
+
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		o.discoveredAt = time.Now()
+		o.provider, o.discoveryError = provider, err
+
+		return provider, err
+	})
+
+	select {
+	case result := <-discovered:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.(*oidcProvider), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// discover fetches the provider configuration, as defined by OpenID Connect Discovery.
+func (o *oidcAuth) discover(ctx context.Context) (*oidcProvider, error) {
+	discoveryURL := strings.TrimSuffix(o.issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating discovery request: %w", err)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching provider configuration: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching provider configuration: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("reading provider configuration: %w", err)
+	}
+
+	var provider oidcProvider
+	if err = json.Unmarshal(body, &provider); err != nil {
+		return nil, fmt.Errorf("decoding provider configuration: %w", err)
+	}
+
+	if strings.TrimSuffix(provider.Issuer, "/") != strings.TrimSuffix(o.issuer, "/") {
+		return nil, fmt.Errorf("provider issuer %q does not match the configured issuer", provider.Issuer)
+	}
+
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("incomplete provider configuration")
+	}
+
+	provider.verifier = &jwtAuth{
+		jwks:      getJWKSCache(provider.JWKSURI, o.client, 15*time.Minute),
+		issuer:    provider.Issuer,
+		audience:  []string{o.clientID},
+		clockSkew: jwt.DefaultLeeway,
+	}
+
+	// Some providers sign the ID tokens with the client secret.
+	if o.clientSecret != "" {
+		provider.verifier.secret = []byte(o.clientSecret)
+	}
+
+	return &provider, nil
+}
+
+// requestScheme returns the scheme used by the client.
+// The X-Forwarded-Proto header can be trusted, as the entry points only keep the one sent by the trusted IPs,
+// and otherwise set it from the connection.
+func requestScheme(req *http.Request) string {
+	switch req.Header.Get("X-Forwarded-Proto") {
+	case "https", "wss":
+		return "https"
+	case "http", "ws":
+		return "http"
+	}
+
+	if req.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// removeCookie removes the cookie with the given name from the request.
+func removeCookie(req *http.Request, name string) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		if cookie.Name != name {
+			req.AddCookie(cookie)
+		}
+	}
+}
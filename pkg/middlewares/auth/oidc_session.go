@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// oidcSession is the content of the session cookie.
+type oidcSession struct {
+	AccessToken  string                 `json:"accessToken"`
+	RefreshToken string                 `json:"refreshToken,omitempty"`
+	Expiry       time.Time              `json:"expiry,omitempty"`
+	Claims       map[string]interface{} `json:"claims,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	// CSRFToken protects the logout of the session.
+	CSRFToken string `json:"csrfToken,omitempty"`
+}
+
+// oidcState is the content of the state cookie, which holds the data of an ongoing authentication.
+type oidcState struct {
+	State        string    `json:"state"`
+	CodeVerifier string    `json:"codeVerifier"`
+	Nonce        string    `json:"nonce"`
+	RedirectURI  string    `json:"redirectURI"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// cookieCodec encrypts and authenticates the cookie values, with AES-GCM.
+type cookieCodec struct {
+	aead cipher.AEAD
+}
+
+func newCookieCodec(secret string) (*cookieCodec, error) {
+	// The key is derived from the secret, so that secrets of any length can be used.
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cookieCodec{aead: aead}, nil
+}
+
+// encode encrypts the given value for the cookie with the given name.
+func (c *cookieCodec) encode(name string, value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	// The cookie name is authenticated, so that the value of a cookie cannot be used as the value of another one.
+	sealed := c.aead.Seal(nonce, nonce, data, []byte(name))
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// decode decrypts the value of the cookie with the given name into value.
+func (c *cookieCodec) decode(name, encoded string, value interface{}) error {
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decoding cookie: %w", err)
+	}
+
+	if len(sealed) < c.aead.NonceSize() {
+		return errors.New("decoding cookie: value too short")
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	data, err := c.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return fmt.Errorf("decrypting cookie: %w", err)
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+// randomString returns a random URL-safe string, suitable for the state, the nonce, and the PKCE code verifier.
+func randomString() (string, error) {
+	data := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,631 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestOIDC(t *testing.T) {
+	provider := newMockOIDCProvider(t, 3600)
+
+	var forwarded http.Header
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req.Header.Clone()
+	})
+
+	handler, err := NewOIDC(context.Background(), next, provider.config(), "oidc-test")
+	require.NoError(t, err)
+
+	session := login(t, handler, provider, "/foo?bar=baz")
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.localhost/foo", nil)
+	req.AddCookie(session)
+	req.AddCookie(&http.Cookie{Name: "other", Value: "value"})
+	req.Header.Set("X-Email", "forged@example.com")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "user@example.com", forwarded.Get("X-Email"))
+	assert.Equal(t, "Bearer access-token-1", forwarded.Get("Authorization"))
+	assert.Equal(t, "other=value", forwarded.Get("Cookie"))
+	assert.Empty(t, recorder.Result().Cookies(), "the session should not be refreshed")
+}
+
+func TestOIDC_refresh(t *testing.T) {
+	// The access tokens are considered as expired as soon as they are issued.
+	provider := newMockOIDCProvider(t, 1)
+
+	var forwarded http.Header
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req.Header.Clone()
+	})
+
+	handler, err := NewOIDC(context.Background(), next, provider.config(), "oidc-test")
+	require.NoError(t, err)
+
+	session := login(t, handler, provider, "/")
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.localhost/", nil)
+	req.AddCookie(session)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int32(1), provider.refreshes.Load())
+	assert.Equal(t, "Bearer access-token-2", forwarded.Get("Authorization"))
+	assert.Equal(t, "user@example.com", forwarded.Get("X-Email"))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "_traefik_oidc", cookies[0].Name)
+	assert.NotEqual(t, session.Value, cookies[0].Value)
+
+	// The refresh token is revoked.
+	provider.mu.Lock()
+	provider.refreshToken = ""
+	provider.mu.Unlock()
+
+	req = httptest.NewRequest(http.MethodGet, "http://app.localhost/", nil)
+	req.AddCookie(cookies[0])
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("Location"), provider.URL+"/authorize"))
+}
+
+func TestOIDC_unauthenticated(t *testing.T) {
+	provider := newMockOIDCProvider(t, 3600)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := NewOIDC(context.Background(), next, provider.config(), "oidc-test")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc    string
+		method  string
+		target  string
+		cookie  *http.Cookie
+		expCode int
+	}{
+		{
+			desc:    "navigation without session",
+			method:  http.MethodGet,
+			target:  "http://app.localhost/foo",
+			expCode: http.StatusFound,
+		},
+		{
+			desc:    "navigation with a forged session",
+			method:  http.MethodGet,
+			target:  "http://app.localhost/foo",
+			cookie:  &http.Cookie{Name: "_traefik_oidc", Value: "forged"},
+			expCode: http.StatusFound,
+		},
+		{
+			desc:    "non navigation without session",
+			method:  http.MethodPost,
+			target:  "http://app.localhost/foo",
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			desc:    "callback without state",
+			method:  http.MethodGet,
+			target:  "http://app.localhost/oidc/callback?code=foo&state=bar",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			desc:    "callback with an error",
+			method:  http.MethodGet,
+			target:  "http://app.localhost/oidc/callback?error=access_denied",
+			expCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, test.target, nil)
+			if test.cookie != nil {
+				req.AddCookie(test.cookie)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expCode, recorder.Code)
+		})
+	}
+}
+
+func TestOIDC_invalidState(t *testing.T) {
+	provider := newMockOIDCProvider(t, 3600)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := NewOIDC(context.Background(), next, provider.config(), "oidc-test")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.localhost/", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	stateCookies := recorder.Result().Cookies()
+	require.Len(t, stateCookies, 1)
+
+	callbackURL := provider.authorize(t, recorder.Header().Get("Location"))
+	callbackURL.RawQuery = strings.Replace(callbackURL.RawQuery, "state=", "state=other", 1)
+
+	req = httptest.NewRequest(http.MethodGet, callbackURL.String(), nil)
+	req.AddCookie(stateCookies[0])
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestOIDC_logout(t *testing.T) {
+	provider := newMockOIDCProvider(t, 3600)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	config := provider.config()
+	config.LogoutPath = "/logout"
+
+	handler, err := NewOIDC(context.Background(), next, config, "oidc-test")
+	require.NoError(t, err)
+
+	session := login(t, handler, provider, "/")
+
+	logout := func(method, csrfToken string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "http://app.localhost/logout", strings.NewReader(url.Values{"csrf_token": {csrfToken}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(session)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	// The logout must be confirmed.
+	recorder := logout(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Result().Cookies())
+	assert.Equal(t, "DENY", recorder.Header().Get("X-Frame-Options"))
+
+	matches := regexp.MustCompile(`name="csrf_token" value="([^"]+)"`).FindStringSubmatch(recorder.Body.String())
+	require.Len(t, matches, 2)
+
+	// Another site cannot log the user out.
+	recorder = logout(http.MethodPost, "forged")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Empty(t, recorder.Result().Cookies())
+
+	recorder = logout(http.MethodPut, matches[1])
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = logout(http.MethodPost, matches[1])
+	assert.Equal(t, http.StatusSeeOther, recorder.Code)
+	assert.Equal(t, provider.URL+"/logout?client_id=client", recorder.Header().Get("Location"))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "_traefik_oidc", cookies[0].Name)
+	assert.Negative(t, cookies[0].MaxAge)
+}
+
+func TestOIDC_redirectURL(t *testing.T) {
+	provider := newMockOIDCProvider(t, 3600)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	testCases := []struct {
+		desc           string
+		redirectURL    string
+		forwardedProto string
+		expRedirectURI string
+		expCookiePath  string
+	}{
+		{
+			desc:           "request host and scheme",
+			forwardedProto: "https",
+			expRedirectURI: "https://app.localhost/oidc/callback",
+			expCookiePath:  "/oidc/callback",
+		},
+		{
+			desc:           "unknown forwarded scheme",
+			forwardedProto: "javascript",
+			expRedirectURI: "http://app.localhost/oidc/callback",
+			expCookiePath:  "/oidc/callback",
+		},
+		{
+			desc:           "configured redirection URL",
+			redirectURL:    "https://app.example.com/auth/callback",
+			forwardedProto: "http",
+			expRedirectURI: "https://app.example.com/auth/callback",
+			expCookiePath:  "/auth/callback",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := provider.config()
+			config.RedirectURL = test.redirectURL
+
+			handler, err := NewOIDC(context.Background(), next, config, "oidc-test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://app.localhost/", nil)
+			req.Header.Set("X-Forwarded-Proto", test.forwardedProto)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			require.Equal(t, http.StatusFound, recorder.Code)
+
+			authURL, err := url.Parse(recorder.Header().Get("Location"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expRedirectURI, authURL.Query().Get("redirect_uri"))
+
+			cookies := recorder.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, test.expCookiePath, cookies[0].Path)
+		})
+	}
+}
+
+func TestOIDC_discovery(t *testing.T) {
+	provider := newMockOIDCProvider(t, 3600)
+	provider.discoveryDelay = 200 * time.Millisecond
+
+	handler, err := NewOIDC(context.Background(), http.NotFoundHandler(), provider.config(), "oidc-test")
+	require.NoError(t, err)
+
+	oa, ok := handler.(*oidcAuth)
+	require.True(t, ok)
+
+	// The discovery outlives the request which triggered it.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = oa.getProvider(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			discovered, err := oa.getProvider(context.Background())
+			assert.NoError(t, err)
+			assert.NotNil(t, discovered)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), provider.discoveries.Load())
+}
+
+func TestNewOIDC_invalidConfig(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	testCases := []struct {
+		desc   string
+		config dynamic.OIDC
+	}{
+		{
+			desc:   "missing issuer",
+			config: dynamic.OIDC{ClientID: "client", SessionSecret: "secret"},
+		},
+		{
+			desc:   "missing client ID",
+			config: dynamic.OIDC{Issuer: "https://issuer.example.com", SessionSecret: "secret"},
+		},
+		{
+			desc:   "missing session secret",
+			config: dynamic.OIDC{Issuer: "https://issuer.example.com", ClientID: "client"},
+		},
+		{
+			desc:   "relative redirection URL",
+			config: dynamic.OIDC{Issuer: "https://issuer.example.com", ClientID: "client", SessionSecret: "secret", RedirectURL: "/oidc/callback"},
+		},
+		{
+			desc:   "redirection URL without path",
+			config: dynamic.OIDC{Issuer: "https://issuer.example.com", ClientID: "client", SessionSecret: "secret", RedirectURL: "https://app.example.com"},
+		},
+		{
+			desc:   "redirection URL with the root path",
+			config: dynamic.OIDC{Issuer: "https://issuer.example.com", ClientID: "client", SessionSecret: "secret", RedirectURL: "https://app.example.com/"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewOIDC(context.Background(), next, test.config, "oidc-test")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCookieCodec(t *testing.T) {
+	codec, err := newCookieCodec("secret")
+	require.NoError(t, err)
+
+	encoded, err := codec.encode("foo", oidcSession{AccessToken: "token"})
+	require.NoError(t, err)
+
+	var session oidcSession
+	require.NoError(t, codec.decode("foo", encoded, &session))
+	assert.Equal(t, "token", session.AccessToken)
+
+	// The value of a cookie cannot be used for another cookie.
+	assert.Error(t, codec.decode("bar", encoded, &session))
+
+	other, err := newCookieCodec("other")
+	require.NoError(t, err)
+	assert.Error(t, other.decode("foo", encoded, &session))
+}
+
+// login runs the authorization code flow for the given target, and returns the session cookie.
+func login(t *testing.T, handler http.Handler, provider *mockOIDCProvider, target string) *http.Cookie {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.localhost"+target, nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	stateCookies := recorder.Result().Cookies()
+	require.Len(t, stateCookies, 1)
+	assert.Equal(t, "/oidc/callback", stateCookies[0].Path)
+
+	callbackURL := provider.authorize(t, recorder.Header().Get("Location"))
+
+	req = httptest.NewRequest(http.MethodGet, callbackURL.String(), nil)
+	req.AddCookie(stateCookies[0])
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, target, recorder.Header().Get("Location"))
+
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == "_traefik_oidc" {
+			assert.True(t, cookie.HttpOnly)
+			return cookie
+		}
+	}
+
+	require.Fail(t, "missing session cookie")
+	return nil
+}
+
+// mockOIDCProvider is an OpenID Connect provider, which authenticates every user as user@example.com.
+type mockOIDCProvider struct {
+	*httptest.Server
+
+	key            *rsa.PrivateKey
+	expiresIn      int
+	discoveryDelay time.Duration
+	discoveries    atomic.Int32
+	refreshes      atomic.Int32
+
+	mu           sync.Mutex
+	codes        map[string]url.Values
+	accessTokens int
+	refreshToken string
+}
+
+func newMockOIDCProvider(t *testing.T, expiresIn int) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	provider := &mockOIDCProvider{
+		key:          key,
+		expiresIn:    expiresIn,
+		codes:        make(map[string]url.Values),
+		refreshToken: "refresh-token",
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, req *http.Request) {
+		provider.discoveries.Add(1)
+		time.Sleep(provider.discoveryDelay)
+
+		_ = json.NewEncoder(rw).Encode(map[string]string{
+			"issuer":                 provider.URL,
+			"authorization_endpoint": provider.URL + "/authorize",
+			"token_endpoint":         provider.URL + "/token",
+			"jwks_uri":               provider.URL + "/jwks",
+			"end_session_endpoint":   provider.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "key", Algorithm: string(jose.RS256), Use: "sig"}},
+		})
+	})
+	mux.HandleFunc("/authorize", provider.serveAuthorize)
+	mux.HandleFunc("/token", provider.serveToken)
+
+	provider.Server = httptest.NewServer(mux)
+	t.Cleanup(provider.Close)
+
+	return provider
+}
+
+func (p *mockOIDCProvider) config() dynamic.OIDC {
+	return dynamic.OIDC{
+		Issuer:             p.URL,
+		ClientID:           "client",
+		ClientSecret:       "secret",
+		Scopes:             []string{"openid", "email"},
+		CallbackPath:       "/oidc/callback",
+		SessionSecret:      "session-secret",
+		SessionCookieName:  "_traefik_oidc",
+		SessionMaxAge:      ptypes.Duration(time.Hour),
+		ForwardClaims:      map[string]string{"X-Email": "email"},
+		ForwardAccessToken: true,
+	}
+}
+
+// authorize follows the given authorization URL, and returns the callback URL the provider redirects to.
+func (p *mockOIDCProvider) authorize(t *testing.T, authURL string) *url.URL {
+	t.Helper()
+
+	require.True(t, strings.HasPrefix(authURL, p.URL+"/authorize"), authURL)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callbackURL, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+
+	return callbackURL
+}
+
+func (p *mockOIDCProvider) serveAuthorize(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	if query.Get("client_id") != "client" || query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		http.Error(rw, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	code := "code-" + query.Get("state")
+	p.codes[code] = query
+	p.mu.Unlock()
+
+	redirectURL, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(rw, "invalid redirect URI", http.StatusBadRequest)
+		return
+	}
+
+	redirectURL.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+
+	http.Redirect(rw, req, redirectURL.String(), http.StatusFound)
+}
+
+func (p *mockOIDCProvider) serveToken(rw http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		http.Error(rw, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	claims := map[string]interface{}{"email": "user@example.com"}
+
+	switch req.PostForm.Get("grant_type") {
+	case "authorization_code":
+		authRequest, ok := p.codes[req.PostForm.Get("code")]
+		delete(p.codes, req.PostForm.Get("code"))
+
+		challenge := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+		if !ok || authRequest.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) ||
+			authRequest.Get("redirect_uri") != req.PostForm.Get("redirect_uri") {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		claims["nonce"] = authRequest.Get("nonce")
+
+	case "refresh_token":
+		if p.refreshToken == "" || req.PostForm.Get("refresh_token") != p.refreshToken {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		p.refreshes.Add(1)
+
+	default:
+		http.Error(rw, "unsupported grant type", http.StatusBadRequest)
+		return
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: p.key, KeyID: "key"}}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	idToken, err := jwt.Signed(signer).Claims(jwt.Claims{
+		Issuer:   p.URL,
+		Subject:  "user",
+		Audience: jwt.Audience{"client"},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	}).Claims(claims).CompactSerialize()
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	p.accessTokens++
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+		"access_token":  "access-token-" + strconv.Itoa(p.accessTokens),
+		"token_type":    "Bearer",
+		"expires_in":    p.expiresIn,
+		"refresh_token": p.refreshToken,
+		"id_token":      idToken,
+	})
+}
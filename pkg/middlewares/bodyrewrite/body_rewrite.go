@@ -0,0 +1,341 @@
+// Package bodyrewrite implements a middleware rewriting the request and response bodies
+// with regular expressions and JSONPath operations.
+package bodyrewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/decompress"
+	"traefik/v3/pkg/tracing"
+)
+
+const typeName = "BodyRewrite"
+
+// DefaultMaxBodyBytes is the default maximum size (in bytes) of a body held in memory to be rewritten.
+const DefaultMaxBodyBytes = 1024 * 1024
+
+var defaultContentTypes = []string{"text/*", "application/json"}
+
+// bodyRewrite is a middleware rewriting the request and response bodies.
+type bodyRewrite struct {
+	next         http.Handler
+	name         string
+	request      *rules
+	response     *rules
+	maxBodyBytes int64
+}
+
+// New creates a new body rewrite middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.BodyRewrite, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if config.MaxBodyBytes < 0 {
+		return nil, fmt.Errorf("negative value not valid for maxBodyBytes: %d", config.MaxBodyBytes)
+	}
+
+	maxBodyBytes := int64(DefaultMaxBodyBytes)
+	if config.MaxBodyBytes > 0 {
+		maxBodyBytes = config.MaxBodyBytes
+	}
+
+	request, err := newRules(config.Request)
+	if err != nil {
+		return nil, fmt.Errorf("request rules: %w", err)
+	}
+
+	response, err := newRules(config.Response)
+	if err != nil {
+		return nil, fmt.Errorf("response rules: %w", err)
+	}
+
+	if request == nil && response == nil {
+		return nil, errors.New("no request nor response rewriting rule")
+	}
+
+	return &bodyRewrite{
+		next:         next,
+		name:         name,
+		request:      request,
+		response:     response,
+		maxBodyBytes: maxBodyBytes,
+	}, nil
+}
+
+func (b *bodyRewrite) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *bodyRewrite) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), b.name, typeName)
+
+	if b.request != nil && !b.rewriteRequest(rw, req, logger) {
+		return
+	}
+
+	if b.response == nil {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
+	brw := &responseWriter{
+		rw:           rw,
+		req:          req,
+		rules:        b.response,
+		maxBodyBytes: b.maxBodyBytes,
+		logger:       logger,
+	}
+	defer brw.close()
+
+	b.next.ServeHTTP(brw, req)
+}
+
+// rewriteRequest replaces the request body by its rewritten version, and returns false if the request has been rejected.
+func (b *bodyRewrite) rewriteRequest(rw http.ResponseWriter, req *http.Request, logger *zerolog.Logger) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+
+	// The compressed request bodies are not rewritten.
+	if len(decompress.ContentCodings(req.Header)) > 0 {
+		return true
+	}
+
+	rewrite, isJSON := b.request.match(req.Header)
+	if !rewrite {
+		return true
+	}
+
+	if isJSON && len(b.request.jsonOperations) > 0 {
+		data, err := io.ReadAll(io.LimitReader(req.Body, b.maxBodyBytes+1))
+		_ = req.Body.Close()
+		if err != nil {
+			logger.Debug().Err(err).Msg("Error while reading the request body")
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return false
+		}
+
+		if int64(len(data)) > b.maxBodyBytes {
+			logger.Debug().Msgf("Request body larger than %d bytes", b.maxBodyBytes)
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return false
+		}
+
+		data = b.request.rewrite(data, true, logger)
+
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.TransferEncoding = nil
+		req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+
+		return true
+	}
+
+	if len(b.request.replacements) == 0 {
+		return true
+	}
+
+	// The length of the rewritten body is unknown until it is fully read.
+	req.Body = &rewriteReader{
+		src:      req.Body,
+		rewriter: newStreamRewriter(b.request.replacements, b.maxBodyBytes),
+		buf:      make([]byte, 32*1024),
+	}
+	req.ContentLength = -1
+	req.Header.Del("Content-Length")
+
+	return true
+}
+
+type replacement struct {
+	regexp      *regexp.Regexp
+	replacement []byte
+}
+
+// rules are the compiled rewriting rules of the request or response bodies.
+type rules struct {
+	contentTypes   []string
+	replacements   []replacement
+	jsonOperations []*jsonOperation
+}
+
+func newRules(config *dynamic.BodyRewriteRules) (*rules, error) {
+	if config == nil || len(config.Replacements) == 0 && len(config.JSONOperations) == 0 {
+		return nil, nil
+	}
+
+	r := &rules{contentTypes: defaultContentTypes}
+
+	if len(config.ContentTypes) > 0 {
+		r.contentTypes = nil
+		for _, contentType := range config.ContentTypes {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+			}
+
+			r.contentTypes = append(r.contentTypes, mediaType)
+		}
+	}
+
+	for _, rep := range config.Replacements {
+		exp, err := regexp.Compile(rep.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling regular expression %s: %w", rep.Regex, err)
+		}
+
+		r.replacements = append(r.replacements, replacement{regexp: exp, replacement: []byte(rep.Replacement)})
+	}
+
+	for _, op := range config.JSONOperations {
+		operation, err := newJSONOperation(strings.ToLower(strings.TrimSpace(op.Op)), op.Path, op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		r.jsonOperations = append(r.jsonOperations, operation)
+	}
+
+	return r, nil
+}
+
+// match reports whether a body with the given header must be rewritten, according to its content type,
+// and whether it is a JSON body.
+func (r *rules) match(header http.Header) (bool, bool) {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false, false
+	}
+
+	isJSON := mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+
+	for _, contentType := range r.contentTypes {
+		if contentType == mediaType {
+			return true, isJSON
+		}
+
+		if prefix, ok := strings.CutSuffix(contentType, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true, isJSON
+		}
+	}
+
+	return false, false
+}
+
+// applies reports whether the rules modify a body, given whether it is a JSON one.
+func (r *rules) applies(isJSON bool) bool {
+	return isJSON && len(r.jsonOperations) > 0 || len(r.replacements) > 0
+}
+
+// rewrite applies the JSON operations, if the body is a JSON one, and then the replacements to the whole body.
+// The JSON operations are skipped if the body is not a valid JSON document.
+func (r *rules) rewrite(data []byte, isJSON bool, logger *zerolog.Logger) []byte {
+	if isJSON && len(r.jsonOperations) > 0 {
+		rewritten, err := rewriteJSON(data, r.jsonOperations)
+		if err != nil {
+			logger.Debug().Err(err).Msg("Unable to apply the JSON operations to an invalid JSON body")
+		} else {
+			data = rewritten
+		}
+	}
+
+	return replaceAll(data, r.replacements)
+}
+
+func replaceAll(data []byte, replacements []replacement) []byte {
+	for _, rep := range replacements {
+		data = rep.regexp.ReplaceAll(data, rep.replacement)
+	}
+
+	return data
+}
+
+// streamRewriter applies the replacements to a body written by chunks.
+// The replacements are applied line by line, so that a match can span several chunks, but not several lines.
+// A line longer than the maximum size is rewritten in several parts.
+type streamRewriter struct {
+	replacements []replacement
+	maxLineBytes int64
+
+	pending []byte
+}
+
+func newStreamRewriter(replacements []replacement, maxLineBytes int64) *streamRewriter {
+	return &streamRewriter{
+		replacements: replacements,
+		maxLineBytes: maxLineBytes,
+	}
+}
+
+// process returns the rewritten version of the complete lines received so far,
+// or of all the data received so far if final is true.
+func (s *streamRewriter) process(p []byte, final bool) []byte {
+	s.pending = append(s.pending, p...)
+
+	var chunk []byte
+	switch i := bytes.LastIndexByte(s.pending, '\n'); {
+	case final || int64(len(s.pending)) >= s.maxLineBytes:
+		chunk = s.pending
+		s.pending = nil
+
+	case i >= 0:
+		chunk = s.pending[:i+1]
+		s.pending = append([]byte(nil), s.pending[i+1:]...)
+
+	default:
+		return nil
+	}
+
+	return replaceAll(chunk, s.replacements)
+}
+
+// rewriteReader is a request body rewritten while it is read.
+type rewriteReader struct {
+	src      io.ReadCloser
+	rewriter *streamRewriter
+	buf      []byte
+
+	out []byte
+	err error
+}
+
+func (r *rewriteReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		n, err := r.src.Read(r.buf)
+		switch {
+		case errors.Is(err, io.EOF):
+			r.out = r.rewriter.process(r.buf[:n], true)
+			r.err = io.EOF
+		case err != nil:
+			r.out = r.rewriter.process(r.buf[:n], false)
+			r.err = err
+		default:
+			r.out = r.rewriter.process(r.buf[:n], false)
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+
+	return n, nil
+}
+
+func (r *rewriteReader) Close() error {
+	return r.src.Close()
+}
@@ -0,0 +1,419 @@
+package bodyrewrite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestBodyRewrite_response(t *testing.T) {
+	hostRules := &dynamic.BodyRewriteRules{
+		Replacements: []dynamic.BodyReplacement{
+			{Regex: `(\w+)\.corp\.internal`, Replacement: "$1.example.com"},
+		},
+	}
+
+	jsonRules := &dynamic.BodyRewriteRules{
+		JSONOperations: []dynamic.JSONOperation{
+			{Op: "delete", Path: "$.internal"},
+		},
+		Replacements: []dynamic.BodyReplacement{
+			{Regex: `corp\.internal`, Replacement: "example.com"},
+		},
+	}
+
+	longBody := strings.Repeat("api.corp.internal\n", 10)
+
+	testCases := []struct {
+		desc                    string
+		config                  dynamic.BodyRewrite
+		contentType             string
+		contentEncoding         string
+		chunks                  []string
+		expectedStatusCode      int
+		expectedBody            string
+		expectedContentLength   string
+		expectedContentEncoding string
+		expectedWeakETag        bool
+	}{
+		{
+			desc:                  "streamed replacements",
+			config:                dynamic.BodyRewrite{Response: hostRules},
+			contentType:           "text/html; charset=utf-8",
+			chunks:                []string{"<a href=\"https://app.co", "rp.internal/\">app</a>\n<a href=\"https://api.corp.internal\">api</a>"},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          "<a href=\"https://app.example.com/\">app</a>\n<a href=\"https://api.example.com\">api</a>",
+			expectedContentLength: "",
+			expectedWeakETag:      true,
+		},
+		{
+			desc:                  "JSON operations and replacements",
+			config:                dynamic.BodyRewrite{Response: jsonRules},
+			contentType:           "application/json",
+			chunks:                []string{`{"url":"https://api.corp.internal",`, `"internal":{"id":1}}`},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          `{"url":"https://api.example.com"}`,
+			expectedContentLength: "33",
+			expectedWeakETag:      true,
+		},
+		{
+			desc:                  "invalid JSON body",
+			config:                dynamic.BodyRewrite{Response: jsonRules},
+			contentType:           "application/json",
+			chunks:                []string{`{"url":"https://api.corp.internal"`},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          `{"url":"https://api.example.com"`,
+			expectedContentLength: "32",
+			expectedWeakETag:      true,
+		},
+		{
+			desc:                  "JSON body too large",
+			config:                dynamic.BodyRewrite{Response: jsonRules, MaxBodyBytes: 10},
+			contentType:           "application/json",
+			chunks:                []string{`{"url":"https://api.corp.internal",`, `"internal":{"id":1}}`},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          `{"url":"https://api.corp.internal","internal":{"id":1}}`,
+			expectedContentLength: "55",
+		},
+		{
+			desc:                  "JSON body not modified",
+			config:                dynamic.BodyRewrite{Response: jsonRules},
+			contentType:           "application/json",
+			chunks:                []string{`{"url":"https://api.example.com"}`},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          `{"url":"https://api.example.com"}`,
+			expectedContentLength: "33",
+		},
+		{
+			desc:                  "content type not matching",
+			config:                dynamic.BodyRewrite{Response: hostRules},
+			contentType:           "application/octet-stream",
+			chunks:                []string{"api.corp.internal"},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          "api.corp.internal",
+			expectedContentLength: "17",
+		},
+		{
+			desc: "custom content types",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				ContentTypes: []string{"application/*"},
+				Replacements: hostRules.Replacements,
+			}},
+			contentType:           "application/javascript",
+			chunks:                []string{"api.corp.internal"},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          "api.example.com",
+			expectedContentLength: "",
+			expectedWeakETag:      true,
+		},
+		{
+			desc:                  "compressed body",
+			config:                dynamic.BodyRewrite{Response: hostRules},
+			contentType:           "text/html",
+			contentEncoding:       "gzip",
+			chunks:                []string{gzipString(longBody)},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          strings.Repeat("api.example.com\n", 10),
+			expectedContentLength: "160",
+			expectedWeakETag:      true,
+		},
+		{
+			desc:                  "compressed JSON body",
+			config:                dynamic.BodyRewrite{Response: jsonRules},
+			contentType:           "application/json",
+			contentEncoding:       "gzip",
+			chunks:                []string{gzipString(`{"url":"https://api.corp.internal","internal":{"id":1}}`)},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          `{"url":"https://api.example.com"}`,
+			expectedContentLength: "33",
+			expectedWeakETag:      true,
+		},
+		{
+			desc:                    "compressed body too large",
+			config:                  dynamic.BodyRewrite{Response: hostRules, MaxBodyBytes: 100},
+			contentType:             "text/html",
+			contentEncoding:         "gzip",
+			chunks:                  []string{gzipString(longBody)},
+			expectedStatusCode:      http.StatusOK,
+			expectedBody:            gzipString(longBody),
+			expectedContentLength:   strconv.Itoa(len(gzipString(longBody))),
+			expectedContentEncoding: "gzip",
+		},
+		{
+			desc:                    "invalid compressed body",
+			config:                  dynamic.BodyRewrite{Response: hostRules},
+			contentType:             "text/html",
+			contentEncoding:         "gzip",
+			chunks:                  []string{"api.corp.internal"},
+			expectedStatusCode:      http.StatusOK,
+			expectedBody:            "api.corp.internal",
+			expectedContentLength:   "17",
+			expectedContentEncoding: "gzip",
+		},
+		{
+			desc:                    "unsupported content coding",
+			config:                  dynamic.BodyRewrite{Response: hostRules},
+			contentType:             "text/html",
+			contentEncoding:         "deflate",
+			chunks:                  []string{"api.corp.internal"},
+			expectedStatusCode:      http.StatusOK,
+			expectedBody:            "api.corp.internal",
+			expectedContentLength:   "17",
+			expectedContentEncoding: "deflate",
+		},
+		{
+			desc:                  "request rules only",
+			config:                dynamic.BodyRewrite{Request: hostRules},
+			contentType:           "text/html",
+			chunks:                []string{"api.corp.internal"},
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          "api.corp.internal",
+			expectedContentLength: "17",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "gzip", req.Header.Get("Accept-Encoding"))
+
+				var length int
+				for _, chunk := range test.chunks {
+					length += len(chunk)
+				}
+
+				rw.Header().Set("Content-Type", test.contentType)
+				rw.Header().Set("Content-Length", strconv.Itoa(length))
+				rw.Header().Set("ETag", `"v1"`)
+				rw.Header().Set("Accept-Ranges", "bytes")
+				if test.contentEncoding != "" {
+					rw.Header().Set("Content-Encoding", test.contentEncoding)
+				}
+
+				for _, chunk := range test.chunks {
+					_, err := rw.Write([]byte(chunk))
+					require.NoError(t, err)
+				}
+			})
+
+			handler, err := New(context.Background(), next, test.config, "test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+			assert.Equal(t, test.expectedContentLength, recorder.Header().Get("Content-Length"))
+			assert.Equal(t, test.expectedContentEncoding, recorder.Header().Get("Content-Encoding"))
+
+			// The modified bodies no longer match the entity tag and the ranges of the original ones.
+			if test.expectedWeakETag {
+				assert.Equal(t, `W/"v1"`, recorder.Header().Get("ETag"))
+				assert.Empty(t, recorder.Header().Get("Accept-Ranges"))
+			} else {
+				assert.Equal(t, `"v1"`, recorder.Header().Get("ETag"))
+				assert.Equal(t, "bytes", recorder.Header().Get("Accept-Ranges"))
+			}
+		})
+	}
+}
+
+func TestBodyRewrite_request(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		config                dynamic.BodyRewrite
+		contentType           string
+		body                  string
+		expectedStatusCode    int
+		expectedBody          string
+		expectedContentLength int64
+	}{
+		{
+			desc: "streamed replacements",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				Replacements: []dynamic.BodyReplacement{{Regex: "foo", Replacement: "foobar"}},
+			}},
+			contentType:           "text/plain",
+			body:                  strings.Repeat("foo\n", 10000),
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          strings.Repeat("foobar\n", 10000),
+			expectedContentLength: -1,
+		},
+		{
+			desc: "JSON operations",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSONOperations: []dynamic.JSONOperation{{Op: "set", Path: "$.client", Value: `"mobile"`}},
+			}},
+			contentType:           "application/json; charset=utf-8",
+			body:                  `{"id":1}`,
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          `{"id":1,"client":"mobile"}`,
+			expectedContentLength: 26,
+		},
+		{
+			desc: "JSON body too large",
+			config: dynamic.BodyRewrite{
+				Request: &dynamic.BodyRewriteRules{
+					JSONOperations: []dynamic.JSONOperation{{Op: "delete", Path: "$.id"}},
+				},
+				MaxBodyBytes: 5,
+			},
+			contentType:        "application/json",
+			body:               `{"id":1}`,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc: "content type not matching",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				Replacements: []dynamic.BodyReplacement{{Regex: "foo", Replacement: "bar"}},
+			}},
+			contentType:           "image/png",
+			body:                  "foo",
+			expectedStatusCode:    http.StatusOK,
+			expectedBody:          "foo",
+			expectedContentLength: 3,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, test.expectedContentLength, req.ContentLength)
+
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, test.expectedBody, string(body))
+			})
+
+			handler, err := New(context.Background(), next, test.config, "test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestBodyRewrite_headRequest(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Content-Length", "42")
+	})
+
+	config := dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+		JSONOperations: []dynamic.JSONOperation{{Op: "delete", Path: "$.id"}},
+	}}
+
+	handler, err := New(context.Background(), next, config, "test")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "42", recorder.Header().Get("Content-Length"))
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.BodyRewrite
+	}{
+		{
+			desc:   "no rules",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{}},
+		},
+		{
+			desc: "invalid regex",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				Replacements: []dynamic.BodyReplacement{{Regex: "(foo"}},
+			}},
+		},
+		{
+			desc: "invalid content type",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				ContentTypes: []string{"text/html;;"},
+				Replacements: []dynamic.BodyReplacement{{Regex: "foo"}},
+			}},
+		},
+		{
+			desc: "invalid JSON operation",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSONOperations: []dynamic.JSONOperation{{Op: "set", Path: "$.foo"}},
+			}},
+		},
+		{
+			desc: "negative max body bytes",
+			config: dynamic.BodyRewrite{
+				Response: &dynamic.BodyRewriteRules{
+					Replacements: []dynamic.BodyReplacement{{Regex: "foo"}},
+				},
+				MaxBodyBytes: -1,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "test")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStreamRewriter(t *testing.T) {
+	rules, err := newRules(&dynamic.BodyRewriteRules{
+		Replacements: []dynamic.BodyReplacement{{Regex: "foo", Replacement: "bar"}},
+	})
+	require.NoError(t, err)
+
+	rewriter := newStreamRewriter(rules.replacements, 16)
+
+	// The incomplete line is kept until it is complete.
+	assert.Empty(t, rewriter.process([]byte("a fo"), false))
+	assert.Equal(t, "a bar\n", string(rewriter.process([]byte("o\nfo"), false)))
+
+	// A line longer than the maximum size is rewritten without waiting for its end.
+	assert.Empty(t, rewriter.process([]byte(" foo f"), false))
+	assert.Equal(t, "fo bar bar bar f", string(rewriter.process([]byte("oo foo f"), false)))
+	assert.Equal(t, "oo", string(rewriter.process([]byte("oo"), true)))
+}
+
+func gzipString(s string) string {
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+	_, _ = writer.Write([]byte(s))
+	_ = writer.Close()
+
+	return buf.String()
+}
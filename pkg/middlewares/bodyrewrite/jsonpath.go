@@ -0,0 +1,421 @@
+package bodyrewrite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	opSet    = "set"
+	opDelete = "delete"
+)
+
+type segmentKind int
+
+const (
+	memberSegment segmentKind = iota
+	indexSegment
+	wildcardSegment
+)
+
+// pathSegment is a step of a JSONPath expression, selecting an object member, an array element,
+// or all the members or elements with a wildcard.
+type pathSegment struct {
+	kind  segmentKind
+	name  string
+	index int
+}
+
+// parseJSONPath parses a JSONPath expression made of the root ($), followed by
+// member names (.name or ['name']), array indexes ([0]) and wildcards (.* or [*]).
+func parseJSONPath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, errors.New("path must start with $")
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+
+			name := rest[1:end]
+			switch name {
+			case "":
+				return nil, errors.New("empty member name")
+			case "*":
+				segments = append(segments, pathSegment{kind: wildcardSegment})
+			default:
+				segments = append(segments, pathSegment{kind: memberSegment, name: name})
+			}
+
+			rest = rest[end:]
+
+		case '[':
+			end := closingBracket(rest)
+			if end < 0 {
+				return nil, errors.New("missing closing bracket")
+			}
+
+			segment, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, err
+			}
+
+			segments = append(segments, segment)
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q", rest[0])
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, errors.New("the root cannot be set or deleted")
+	}
+
+	return segments, nil
+}
+
+// closingBracket returns the index of the bracket closing the one at the beginning of s,
+// ignoring the brackets inside quoted member names.
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == '\\':
+			i++
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote == 0 && (s[i] == '\'' || s[i] == '"'):
+			quote = s[i]
+		case quote == 0 && s[i] == ']':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func parseBracket(content string) (pathSegment, error) {
+	content = strings.TrimSpace(content)
+
+	switch {
+	case content == "*":
+		return pathSegment{kind: wildcardSegment}, nil
+
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		name := content[1 : len(content)-1]
+		name = strings.NewReplacer(`\\`, `\`, `\'`, `'`, `\"`, `"`).Replace(name)
+
+		return pathSegment{kind: memberSegment, name: name}, nil
+
+	default:
+		index, err := strconv.Atoi(content)
+		if err != nil || index < 0 {
+			return pathSegment{}, fmt.Errorf("invalid array index %q", content)
+		}
+
+		return pathSegment{kind: indexSegment, index: index}, nil
+	}
+}
+
+// jsonOperation sets or deletes the values matched by a JSONPath expression.
+type jsonOperation struct {
+	op       string
+	segments []pathSegment
+	value    json.RawMessage
+}
+
+func newJSONOperation(op, path, value string) (*jsonOperation, error) {
+	segments, err := parseJSONPath(strings.TrimSpace(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", path, err)
+	}
+
+	switch op {
+	case opSet:
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("invalid JSON value %q for the path %q", value, path)
+		}
+
+	case opDelete:
+
+	default:
+		return nil, fmt.Errorf("unsupported JSON operation %q, must be %s or %s", op, opSet, opDelete)
+	}
+
+	return &jsonOperation{
+		op:       op,
+		segments: segments,
+		value:    json.RawMessage(value),
+	}, nil
+}
+
+// apply applies the operation to the values matched by the given segments in node, and returns the modified node.
+// A set operation creates the matched member if its parent object exists, but never creates the parent objects.
+func (o *jsonOperation) apply(node interface{}, segments []pathSegment) interface{} {
+	segment, last := segments[0], len(segments) == 1
+
+	switch n := node.(type) {
+	case *jsonObject:
+		switch segment.kind {
+		case memberSegment:
+			child, ok := n.members[segment.name]
+			switch {
+			case last && o.op == opDelete:
+				n.delete(segment.name)
+			case last:
+				n.set(segment.name, o.newValue())
+			case ok:
+				n.set(segment.name, o.apply(child, segments[1:]))
+			}
+
+		case wildcardSegment:
+			if last && o.op == opDelete {
+				return newJSONObject()
+			}
+
+			for _, name := range n.names {
+				if last {
+					n.set(name, o.newValue())
+				} else {
+					n.set(name, o.apply(n.members[name], segments[1:]))
+				}
+			}
+		}
+
+		return n
+
+	case []interface{}:
+		switch segment.kind {
+		case indexSegment:
+			if segment.index >= len(n) {
+				return n
+			}
+
+			switch {
+			case last && o.op == opDelete:
+				return append(n[:segment.index:segment.index], n[segment.index+1:]...)
+			case last:
+				n[segment.index] = o.newValue()
+			default:
+				n[segment.index] = o.apply(n[segment.index], segments[1:])
+			}
+
+		case wildcardSegment:
+			if last && o.op == opDelete {
+				return []interface{}{}
+			}
+
+			for i, child := range n {
+				if last {
+					n[i] = o.newValue()
+				} else {
+					n[i] = o.apply(child, segments[1:])
+				}
+			}
+		}
+
+		return n
+
+	default:
+		return node
+	}
+}
+
+// newValue returns a new decoded copy of the value to set, so that the values set at several places are not shared.
+func (o *jsonOperation) newValue() interface{} {
+	value, err := decodeJSON(o.value)
+	if err != nil {
+		// The value has been validated at creation.
+		return nil
+	}
+
+	return value
+}
+
+// rewriteJSON applies the operations to the JSON document.
+func rewriteJSON(data []byte, operations []*jsonOperation) ([]byte, error) {
+	doc, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operation := range operations {
+		doc = operation.apply(doc, operation.segments)
+	}
+
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// jsonObject is a decoded JSON object, keeping the order of its members.
+type jsonObject struct {
+	names   []string
+	members map[string]interface{}
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{members: make(map[string]interface{})}
+}
+
+// set sets the value of a member, which is added after the other ones if it does not exist.
+func (o *jsonObject) set(name string, value interface{}) {
+	if _, ok := o.members[name]; !ok {
+		o.names = append(o.names, name)
+	}
+
+	o.members[name] = value
+}
+
+func (o *jsonObject) delete(name string) {
+	if _, ok := o.members[name]; !ok {
+		return
+	}
+
+	delete(o.members, name)
+	for i, n := range o.names {
+		if n == name {
+			o.names = append(o.names[:i], o.names[i+1:]...)
+			return
+		}
+	}
+}
+
+// decodeJSON decodes a single JSON value, keeping the numbers as they are written, and the order of the object members.
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid data after the top-level value")
+	}
+
+	return value, nil
+}
+
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := newJSONObject()
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			// As with encoding/json, the last value of a duplicated member wins.
+			object.set(name.(string), value)
+		}
+
+		// The closing brace.
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		return object, nil
+
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, value)
+		}
+
+		// The closing bracket.
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		return array, nil
+
+	default:
+		return token, nil
+	}
+}
+
+// encodeJSON encodes a decoded JSON value, without escaping the HTML characters.
+func encodeJSON(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case *jsonObject:
+		buf.WriteByte('{')
+		for i, name := range v.names {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := encodeJSON(buf, name); err != nil {
+				return err
+			}
+
+			buf.WriteByte(':')
+
+			if err := encodeJSON(buf, v.members[name]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+		return nil
+
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := encodeJSON(buf, element); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+		return nil
+
+	default:
+		encoder := json.NewEncoder(buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+
+		// The encoder terminates each value with a newline.
+		buf.Truncate(buf.Len() - 1)
+
+		return nil
+	}
+}
@@ -0,0 +1,237 @@
+package bodyrewrite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		path     string
+		expected []pathSegment
+		wantErr  bool
+	}{
+		{
+			desc: "dot notation",
+			path: "$.foo.bar",
+			expected: []pathSegment{
+				{kind: memberSegment, name: "foo"},
+				{kind: memberSegment, name: "bar"},
+			},
+		},
+		{
+			desc: "bracket notation",
+			path: `$['foo.bar']["baz"]['it\'s']`,
+			expected: []pathSegment{
+				{kind: memberSegment, name: "foo.bar"},
+				{kind: memberSegment, name: "baz"},
+				{kind: memberSegment, name: "it's"},
+			},
+		},
+		{
+			desc: "array index and wildcards",
+			path: "$.items[2].*[*]",
+			expected: []pathSegment{
+				{kind: memberSegment, name: "items"},
+				{kind: indexSegment, index: 2},
+				{kind: wildcardSegment},
+				{kind: wildcardSegment},
+			},
+		},
+		{
+			desc: "bracket containing a closing bracket",
+			path: "$['a]b']",
+			expected: []pathSegment{
+				{kind: memberSegment, name: "a]b"},
+			},
+		},
+		{
+			desc:    "missing root",
+			path:    "foo.bar",
+			wantErr: true,
+		},
+		{
+			desc:    "root only",
+			path:    "$",
+			wantErr: true,
+		},
+		{
+			desc:    "empty member name",
+			path:    "$.foo..bar",
+			wantErr: true,
+		},
+		{
+			desc:    "missing closing bracket",
+			path:    "$.foo[0",
+			wantErr: true,
+		},
+		{
+			desc:    "negative index",
+			path:    "$.foo[-1]",
+			wantErr: true,
+		},
+		{
+			desc:    "unexpected character",
+			path:    "$foo",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			segments, err := parseJSONPath(test.path)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, segments)
+		})
+	}
+}
+
+func TestRewriteJSON(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		operations [][3]string
+		data       string
+		expected   string
+		wantErr    bool
+	}{
+		{
+			desc:       "delete member",
+			operations: [][3]string{{opDelete, "$.internal", ""}},
+			data:       `{"id":1,"internal":{"host":"db.internal"}}`,
+			expected:   `{"id":1}`,
+		},
+		{
+			desc:       "delete nested members with wildcard",
+			operations: [][3]string{{opDelete, "$.items[*].secret", ""}},
+			data:       `{"items":[{"id":1,"secret":"a"},{"id":2,"secret":"b"},{"id":3}]}`,
+			expected:   `{"items":[{"id":1},{"id":2},{"id":3}]}`,
+		},
+		{
+			desc:       "delete array element",
+			operations: [][3]string{{opDelete, "$[1]", ""}},
+			data:       `["a","b","c"]`,
+			expected:   `["a","c"]`,
+		},
+		{
+			desc:       "delete all array elements",
+			operations: [][3]string{{opDelete, "$.items[*]", ""}},
+			data:       `{"items":[1,2]}`,
+			expected:   `{"items":[]}`,
+		},
+		{
+			desc:       "set existing member",
+			operations: [][3]string{{opSet, "$.user.email", `"redacted"`}},
+			data:       `{"user":{"email":"foo@bar.com","name":"foo"}}`,
+			expected:   `{"user":{"email":"redacted","name":"foo"}}`,
+		},
+		{
+			desc:       "set new member",
+			operations: [][3]string{{opSet, "$.meta", `{"source":"traefik"}`}},
+			data:       `{"id":1}`,
+			expected:   `{"id":1,"meta":{"source":"traefik"}}`,
+		},
+		{
+			desc:       "set does not create parents",
+			operations: [][3]string{{opSet, "$.foo.bar", `1`}},
+			data:       `{"id":1}`,
+			expected:   `{"id":1}`,
+		},
+		{
+			desc:       "set array elements",
+			operations: [][3]string{{opSet, "$.items[*].tags", `[]`}},
+			data:       `{"items":[{"tags":["a"]},{}]}`,
+			expected:   `{"items":[{"tags":[]},{"tags":[]}]}`,
+		},
+		{
+			desc:       "index out of range",
+			operations: [][3]string{{opDelete, "$[5]", ""}, {opSet, "$[6]", "1"}},
+			data:       `[1,2]`,
+			expected:   `[1,2]`,
+		},
+		{
+			desc: "multiple operations",
+			operations: [][3]string{
+				{opDelete, "$.a", ""},
+				{opSet, "$.b", `"<b>"`},
+			},
+			data:     `{"a":1,"b":2,"c":12345678901234567890}`,
+			expected: `{"b":"<b>","c":12345678901234567890}`,
+		},
+		{
+			desc:       "member order",
+			operations: [][3]string{{opDelete, "$.b", ""}, {opSet, "$.z.y", `1`}, {opSet, "$.a", `2`}},
+			data:       `{"z":{"y":0,"x":0},"b":1,"c":{"3":3,"1":1,"2":2}}`,
+			expected:   `{"z":{"y":1,"x":0},"c":{"3":3,"1":1,"2":2},"a":2}`,
+		},
+		{
+			desc:       "delete all object members",
+			operations: [][3]string{{opDelete, "$.user.*", ""}},
+			data:       `{"user":{"email":"foo@bar.com","name":"foo"},"id":1}`,
+			expected:   `{"user":{},"id":1}`,
+		},
+		{
+			desc:       "duplicated member",
+			operations: [][3]string{{opDelete, "$.b", ""}},
+			data:       `{"a":1,"b":2,"a":3}`,
+			expected:   `{"a":3}`,
+		},
+		{
+			desc:       "invalid JSON",
+			operations: [][3]string{{opDelete, "$.a", ""}},
+			data:       `{"a":1`,
+			wantErr:    true,
+		},
+		{
+			desc:       "several JSON values",
+			operations: [][3]string{{opDelete, "$.a", ""}},
+			data:       `{"a":1} {"a":2}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var operations []*jsonOperation
+			for _, op := range test.operations {
+				operation, err := newJSONOperation(op[0], op[1], op[2])
+				require.NoError(t, err)
+
+				operations = append(operations, operation)
+			}
+
+			data, err := rewriteJSON([]byte(test.data), operations)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(data))
+		})
+	}
+}
+
+func TestNewJSONOperation_invalid(t *testing.T) {
+	_, err := newJSONOperation("replace", "$.foo", "1")
+	assert.Error(t, err)
+
+	_, err = newJSONOperation(opSet, "$.foo", "{")
+	assert.Error(t, err)
+
+	_, err = newJSONOperation(opDelete, "foo", "")
+	assert.Error(t, err)
+}
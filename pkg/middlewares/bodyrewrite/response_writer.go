@@ -0,0 +1,221 @@
+package bodyrewrite
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"traefik/v3/pkg/middlewares/decompress"
+)
+
+type rewriteMode int
+
+const (
+	// passThrough forwards the response body as is.
+	passThrough rewriteMode = iota
+	// streaming rewrites the response body line by line, while it is written.
+	streaming
+	// buffering holds the whole response body, and decodes and rewrites it once complete.
+	// A body larger than the maximum size is forwarded as is.
+	buffering
+)
+
+// responseWriter rewrites the response body, according to its headers.
+type responseWriter struct {
+	rw           http.ResponseWriter
+	req          *http.Request
+	rules        *rules
+	maxBodyBytes int64
+	logger       *zerolog.Logger
+
+	wroteHeader bool
+	code        int
+	mode        rewriteMode
+	rewriter    *streamRewriter
+	buf         bytes.Buffer
+	isJSON      bool
+	// codings are the content codings of the buffered body.
+	codings []string
+}
+
+func (r *responseWriter) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *responseWriter) WriteHeader(code int) {
+	if r.wroteHeader {
+		return
+	}
+
+	// Informational responses are forwarded, and the final response is still to come.
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		r.rw.WriteHeader(code)
+		return
+	}
+
+	r.wroteHeader = true
+	r.code = code
+
+	rewrite, isJSON := r.rules.match(r.rw.Header())
+	rewrite = rewrite && r.rules.applies(isJSON)
+
+	// The responses without body are not rewritten,
+	// nor the partial ones, as the matches could span several parts,
+	// nor the ones compressed with an unsupported coding.
+	codings := decompress.ContentCodings(r.rw.Header())
+	switch {
+	case r.req.Method == http.MethodHead, code == http.StatusSwitchingProtocols, code == http.StatusNoContent,
+		code == http.StatusPartialContent, code == http.StatusNotModified, !decompress.Supported(codings):
+		rewrite = false
+	}
+
+	switch {
+	case rewrite && (len(codings) > 0 || isJSON && len(r.rules.jsonOperations) > 0):
+		// The compressed bodies are decoded, and the JSON bodies parsed, once complete.
+		// The header is written once the body is complete, with its new length.
+		r.mode = buffering
+		r.isJSON = isJSON
+		r.codings = codings
+		return
+
+	case rewrite:
+		r.mode = streaming
+		r.rewriter = newStreamRewriter(r.rules.replacements, r.maxBodyBytes)
+
+		// The length of the rewritten body is unknown until it is fully written,
+		// and the body can be modified by any of its lines.
+		r.rw.Header().Del("Content-Length")
+		dropValidators(r.rw.Header())
+	}
+
+	r.rw.WriteHeader(code)
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	switch r.mode {
+	case streaming:
+		if out := r.rewriter.process(p, false); len(out) > 0 {
+			if _, err := r.rw.Write(out); err != nil {
+				return 0, err
+			}
+		}
+
+		return len(p), nil
+
+	case buffering:
+		if int64(r.buf.Len()+len(p)) <= r.maxBodyBytes {
+			r.buf.Write(p)
+			return len(p), nil
+		}
+
+		r.logger.Debug().Msgf("Response body larger than %d bytes, forwarding it without rewriting it", r.maxBodyBytes)
+
+		// The body is too large to be rewritten, it is forwarded as is.
+		r.mode = passThrough
+		r.rw.WriteHeader(r.code)
+
+		if _, err := r.rw.Write(r.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		r.buf = bytes.Buffer{}
+
+		return r.rw.Write(p)
+
+	default:
+		return r.rw.Write(p)
+	}
+}
+
+// Flush sends any buffered data to the client.
+// The buffered response bodies are only sent once complete.
+func (r *responseWriter) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	switch r.mode {
+	case streaming:
+		if out := r.rewriter.process(nil, true); len(out) > 0 {
+			if _, err := r.rw.Write(out); err != nil {
+				r.logger.Debug().Err(err).Msg("Error while writing the rewritten response body")
+				return
+			}
+		}
+
+	case buffering:
+		return
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	return hijacker.Hijack()
+}
+
+// close writes the rest of the rewritten body.
+func (r *responseWriter) close() {
+	switch r.mode {
+	case streaming:
+		if out := r.rewriter.process(nil, true); len(out) > 0 {
+			if _, err := r.rw.Write(out); err != nil {
+				r.logger.Debug().Err(err).Msg("Error while writing the rewritten response body")
+			}
+		}
+
+	case buffering:
+		body, err := decompress.Decode(bytes.NewReader(r.buf.Bytes()), r.codings, r.maxBodyBytes)
+		if err != nil {
+			r.logger.Debug().Err(err).Msg("Unable to decode the response body, forwarding it without rewriting it")
+
+			r.rw.WriteHeader(r.code)
+			if _, err := r.rw.Write(r.buf.Bytes()); err != nil {
+				r.logger.Debug().Err(err).Msg("Error while writing the response body")
+			}
+			return
+		}
+
+		rewritten := r.rules.rewrite(body, r.isJSON, r.logger)
+		if len(r.codings) > 0 || !bytes.Equal(rewritten, body) {
+			dropValidators(r.rw.Header())
+		}
+		body = rewritten
+
+		// The rewritten body is sent uncompressed.
+		r.rw.Header().Del("Content-Encoding")
+		r.rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		r.rw.WriteHeader(r.code)
+
+		if _, err := r.rw.Write(body); err != nil {
+			r.logger.Debug().Err(err).Msg("Error while writing the rewritten response body")
+		}
+	}
+}
+
+// dropValidators updates the headers of a response whose body is modified:
+// the entity tag of the original body becomes weak, as the bytes differ,
+// and the range requests are no longer advertised, as the ranges of the original body do not match the modified one.
+func dropValidators(header http.Header) {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		header.Set("ETag", "W/"+etag)
+	}
+
+	header.Del("Accept-Ranges")
+}
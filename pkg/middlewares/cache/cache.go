@@ -0,0 +1,419 @@
+// Package cache implements a middleware caching the responses of the backends,
+// following the HTTP caching semantics of a shared cache (RFC 9111).
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/tracing"
+)
+
+const typeName = "Cache"
+
+// Cache statuses, reported in the status header and in the access log.
+const (
+	// statusHit means that the response has been served from the cache.
+	statusHit = "HIT"
+	// statusMiss means that the response has been served by the backend.
+	statusMiss = "MISS"
+	// statusStale means that a stale response has been served from the cache, while being revalidated in the background.
+	statusStale = "STALE"
+	// statusRevalidated means that the response has been served from the cache, after being validated by the backend.
+	statusRevalidated = "REVALIDATED"
+	// statusBypass means that the request cannot be served from the cache.
+	statusBypass = "BYPASS"
+)
+
+// stores holds the cache stores, keyed by middleware name and store configuration,
+// so that the cached responses are kept when the middlewares are rebuilt on configuration reload.
+// The stores no longer used by any middleware are released.
+var stores = middlewares.NewShared[store](nil)
+
+// cache serves the stored responses while they are fresh, and stores the cacheable responses of the backend.
+type cache struct {
+	name         string
+	next         http.Handler
+	store        store
+	maxBodyBytes int64
+	defaultTTL   time.Duration
+	statusHeader string
+
+	// revalidating holds the keys of the entries being revalidated in the background.
+	revalidating sync.Map
+}
+
+// New creates a cache middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Cache, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.MaxEntries <= 0 {
+		return nil, fmt.Errorf("maxEntries must be greater than zero: %d", config.MaxEntries)
+	}
+
+	if config.MaxBodyBytes < 0 {
+		return nil, fmt.Errorf("negative value not valid for maxBodyBytes: %d", config.MaxBodyBytes)
+	}
+
+	if config.Disk == nil && config.MaxMemory <= 0 {
+		return nil, fmt.Errorf("maxMemory must be greater than zero: %d", config.MaxMemory)
+	}
+
+	if config.DefaultTTL < 0 {
+		return nil, fmt.Errorf("negative value not valid for defaultTTL: %s", time.Duration(config.DefaultTTL))
+	}
+
+	st, err := getStore(logger.WithContext(ctx), name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache{
+		name:         name,
+		next:         next,
+		store:        st,
+		maxBodyBytes: config.MaxBodyBytes,
+		defaultTTL:   time.Duration(config.DefaultTTL),
+		statusHeader: config.StatusHeader,
+	}, nil
+}
+
+func (c *cache) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *cache) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		c.serveBypass(rw, req)
+		return
+	}
+
+	reqCC := parseCacheControl(req.Header)
+
+	// The range requests are not served from the cache, and the responses to the no-store requests are not stored.
+	if req.Header.Get("Range") != "" || reqCC.has("no-store") {
+		c.serveBypass(rw, req)
+		return
+	}
+
+	key := cacheKey(req)
+
+	e := c.lookup(req, key)
+	now := time.Now()
+
+	switch {
+	case e != nil && e.fresh(now) && acceptable(req, reqCC, e, now):
+		c.serveEntry(rw, req, e, statusHit, now)
+
+	case e != nil && !e.fresh(now) && e.staleWhileRevalidate(now) && acceptsStale(req, reqCC):
+		c.serveEntry(rw, req, e, statusStale, now)
+		c.revalidateInBackground(req, key, e)
+
+	case reqCC.has("only-if-cached"):
+		c.setCacheStatus(rw, req, statusMiss)
+		http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+
+	default:
+		c.fetch(rw, req, key, e)
+	}
+}
+
+// serveBypass forwards the request to the backend without using the cache.
+// The stored response of the target resource is invalidated by the successful unsafe requests (RFC 9111 section 4.4).
+func (c *cache) serveBypass(rw http.ResponseWriter, req *http.Request) {
+	c.setCacheStatus(nil, req, statusBypass)
+
+	recorder := newResponseRecorder(rw, c.statusHeader, statusBypass, 0)
+	c.next.ServeHTTP(recorder, req)
+
+	if !recorder.headersSent {
+		recorder.WriteHeader(recorder.code)
+	}
+
+	if !isUnsafe(req.Method) || recorder.code >= http.StatusBadRequest {
+		return
+	}
+
+	if err := c.store.Delete(cacheKey(req)); err != nil {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Error().Err(err).Msg("Could not invalidate cached response")
+	}
+}
+
+// fetch forwards the request to the backend, and stores the response if it is cacheable.
+// If a stale entry with validators is given, the request is made conditional,
+// and the entry is served if the backend responds that it has not been modified.
+func (c *cache) fetch(rw http.ResponseWriter, req *http.Request, key string, stale *entry) {
+	// The responses to the HEAD requests are not stored, they are only served from the stored GET responses.
+	if req.Method == http.MethodHead {
+		c.setCacheStatus(nil, req, statusMiss)
+
+		recorder := newResponseRecorder(rw, c.statusHeader, statusMiss, 0)
+		c.next.ServeHTTP(recorder, req)
+
+		if !recorder.headersSent {
+			recorder.WriteHeader(recorder.code)
+		}
+		return
+	}
+
+	outReq := req
+	recorder := newResponseRecorder(rw, c.statusHeader, statusMiss, c.maxBodyBytes)
+
+	if stale != nil && stale.hasValidators() {
+		outReq = conditionalRequest(req.Context(), req, stale)
+		recorder.holdNotModified = true
+	}
+
+	c.next.ServeHTTP(recorder, outReq)
+
+	// The headers set by a handler returning without writing anything are only sent, and recorded, with the status code.
+	if !recorder.headersSent {
+		recorder.WriteHeader(recorder.code)
+	}
+
+	if recorder.holdNotModified && recorder.code == http.StatusNotModified {
+		now := time.Now()
+		c.serveEntry(rw, req, c.refresh(req, key, stale, recorder.storedHeader, now), statusRevalidated, now)
+		return
+	}
+
+	c.setCacheStatus(nil, req, statusMiss)
+	c.update(req, key, recorder)
+}
+
+// revalidateInBackground revalidates the given stale entry, unless it is already being revalidated.
+func (c *cache) revalidateInBackground(req *http.Request, key string, stale *entry) {
+	if _, loaded := c.revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	// The revalidation must outlive the client request,
+	// and must not alter the access log data and the tracing span of the client request.
+	logger := log.Ctx(req.Context())
+	ctx := logger.WithContext(context.Background())
+
+	var outReq *http.Request
+	if stale.hasValidators() {
+		outReq = conditionalRequest(ctx, req, stale)
+	} else {
+		outReq = req.Clone(ctx)
+	}
+
+	// The body of the client request is closed once it is handled.
+	outReq.Body = http.NoBody
+	outReq.ContentLength = 0
+
+	go func() {
+		defer c.revalidating.Delete(key)
+
+		recorder := newResponseRecorder(nil, "", "", c.maxBodyBytes)
+		c.next.ServeHTTP(recorder, outReq)
+
+		if !recorder.headersSent {
+			recorder.WriteHeader(recorder.code)
+		}
+
+		if recorder.code == http.StatusNotModified {
+			c.refresh(outReq, key, stale, recorder.storedHeader, time.Now())
+			return
+		}
+
+		c.update(outReq, key, recorder)
+	}()
+}
+
+// lookup returns the stored entry matching the request, or nil if there is none.
+func (c *cache) lookup(req *http.Request, key string) *entry {
+	e, err := c.store.Get(key)
+	if err == nil && e != nil && e.isVariants() {
+		e, err = c.store.Get(variantKey(key, e.Vary, req.Header))
+	}
+
+	if err != nil {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Error().Err(err).Msg("Could not read cached response")
+		return nil
+	}
+
+	return e
+}
+
+// update stores the recorded response if it is cacheable, or invalidates the stored one otherwise.
+func (c *cache) update(req *http.Request, key string, recorder *responseRecorder) {
+	logger := middlewares.GetLogger(req.Context(), c.name, typeName)
+
+	if recorder.truncated || !storable(req, recorder.code, recorder.storedHeader) {
+		if err := c.store.Delete(key); err != nil {
+			logger.Error().Err(err).Msg("Could not invalidate cached response")
+		}
+		return
+	}
+
+	e := newEntry(recorder.code, recorder.storedHeader, recorder.body.Bytes(), c.defaultTTL, time.Now())
+
+	// The responses without explicit freshness and without validators are never reused.
+	if e.Lifetime == 0 && !e.hasValidators() {
+		return
+	}
+
+	if err := c.set(req, key, e); err != nil {
+		logger.Error().Err(err).Msg("Could not store response")
+	}
+}
+
+// refresh updates the stored entry with the header fields of the 304 (Not Modified) response (RFC 9111 section 4.3.4),
+// and returns the updated entry.
+func (c *cache) refresh(req *http.Request, key string, stale *entry, header http.Header, now time.Time) *entry {
+	updated := stale.Header.Clone()
+	for name, values := range header {
+		if name == "Content-Length" {
+			continue
+		}
+
+		updated[name] = values
+	}
+
+	e := newEntry(stale.StatusCode, updated, stale.Body, c.defaultTTL, now)
+
+	if err := c.set(req, key, e); err != nil {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Error().Err(err).Msg("Could not store response")
+	}
+
+	return e
+}
+
+// set stores the entry, and records the request headers selecting its variant if the response varies.
+func (c *cache) set(req *http.Request, key string, e *entry) error {
+	if len(e.Vary) == 0 {
+		return c.store.Set(key, e)
+	}
+
+	if err := c.store.Set(key, &entry{Vary: e.Vary, StoredAt: e.StoredAt}); err != nil {
+		return err
+	}
+
+	return c.store.Set(variantKey(key, e.Vary, req.Header), e)
+}
+
+// serveEntry writes the stored response, or a 304 (Not Modified) response if it matches the conditional request headers.
+func (c *cache) serveEntry(rw http.ResponseWriter, req *http.Request, e *entry, status string, now time.Time) {
+	for name, values := range e.Header {
+		rw.Header()[name] = append([]string(nil), values...)
+	}
+
+	rw.Header().Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	c.setCacheStatus(rw, req, status)
+
+	if notModified(req, e) {
+		rw.Header().Del("Content-Length")
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rw.WriteHeader(e.StatusCode)
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := rw.Write(e.Body); err != nil {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Debug().Err(err).Msg("Could not write cached response")
+	}
+}
+
+// setCacheStatus reports the cache status in the access log, and in the status header of the given response writer if any.
+func (c *cache) setCacheStatus(rw http.ResponseWriter, req *http.Request, status string) {
+	if rw != nil && c.statusHeader != "" {
+		rw.Header().Set(c.statusHeader, status)
+	}
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.Core[accesslog.CacheStatus] = status
+	}
+}
+
+// cacheKey returns the primary cache key of the request, i.e. its target URI.
+func cacheKey(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + req.Host + req.URL.RequestURI()
+}
+
+// variantKey returns the cache key of the variant of a response, selected by the given request headers.
+func variantKey(key string, vary []string, header http.Header) string {
+	values := make(map[string][]string, len(vary))
+	for _, name := range vary {
+		values[name] = header.Values(name)
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		// Cannot happen, a map of strings can always be marshaled.
+		panic(err)
+	}
+
+	return key + "\n" + string(data)
+}
+
+// conditionalRequest returns a copy of the request, validating the given stored response with its validators.
+func conditionalRequest(ctx context.Context, req *http.Request, e *entry) *http.Request {
+	outReq := req.Clone(ctx)
+
+	outReq.Header.Del("If-None-Match")
+	outReq.Header.Del("If-Modified-Since")
+
+	if etag := e.Header.Get("Etag"); etag != "" {
+		outReq.Header.Set("If-None-Match", etag)
+	}
+
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		outReq.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	return outReq
+}
+
+// isUnsafe reports whether the method is unsafe, i.e. whether it can modify the target resource.
+func isUnsafe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	default:
+		return true
+	}
+}
+
+func getStore(ctx context.Context, name string, config dynamic.Cache) (store, error) {
+	key := name + "\n" + strconv.Itoa(config.MaxEntries)
+	if config.Disk == nil {
+		key += "\n" + strconv.FormatInt(config.MaxMemory, 10)
+
+		return stores.Acquire(ctx, key, func() (store, error) {
+			return newMemoryStore(config.MaxEntries, config.MaxMemory)
+		})
+	}
+
+	data, err := json.Marshal(config.Disk)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling cache disk configuration: %w", err)
+	}
+
+	key += "\n" + string(data)
+
+	return stores.Acquire(ctx, key, func() (store, error) {
+		return newDiskStore(ctx, config.Disk.Path, config.MaxEntries)
+	})
+}
@@ -0,0 +1,466 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares/accesslog"
+)
+
+type step struct {
+	method         string
+	requestHeaders map[string]string
+
+	expectedStatus      int
+	expectedCacheStatus string
+	expectedBody        string
+	expectedCalls       int64
+}
+
+func TestCache_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  func(config *dynamic.Cache)
+		backend func(rw http.ResponseWriter, req *http.Request, call int64)
+		steps   []step
+	}{
+		{
+			desc: "fresh response",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 1},
+				{method: http.MethodHead, expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedCalls: 1},
+			},
+		},
+		{
+			desc: "response without body",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedCalls: 1},
+				{method: http.MethodPost, expectedStatus: http.StatusOK, expectedCacheStatus: statusBypass, expectedCalls: 2},
+			},
+		},
+		{
+			desc: "expires header",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				now := time.Now()
+				rw.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+				rw.Header().Set("Expires", now.Add(time.Minute).UTC().Format(http.TimeFormat))
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 1},
+			},
+		},
+		{
+			desc: "no-store response",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "no-store, max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "private response",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "private, max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "response without freshness information",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "default TTL",
+			config: func(config *dynamic.Cache) {
+				config.DefaultTTL = ptypes.Duration(time.Minute)
+			},
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 1},
+			},
+		},
+		{
+			desc: "response setting a cookie",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Set-Cookie", "session=foo")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "authorized request",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{requestHeaders: map[string]string{"Authorization": "Basic Zm9vOmJhcg=="}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{requestHeaders: map[string]string{"Authorization": "Basic Zm9vOmJhcg=="}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "authorized request with public response",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "public, max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{requestHeaders: map[string]string{"Authorization": "Basic Zm9vOmJhcg=="}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{requestHeaders: map[string]string{"Authorization": "Basic Zm9vOmJhcg=="}, expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 1},
+			},
+		},
+		{
+			desc: "response larger than max body bytes",
+			config: func(config *dynamic.Cache) {
+				config.MaxBodyBytes = 2
+			},
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "vary",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Vary", "Accept-Language")
+				_, _ = rw.Write([]byte(req.Header.Get("Accept-Language")))
+			},
+			steps: []step{
+				{requestHeaders: map[string]string{"Accept-Language": "fr"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "fr", expectedCalls: 1},
+				{requestHeaders: map[string]string{"Accept-Language": "en"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "en", expectedCalls: 2},
+				{requestHeaders: map[string]string{"Accept-Language": "fr"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "fr", expectedCalls: 2},
+				{requestHeaders: map[string]string{"Accept-Language": "en"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "en", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "vary star",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Vary", "*")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "revalidation with ETag",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "no-cache")
+				rw.Header().Set("Etag", `"v1"`)
+				if req.Header.Get("If-None-Match") == `"v1"` {
+					rw.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusRevalidated, expectedBody: "foo", expectedCalls: 2},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusRevalidated, expectedBody: "foo", expectedCalls: 3},
+			},
+		},
+		{
+			desc: "revalidation with Last-Modified",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				lastModified := "Mon, 02 Jan 2006 15:04:05 GMT"
+				rw.Header().Set("Cache-Control", "max-age=0")
+				rw.Header().Set("Last-Modified", lastModified)
+				if req.Header.Get("If-Modified-Since") == lastModified {
+					rw.Header().Set("Cache-Control", "max-age=60")
+					rw.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusRevalidated, expectedBody: "foo", expectedCalls: 2},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "revalidation with modified response",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "no-cache")
+				rw.Header().Set("Etag", `"v`+strings.Repeat("1", int(call))+`"`)
+				_, _ = rw.Write([]byte(strings.Repeat("1", int(call))))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "11", expectedCalls: 2},
+			},
+		},
+		{
+			desc: "conditional request served from the cache",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Etag", `W/"v1"`)
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{requestHeaders: map[string]string{"If-None-Match": `"v0", "v1"`}, expectedStatus: http.StatusNotModified, expectedCacheStatus: statusHit, expectedCalls: 1},
+				{requestHeaders: map[string]string{"If-None-Match": `"v0"`}, expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 1},
+			},
+		},
+		{
+			desc: "request directives",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			steps: []step{
+				{requestHeaders: map[string]string{"Cache-Control": "only-if-cached"}, expectedStatus: http.StatusGatewayTimeout, expectedCacheStatus: statusMiss, expectedBody: "Gateway Timeout\n"},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 1},
+				{requestHeaders: map[string]string{"Cache-Control": "only-if-cached"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 1},
+				{requestHeaders: map[string]string{"Cache-Control": "no-cache"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 2},
+				{requestHeaders: map[string]string{"Pragma": "no-cache"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 3},
+				{requestHeaders: map[string]string{"Cache-Control": "min-fresh=120"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: "foo", expectedCalls: 4},
+				{requestHeaders: map[string]string{"Cache-Control": "no-store"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusBypass, expectedBody: "foo", expectedCalls: 5},
+				{requestHeaders: map[string]string{"Range": "bytes=0-1"}, expectedStatus: http.StatusOK, expectedCacheStatus: statusBypass, expectedBody: "foo", expectedCalls: 6},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: "foo", expectedCalls: 6},
+			},
+		},
+		{
+			desc: "invalidation by unsafe request",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte(req.Method))
+			},
+			steps: []step{
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: http.MethodGet, expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusHit, expectedBody: http.MethodGet, expectedCalls: 1},
+				{method: http.MethodPost, expectedStatus: http.StatusOK, expectedCacheStatus: statusBypass, expectedBody: http.MethodPost, expectedCalls: 2},
+				{expectedStatus: http.StatusOK, expectedCacheStatus: statusMiss, expectedBody: http.MethodGet, expectedCalls: 3},
+			},
+		},
+		{
+			desc: "not found response",
+			config: func(config *dynamic.Cache) {
+				config.StatusHeader = "X-Cache"
+			},
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				http.NotFound(rw, req)
+			},
+			steps: []step{
+				{expectedStatus: http.StatusNotFound, expectedCacheStatus: statusMiss, expectedBody: "404 page not found\n", expectedCalls: 1},
+				{expectedStatus: http.StatusNotFound, expectedCacheStatus: statusHit, expectedBody: "404 page not found\n", expectedCalls: 1},
+			},
+		},
+		{
+			desc: "server error response",
+			backend: func(rw http.ResponseWriter, req *http.Request, call int64) {
+				rw.WriteHeader(http.StatusInternalServerError)
+			},
+			steps: []step{
+				{expectedStatus: http.StatusInternalServerError, expectedCacheStatus: statusMiss, expectedCalls: 1},
+				{expectedStatus: http.StatusInternalServerError, expectedCacheStatus: statusMiss, expectedCalls: 2},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int64
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				test.backend(rw, req, calls.Add(1))
+			})
+
+			config := dynamic.Cache{}
+			config.SetDefaults()
+			if test.config != nil {
+				test.config(&config)
+			}
+
+			handler, err := New(context.Background(), next, config, t.Name())
+			require.NoError(t, err)
+
+			for i, step := range test.steps {
+				method := step.method
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+
+				req := httptest.NewRequest(method, "http://foo.bar/baz?qux=1", nil)
+				req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+				for name, value := range step.requestHeaders {
+					req.Header.Set(name, value)
+				}
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				assert.Equal(t, step.expectedStatus, recorder.Code, "step %d", i)
+				assert.Equal(t, step.expectedCacheStatus, recorder.Header().Get(config.StatusHeader), "step %d", i)
+				assert.Equal(t, step.expectedCacheStatus, logData.Core[accesslog.CacheStatus], "step %d", i)
+				assert.Equal(t, step.expectedBody, recorder.Body.String(), "step %d", i)
+				assert.Equal(t, step.expectedCalls, calls.Load(), "step %d", i)
+			}
+		})
+	}
+}
+
+func TestCache_ServeHTTP_staleWhileRevalidate(t *testing.T) {
+	var calls atomic.Int64
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		call := calls.Add(1)
+
+		rw.Header().Set("Cache-Control", "max-age=0, stale-while-revalidate=60")
+		rw.Header().Set("Etag", `"v1"`)
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			rw.Header().Set("Cache-Control", "max-age=60")
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		_, _ = rw.Write([]byte{byte('0' + call)})
+	})
+
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	handler, err := New(context.Background(), next, config, t.Name())
+	require.NoError(t, err)
+
+	serve := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil))
+		return recorder
+	}
+
+	recorder := serve()
+	assert.Equal(t, statusMiss, recorder.Header().Get(config.StatusHeader))
+	assert.Equal(t, "1", recorder.Body.String())
+
+	recorder = serve()
+	assert.Equal(t, statusStale, recorder.Header().Get(config.StatusHeader))
+	assert.Equal(t, "1", recorder.Body.String())
+
+	// The stale response is revalidated in the background, and is then fresh for a minute.
+	assert.Eventually(t, func() bool {
+		return serve().Header().Get(config.StatusHeader) == statusHit
+	}, 5*time.Second, 10*time.Millisecond)
+
+	recorder = serve()
+	assert.Equal(t, "1", recorder.Body.String())
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestCache_ServeHTTP_age(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Cache-Control", "max-age=60")
+		rw.Header().Set("Age", "30")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	handler, err := New(context.Background(), next, config, t.Name())
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil))
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil)
+	req.Header.Set("Cache-Control", "max-age=20")
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, statusMiss, recorder.Header().Get(config.StatusHeader))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil))
+	assert.Equal(t, statusHit, recorder.Header().Get(config.StatusHeader))
+	assert.Equal(t, "30", recorder.Header().Get("Age"))
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.Cache
+	}{
+		{
+			desc:   "no max entries",
+			config: dynamic.Cache{MaxBodyBytes: 1},
+		},
+		{
+			desc:   "negative max body bytes",
+			config: dynamic.Cache{MaxEntries: 1, MaxBodyBytes: -1, MaxMemory: 1},
+		},
+		{
+			desc:   "no max memory",
+			config: dynamic.Cache{MaxEntries: 1},
+		},
+		{
+			desc:   "negative default TTL",
+			config: dynamic.Cache{MaxEntries: 1, MaxMemory: 1, DefaultTTL: -1},
+		},
+		{
+			desc:   "empty disk path",
+			config: dynamic.Cache{MaxEntries: 1, Disk: &dynamic.CacheDisk{}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, t.Name())
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDeltaSeconds is the greatest delta-seconds value, the larger ones are capped to it (RFC 9111 section 1.2.2).
+const maxDeltaSeconds = 2147483648
+
+// heuristicallyCacheable holds the status codes of the responses which can be stored
+// without explicit freshness information (RFC 9110 section 15.1).
+// The partial contents are not stored, as the range requests are not served from the cache.
+var heuristicallyCacheable = map[int]struct{}{
+	http.StatusOK:                   {},
+	http.StatusNonAuthoritativeInfo: {},
+	http.StatusNoContent:            {},
+	http.StatusMultipleChoices:      {},
+	http.StatusMovedPermanently:     {},
+	http.StatusPermanentRedirect:    {},
+	http.StatusNotFound:             {},
+	http.StatusMethodNotAllowed:     {},
+	http.StatusGone:                 {},
+	http.StatusRequestURITooLong:    {},
+	http.StatusNotImplemented:       {},
+}
+
+// cacheControl holds the directives of the Cache-Control header fields, keyed by lower-case name.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(directive, "=")
+
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+
+			cc[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// duration returns the delta-seconds argument of the given directive,
+// and whether the directive is present with a valid argument.
+func (cc cacheControl) duration(name string) (time.Duration, bool) {
+	arg, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	if seconds > maxDeltaSeconds {
+		seconds = maxDeltaSeconds
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// noCache reports whether the request requires the stored responses to be validated before being used,
+// with the Cache-Control or the legacy Pragma header.
+func noCache(req *http.Request, reqCC cacheControl) bool {
+	if reqCC.has("no-cache") {
+		return true
+	}
+
+	return len(reqCC) == 0 && strings.Contains(strings.ToLower(req.Header.Get("Pragma")), "no-cache")
+}
+
+// storable reports whether the response to the given request can be stored (RFC 9111 section 3).
+// The responses setting cookies are never stored, as they are most likely specific to a client.
+func storable(req *http.Request, statusCode int, header http.Header) bool {
+	cc := parseCacheControl(header)
+	if cc.has("no-store") || cc.has("private") {
+		return false
+	}
+
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	// A Vary header listing * means that the response cannot be reused for another request.
+	if vary := varyHeaders(header); len(vary) == 1 && vary[0] == "*" {
+		return false
+	}
+
+	if req.Header.Get("Authorization") != "" && !cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate") {
+		return false
+	}
+
+	if _, ok := heuristicallyCacheable[statusCode]; ok {
+		return true
+	}
+
+	if statusCode < http.StatusOK || statusCode == http.StatusPartialContent || statusCode == http.StatusNotModified {
+		return false
+	}
+
+	return cc.has("public") || cc.has("max-age") || cc.has("s-maxage") || header.Get("Expires") != ""
+}
+
+// varyHeaders returns the canonical names of the request headers listed in the Vary header of the given response,
+// sorted so that the equivalent Vary headers select the variants in the same way.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return []string{"*"}
+			}
+
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// newEntry creates the entry storing the given response,
+// with its freshness lifetime computed as a shared cache (RFC 9111 section 4.2).
+func newEntry(statusCode int, header http.Header, body []byte, defaultTTL time.Duration, responseTime time.Time) *entry {
+	cc := parseCacheControl(header)
+
+	date := responseTime
+	if d, err := http.ParseTime(header.Get("Date")); err == nil {
+		date = d
+	}
+
+	e := &entry{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       body,
+		Vary:       varyHeaders(header),
+		StoredAt:   responseTime,
+		InitialAge: initialAge(header, date, responseTime),
+		// The s-maxage directive incorporates the semantics of the proxy-revalidate directive.
+		MustRevalidate: cc.has("no-cache") || cc.has("must-revalidate") || cc.has("proxy-revalidate") || cc.has("s-maxage"),
+	}
+
+	if !e.MustRevalidate {
+		e.StaleWhileRevalidate, _ = cc.duration("stale-while-revalidate")
+	}
+
+	switch lifetime, ok := cc.duration("s-maxage"); {
+	case cc.has("no-cache"):
+		e.Lifetime = 0
+	case ok:
+		e.Lifetime = lifetime
+	default:
+		e.Lifetime = freshnessLifetime(statusCode, header, cc, date, defaultTTL)
+	}
+
+	return e
+}
+
+// freshnessLifetime returns the freshness lifetime of a response without s-maxage directive.
+func freshnessLifetime(statusCode int, header http.Header, cc cacheControl, date time.Time, defaultTTL time.Duration) time.Duration {
+	if lifetime, ok := cc.duration("max-age"); ok {
+		return lifetime
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		// An invalid Expires header, such as 0, represents a time in the past.
+		t, err := http.ParseTime(expires)
+		if err != nil || t.Before(date) {
+			return 0
+		}
+
+		return t.Sub(date)
+	}
+
+	if _, ok := heuristicallyCacheable[statusCode]; ok {
+		return defaultTTL
+	}
+
+	return 0
+}
+
+// initialAge returns the age of the response when it is received (RFC 9111 section 4.2.3).
+func initialAge(header http.Header, date, responseTime time.Time) time.Duration {
+	var apparentAge time.Duration
+	if responseTime.After(date) {
+		apparentAge = responseTime.Sub(date)
+	}
+
+	seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64)
+	if err != nil || seconds < 0 {
+		return apparentAge
+	}
+
+	if seconds > maxDeltaSeconds {
+		seconds = maxDeltaSeconds
+	}
+
+	if ageValue := time.Duration(seconds) * time.Second; ageValue > apparentAge {
+		return ageValue
+	}
+
+	return apparentAge
+}
+
+// acceptable reports whether the request accepts the given fresh entry, without validation (RFC 9111 section 5.2.1).
+func acceptable(req *http.Request, reqCC cacheControl, e *entry, now time.Time) bool {
+	if noCache(req, reqCC) {
+		return false
+	}
+
+	age := e.age(now)
+
+	if maxAge, ok := reqCC.duration("max-age"); ok && age > maxAge {
+		return false
+	}
+
+	if minFresh, ok := reqCC.duration("min-fresh"); ok && e.Lifetime-age < minFresh {
+		return false
+	}
+
+	return true
+}
+
+// acceptsStale reports whether the request accepts a stale entry while it is revalidated in the background.
+func acceptsStale(req *http.Request, reqCC cacheControl) bool {
+	return !noCache(req, reqCC) && !reqCC.has("max-age") && !reqCC.has("min-fresh")
+}
+
+// notModified reports whether the conditional headers of the request match the given stored response,
+// in which case a 304 (Not Modified) response is sent instead (RFC 9110 section 13.2.2).
+func notModified(req *http.Request, e *entry) bool {
+	if e.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(e.Header.Get("Etag"), "W/")
+		if etag == "" {
+			return false
+		}
+
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(e.Header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(ims)
+}
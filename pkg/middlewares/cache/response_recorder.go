@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// responseRecorder forwards the response to the client without buffering,
+// while keeping a copy of it up to the maximum body size, so that it can be stored once entirely written.
+// Without response writer, as for the background revalidations, the response is only recorded.
+type responseRecorder struct {
+	rw           http.ResponseWriter
+	statusHeader string
+	cacheStatus  string
+
+	// holdNotModified prevents a 304 (Not Modified) response to a revalidation request from being forwarded,
+	// so that the stored response can be served instead.
+	holdNotModified bool
+
+	header       http.Header
+	storedHeader http.Header
+	code         int
+	headersSent  bool
+	forwarded    bool
+
+	body         bytes.Buffer
+	maxBodyBytes int64
+	truncated    bool
+}
+
+func newResponseRecorder(rw http.ResponseWriter, statusHeader, cacheStatus string, maxBodyBytes int64) *responseRecorder {
+	return &responseRecorder{
+		rw:           rw,
+		statusHeader: statusHeader,
+		cacheStatus:  cacheStatus,
+		header:       make(http.Header),
+		code:         http.StatusOK, // If backend does not call WriteHeader on us, we consider it's a 200.
+		maxBodyBytes: maxBodyBytes,
+	}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	if r.forwarded {
+		return r.rw.Header()
+	}
+
+	return r.header
+}
+
+// WriteHeader is, in the specific case of 1xx status codes, a direct call to the wrapped ResponseWriter, without marking headers as sent,
+// allowing so further calls.
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.headersSent {
+		return
+	}
+
+	// Handling informational headers.
+	if code >= 100 && code <= 199 {
+		if r.rw == nil {
+			return
+		}
+
+		// Multiple informational status codes can be used,
+		// so here the copy is not appending the values to not repeat them.
+		for k, v := range r.header {
+			r.rw.Header()[k] = v
+		}
+
+		r.rw.WriteHeader(code)
+		return
+	}
+
+	r.code = code
+	r.headersSent = true
+	r.storedHeader = r.header.Clone()
+
+	if r.rw == nil || r.holdNotModified && code == http.StatusNotModified {
+		return
+	}
+
+	// The copy is not appending the values,
+	// to not repeat them in case any informational status code has been written.
+	for k, v := range r.header {
+		r.rw.Header()[k] = v
+	}
+
+	if r.statusHeader != "" {
+		r.rw.Header().Set(r.statusHeader, r.cacheStatus)
+	}
+
+	r.rw.WriteHeader(code)
+	r.forwarded = true
+}
+
+func (r *responseRecorder) Write(buf []byte) (int, error) {
+	// If WriteHeader was already called from the caller, this is a NOOP.
+	// Otherwise, r.code is actually a 200 here.
+	r.WriteHeader(r.code)
+
+	if !r.truncated {
+		if int64(r.body.Len()+len(buf)) > r.maxBodyBytes {
+			// The response is too large to be stored, there is no need to keep a copy of it anymore.
+			r.truncated = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(buf)
+		}
+	}
+
+	if !r.forwarded {
+		return len(buf), nil
+	}
+
+	return r.rw.Write(buf)
+}
+
+// Hijack hijacks the connection.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := r.rw.(http.Hijacker); ok {
+		// The response of a hijacked connection is not known, so it cannot be stored,
+		// and no header must be written on the connection anymore.
+		r.truncated = true
+		r.headersSent = true
+		return hj.Hijack()
+	}
+
+	return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	// If WriteHeader was already called from the caller, this is a NOOP.
+	// Otherwise, r.code is actually a 200 here.
+	r.WriteHeader(r.code)
+
+	if !r.forwarded {
+		return
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,342 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/rs/zerolog/log"
+)
+
+// entry is a stored response, with its caching policy.
+// An entry without status code only records the request headers selecting the variants of a response,
+// which are stored as their own entries.
+type entry struct {
+	StatusCode int         `json:"statusCode,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+
+	// Vary holds the canonical names of the request headers selecting the variant of the response.
+	Vary []string `json:"vary,omitempty"`
+
+	StoredAt             time.Time     `json:"storedAt"`
+	InitialAge           time.Duration `json:"initialAge,omitempty"`
+	Lifetime             time.Duration `json:"lifetime,omitempty"`
+	StaleWhileRevalidate time.Duration `json:"staleWhileRevalidate,omitempty"`
+	MustRevalidate       bool          `json:"mustRevalidate,omitempty"`
+}
+
+// isVariants reports whether the entry only records the request headers selecting the variants of a response.
+func (e *entry) isVariants() bool {
+	return e.StatusCode == 0
+}
+
+// age returns the current age of the stored response.
+func (e *entry) age(now time.Time) time.Duration {
+	return e.InitialAge + now.Sub(e.StoredAt)
+}
+
+// fresh reports whether the stored response can be used without validation.
+func (e *entry) fresh(now time.Time) bool {
+	return e.age(now) < e.Lifetime
+}
+
+// staleWhileRevalidate reports whether the stale response can be used while it is revalidated in the background.
+func (e *entry) staleWhileRevalidate(now time.Time) bool {
+	return e.StaleWhileRevalidate > 0 && e.age(now) < e.Lifetime+e.StaleWhileRevalidate
+}
+
+// size returns an estimate of the memory used by the entry.
+func (e *entry) size() int64 {
+	size := int64(len(e.Body))
+	for name, values := range e.Header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+	for _, name := range e.Vary {
+		size += int64(len(name))
+	}
+
+	return size
+}
+
+// hasValidators reports whether the stored response can be revalidated with a conditional request.
+func (e *entry) hasValidators() bool {
+	return e.Header.Get("Etag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// store holds the cached responses, keyed by cache key.
+type store interface {
+	// Get returns the entry of the given key, or nil if there is none.
+	Get(key string) (*entry, error)
+	// Set stores the entry of the given key.
+	Set(key string, e *entry) error
+	// Delete deletes the entry of the given key, if any.
+	Delete(key string) error
+}
+
+// memoryStore stores the entries in memory.
+// The least recently used entries are evicted once the maximum number of entries,
+// or the maximum size of the entries, is reached.
+type memoryStore struct {
+	maxMemory int64
+
+	// mu serializes the updates of the entries, and guards their size,
+	// which is updated by the evictions of the entries.
+	mu      sync.Mutex
+	size    int64
+	entries *lru.Cache
+}
+
+// memoryEntry is an entry of a memoryStore, with its size.
+type memoryEntry struct {
+	entry *entry
+	size  int64
+}
+
+func newMemoryStore(maxEntries int, maxMemory int64) (*memoryStore, error) {
+	m := &memoryStore{maxMemory: maxMemory}
+
+	var err error
+	m.entries, err = lru.NewWithEvict(maxEntries, func(_, value interface{}) {
+		m.size -= value.(memoryEntry).size
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *memoryStore) Get(key string) (*entry, error) {
+	value, ok := m.entries.Get(key)
+	if !ok {
+		return nil, nil
+	}
+
+	return value.(memoryEntry).entry, nil
+}
+
+func (m *memoryStore) Set(key string, e *entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	size := int64(len(key)) + e.size()
+	if size > m.maxMemory {
+		// The entry cannot be stored, the previous one is stale.
+		m.entries.Remove(key)
+		return nil
+	}
+
+	// The replaced entries are not evicted.
+	if previous, ok := m.entries.Peek(key); ok {
+		m.size -= previous.(memoryEntry).size
+	}
+
+	m.entries.Add(key, memoryEntry{entry: e, size: size})
+	m.size += size
+
+	for m.size > m.maxMemory {
+		m.entries.RemoveOldest()
+	}
+
+	return nil
+}
+
+func (m *memoryStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries.Remove(key)
+	return nil
+}
+
+// diskFileContent is the content of a file of a diskStore.
+type diskFileContent struct {
+	Key   string `json:"key"`
+	Entry *entry `json:"entry"`
+}
+
+// diskStore stores each entry in its own file, so that the entries survive restarts.
+// Only the index of the entries is kept in memory,
+// and the files of the least recently used entries are removed once the maximum number of entries is reached.
+type diskStore struct {
+	ctx  context.Context
+	path string
+
+	// index holds the file names, keyed by cache key.
+	index *lru.Cache
+}
+
+func newDiskStore(ctx context.Context, path string, maxEntries int) (*diskStore, error) {
+	if path == "" {
+		return nil, errors.New("cache disk path is empty")
+	}
+
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	s := &diskStore{ctx: ctx, path: path}
+
+	var err error
+	s.index, err = lru.NewWithEvict(maxEntries, func(_, value interface{}) {
+		s.remove(value.(string))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("loading cache directory %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// load indexes the entries of the directory, from the least to the most recently stored.
+func (s *diskStore) load() error {
+	dirEntries, err := os.ReadDir(s.path)
+	if err != nil {
+		return err
+	}
+
+	type indexed struct {
+		key      string
+		name     string
+		storedAt time.Time
+	}
+
+	var files []indexed
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+
+		switch {
+		case dirEntry.IsDir():
+			continue
+		case strings.HasSuffix(name, ".tmp"):
+			// Leftover of an interrupted write.
+			s.remove(name)
+			continue
+		case !strings.HasSuffix(name, ".json"):
+			continue
+		}
+
+		content, err := s.read(name)
+		if err != nil {
+			log.Ctx(s.ctx).Warn().Err(err).Str("file", name).Msg("Removing invalid cache file")
+			s.remove(name)
+			continue
+		}
+
+		files = append(files, indexed{key: content.Key, name: name, storedAt: content.Entry.StoredAt})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].storedAt.Before(files[j].storedAt)
+	})
+
+	for _, file := range files {
+		s.index.Add(file.key, file.name)
+	}
+
+	return nil
+}
+
+func (s *diskStore) Get(key string) (*entry, error) {
+	value, ok := s.index.Get(key)
+	if !ok {
+		return nil, nil
+	}
+
+	content, err := s.read(value.(string))
+	if errors.Is(err, fs.ErrNotExist) {
+		s.index.Remove(key)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return content.Entry, nil
+}
+
+func (s *diskStore) Set(key string, e *entry) error {
+	data, err := json.Marshal(diskFileContent{Key: key, Entry: e})
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(hash[:]) + ".json"
+
+	// The file is replaced atomically, so that it is never read partially written.
+	tmp, err := os.CreateTemp(s.path, "*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.path, name)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	// The key is only indexed once the file is written, so that it is never read before.
+	s.index.Add(key, name)
+
+	return nil
+}
+
+func (s *diskStore) Delete(key string) error {
+	s.index.Remove(key)
+	return nil
+}
+
+func (s *diskStore) read(name string) (*diskFileContent, error) {
+	data, err := os.ReadFile(filepath.Join(s.path, name))
+	if err != nil {
+		return nil, err
+	}
+
+	var content diskFileContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("decoding cache file %s: %w", name, err)
+	}
+
+	if content.Entry == nil {
+		return nil, fmt.Errorf("cache file %s has no entry", name)
+	}
+
+	return &content, nil
+}
+
+func (s *diskStore) remove(name string) {
+	if err := os.Remove(filepath.Join(s.path, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Ctx(s.ctx).Error().Err(err).Str("file", name).Msg("Could not remove cache file")
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestMemoryStore(t *testing.T) {
+	st, err := newMemoryStore(2, 1024)
+	require.NoError(t, err)
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, st.Set(key, &entry{StatusCode: http.StatusOK, Body: []byte(key)}))
+	}
+
+	e, err := st.Get("a")
+	require.NoError(t, err)
+	assert.Nil(t, e, "the least recently used entry must be evicted")
+
+	e, err = st.Get("c")
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.Equal(t, "c", string(e.Body))
+
+	require.NoError(t, st.Delete("c"))
+
+	e, err = st.Get("c")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+}
+
+func TestMemoryStore_maxMemory(t *testing.T) {
+	st, err := newMemoryStore(10, 10)
+	require.NoError(t, err)
+
+	// Each entry uses 5 bytes: 1 for the key, and 4 for the body.
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, st.Set(key, &entry{StatusCode: http.StatusOK, Body: []byte(key + "bod")}))
+	}
+
+	e, err := st.Get("a")
+	require.NoError(t, err)
+	assert.Nil(t, e, "the least recently used entry must be evicted")
+
+	e, err = st.Get("b")
+	require.NoError(t, err)
+	assert.NotNil(t, e)
+
+	// The replaced entry is accounted once.
+	require.NoError(t, st.Set("c", &entry{StatusCode: http.StatusOK, Body: []byte("c")}))
+	assert.Equal(t, int64(7), st.size)
+
+	// The entries larger than the maximum are not stored, and replace the previous one.
+	require.NoError(t, st.Set("b", &entry{StatusCode: http.StatusOK, Body: []byte("too large body")}))
+
+	e, err = st.Get("b")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	e, err = st.Get("c")
+	require.NoError(t, err)
+	assert.NotNil(t, e)
+	assert.Equal(t, int64(2), st.size)
+}
+
+func TestGetStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	st, err := getStore(ctx, t.Name(), config)
+	require.NoError(t, err)
+
+	same, err := getStore(ctx, t.Name(), config)
+	require.NoError(t, err)
+	assert.Same(t, st, same)
+
+	config.MaxMemory = 1024
+
+	other, err := getStore(ctx, t.Name(), config)
+	require.NoError(t, err)
+	assert.NotSame(t, st, other)
+}
+
+func TestDiskStore(t *testing.T) {
+	dir := t.TempDir()
+
+	st, err := newDiskStore(context.Background(), dir, 2)
+	require.NoError(t, err)
+
+	storedAt := time.Now().Add(-time.Minute)
+	for i, key := range []string{"a", "b", "c"} {
+		e := &entry{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Etag": []string{`"` + key + `"`}},
+			Body:       []byte(key),
+			StoredAt:   storedAt.Add(time.Duration(i) * time.Second),
+			Lifetime:   time.Hour,
+		}
+		require.NoError(t, st.Set(key, e))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "the file of the least recently used entry must be removed")
+
+	e, err := st.Get("a")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	// The entries are reloaded from the directory.
+	st, err = newDiskStore(context.Background(), dir, 2)
+	require.NoError(t, err)
+
+	e, err = st.Get("b")
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.Equal(t, "b", string(e.Body))
+	assert.Equal(t, `"b"`, e.Header.Get("Etag"))
+	assert.Equal(t, time.Hour, e.Lifetime)
+	assert.True(t, e.fresh(time.Now()))
+
+	require.NoError(t, st.Delete("b"))
+
+	e, err = st.Get("b")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	files, err = filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestDiskStore_invalidFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.json"), []byte("{"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "123.tmp"), []byte("{"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other"), []byte("foo"), 0o600))
+
+	_, err := newDiskStore(context.Background(), dir, 2)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "other")}, files)
+}
@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/klauspost/compress/gzhttp"
+	"github.com/klauspost/compress/gzip"
 	"github.com/opentracing/opentracing-go/ext"
 	"traefik/v3/pkg/config/dynamic"
 	"traefik/v3/pkg/middlewares"
-	"traefik/v3/pkg/middlewares/compress/brotli"
 	"traefik/v3/pkg/tracing"
 )
 
@@ -21,6 +22,18 @@ const typeName = "Compress"
 // See https://github.com/klauspost/compress/blob/9559b037e79ad673c71f6ef7c732c00949014cd2/gzhttp/compress.go#L47.
 const DefaultMinSize = 1024
 
+const (
+	zstdName   = "zstd"
+	brotliName = "br"
+	gzipName   = "gzip"
+
+	// maxZstdLevel is the highest compression level of the zstd algorithm.
+	maxZstdLevel = 22
+)
+
+// defaultEncodings is the default list of supported encodings, in the order of preference.
+var defaultEncodings = []string{brotliName, gzipName, zstdName}
+
 // Compress is a middleware that allows to compress the response.
 type compress struct {
 	next     http.Handler
@@ -28,8 +41,9 @@ type compress struct {
 	excludes []string
 	minSize  int
 
-	brotliHandler http.Handler
-	gzipHandler   http.Handler
+	// encodings is the list of supported encodings, in the order of preference.
+	encodings []string
+	handlers  map[string]http.Handler
 }
 
 // New creates a new compress middleware.
@@ -51,22 +65,48 @@ func New(ctx context.Context, next http.Handler, conf dynamic.Compress, name str
 		minSize = conf.MinResponseBodyBytes
 	}
 
+	encodings := defaultEncodings
+	if len(conf.Encodings) > 0 {
+		encodings = conf.Encodings
+	}
+
+	var levels dynamic.CompressLevels
+	if conf.Levels != nil {
+		levels = *conf.Levels
+	}
+
 	c := &compress{
 		next:     next,
 		name:     name,
 		excludes: excludes,
 		minSize:  minSize,
+		handlers: make(map[string]http.Handler),
 	}
 
-	var err error
-	c.brotliHandler, err = c.newBrotliHandler()
-	if err != nil {
-		return nil, err
-	}
+	for _, encoding := range encodings {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if _, ok := c.handlers[encoding]; ok {
+			return nil, fmt.Errorf("duplicated encoding %q", encoding)
+		}
 
-	c.gzipHandler, err = c.newGzipHandler()
-	if err != nil {
-		return nil, err
+		var handler http.Handler
+		var err error
+		switch encoding {
+		case zstdName:
+			handler, err = c.newCompressionHandler(zstdName, levels.Zstd)
+		case brotliName:
+			handler, err = c.newCompressionHandler(brotliName, levels.Brotli)
+		case gzipName:
+			handler, err = c.newGzipHandler(levels.Gzip)
+		default:
+			err = fmt.Errorf("unsupported encoding %q", encoding)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		c.encodings = append(c.encodings, encoding)
+		c.handlers[encoding] = handler
 	}
 
 	return c, nil
@@ -92,35 +132,51 @@ func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Client allows us to do whatever we want, so we br compress.
+	// Client allows us to do whatever we want, so we compress with the preferred encoding.
 	// See https://www.rfc-editor.org/rfc/rfc9110.html#section-12.5.3
 	acceptEncoding, ok := req.Header["Accept-Encoding"]
 	if !ok {
-		c.brotliHandler.ServeHTTP(rw, req)
+		c.serveEncoding(rw, req, c.encodings[0])
 		return
 	}
 
-	if encodingAccepts(acceptEncoding, "br") {
-		c.brotliHandler.ServeHTTP(rw, req)
+	encoding := negotiateEncoding(acceptEncoding, c.encodings)
+	if encoding == "" {
+		c.next.ServeHTTP(rw, req)
 		return
 	}
 
-	if encodingAccepts(acceptEncoding, "gzip") {
-		c.gzipHandler.ServeHTTP(rw, req)
-		return
+	c.serveEncoding(rw, req, encoding)
+}
+
+func (c *compress) serveEncoding(rw http.ResponseWriter, req *http.Request, encoding string) {
+	// The gzip handler only compresses the requests explicitly accepting gzip,
+	// whereas it can also be selected when any encoding is accepted.
+	if encoding == gzipName && !encodingAccepts(req.Header.Values("Accept-Encoding"), gzipName) {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", gzipName)
 	}
 
-	c.next.ServeHTTP(rw, req)
+	c.handlers[encoding].ServeHTTP(rw, req)
 }
 
 func (c *compress) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return c.name, tracing.SpanKindNoneEnum
 }
 
-func (c *compress) newGzipHandler() (http.Handler, error) {
+func (c *compress) newGzipHandler(level int) (http.Handler, error) {
+	if level < 0 || level > gzip.BestCompression {
+		return nil, fmt.Errorf("invalid gzip compression level %d, must be between %d and %d", level, gzip.BestSpeed, gzip.BestCompression)
+	}
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
 	wrapper, err := gzhttp.NewWrapper(
 		gzhttp.ExceptContentTypes(c.excludes),
 		gzhttp.MinSize(c.minSize),
+		gzhttp.CompressionLevel(level),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("new gzip wrapper: %w", err)
@@ -129,34 +185,96 @@ func (c *compress) newGzipHandler() (http.Handler, error) {
 	return wrapper(c.next), nil
 }
 
-func (c *compress) newBrotliHandler() (http.Handler, error) {
-	cfg := brotli.Config{
+func (c *compress) newCompressionHandler(algorithm string, level int) (http.Handler, error) {
+	cfg := Config{
 		ExcludedContentTypes: c.excludes,
 		MinSize:              c.minSize,
+		Algorithm:            algorithm,
+		Level:                level,
 	}
 
-	wrapper, err := brotli.NewWrapper(cfg)
+	wrapper, err := NewWrapper(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("new brotli wrapper: %w", err)
+		return nil, fmt.Errorf("new %s wrapper: %w", algorithm, err)
 	}
 
 	return wrapper(c.next), nil
 }
 
-func encodingAccepts(acceptEncoding []string, typ string) bool {
+// negotiateEncoding returns the encoding to use among the supported ones, given in the order of preference,
+// according to the quality values of the Accept-Encoding header, or an empty string if none is acceptable.
+// The order of preference breaks the ties between encodings of the same quality.
+// See https://www.rfc-editor.org/rfc/rfc9110.html#section-12.5.3
+func negotiateEncoding(acceptEncoding []string, encodings []string) string {
+	qualities := parseAcceptEncoding(acceptEncoding)
+
+	var best string
+	var bestQuality float64
+	for _, encoding := range encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = qualities["*"]
+		}
+
+		if quality > bestQuality {
+			best = encoding
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// parseAcceptEncoding returns the quality value of each coding listed in the Accept-Encoding header values.
+// The codings with an invalid quality value are ignored.
+func parseAcceptEncoding(acceptEncoding []string) map[string]float64 {
+	qualities := make(map[string]float64)
+
 	for _, ae := range acceptEncoding {
 		for _, e := range strings.Split(ae, ",") {
-			parsed := strings.Split(strings.TrimSpace(e), ";")
-			if len(parsed) == 0 {
+			coding, params, _ := strings.Cut(e, ";")
+
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
 				continue
 			}
-			if parsed[0] == typ || parsed[0] == "*" {
-				return true
+
+			quality, ok := parseQuality(params)
+			if !ok {
+				continue
 			}
+
+			qualities[coding] = quality
 		}
 	}
 
-	return false
+	return qualities
+}
+
+// parseQuality returns the value of the q parameter, which is 1 when it is absent,
+// and whether it is valid.
+func parseQuality(params string) (float64, bool) {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return 0, false
+		}
+
+		return quality, true
+	}
+
+	return 1, true
+}
+
+// encodingAccepts reports whether the Accept-Encoding header values explicitly accept the given coding.
+func encodingAccepts(acceptEncoding []string, typ string) bool {
+	quality, ok := parseAcceptEncoding(acceptEncoding)[typ]
+	return ok && quality > 0
 }
 
 func contains(values []string, val string) bool {
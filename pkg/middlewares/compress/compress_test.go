@@ -12,6 +12,7 @@ import (
 
 	"github.com/andybalholm/brotli"
 	"github.com/klauspost/compress/gzhttp"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"traefik/v3/pkg/config/dynamic"
@@ -25,11 +26,13 @@ const (
 	varyHeader            = "Vary"
 	gzipValue             = "gzip"
 	brotliValue           = "br"
+	zstdValue             = "zstd"
 )
 
 func TestNegotiation(t *testing.T) {
 	testCases := []struct {
 		desc            string
+		encodings       []string
 		acceptEncHeader string
 		expEncoding     string
 	}{
@@ -57,21 +60,78 @@ func TestNegotiation(t *testing.T) {
 			acceptEncHeader: "br",
 			expEncoding:     "br",
 		},
+		{
+			desc:            "zstd accept header",
+			acceptEncHeader: "zstd",
+			expEncoding:     "zstd",
+		},
 		{
 			desc:            "multi accept header, prefer br",
 			acceptEncHeader: "br;q=0.8, gzip;q=0.6",
 			expEncoding:     "br",
 		},
 		{
-			desc:            "multi accept header, prefer br",
+			desc:            "multi accept header, prefer gzip",
 			acceptEncHeader: "gzip;q=1.0, br;q=0.8",
-			expEncoding:     "br",
+			expEncoding:     "gzip",
 		},
 		{
 			desc:            "multi accept header list, prefer br",
 			acceptEncHeader: "gzip, br",
 			expEncoding:     "br",
 		},
+		{
+			desc:            "multi accept header list, prefer br over zstd",
+			acceptEncHeader: "gzip, deflate, br, zstd",
+			expEncoding:     "br",
+		},
+		{
+			desc:            "multi accept header, prefer zstd",
+			acceptEncHeader: "zstd, br;q=0.9, gzip;q=0.8",
+			expEncoding:     "zstd",
+		},
+		{
+			desc:            "br refused",
+			acceptEncHeader: "br;q=0, *",
+			expEncoding:     "gzip",
+		},
+		{
+			desc:            "any encoding with lower quality",
+			acceptEncHeader: "gzip;q=0.5, *;q=0.1",
+			expEncoding:     "gzip",
+		},
+		{
+			desc:            "case insensitive",
+			acceptEncHeader: "GZIP;Q=0.5, br;q=0.4",
+			expEncoding:     "gzip",
+		},
+		{
+			desc:            "invalid quality value",
+			acceptEncHeader: "br;q=foo, gzip",
+			expEncoding:     "gzip",
+		},
+		{
+			desc:            "identity only",
+			acceptEncHeader: "identity, *;q=0",
+			expEncoding:     "",
+		},
+		{
+			desc:        "no accept header, custom preference order",
+			encodings:   []string{"gzip", "br"},
+			expEncoding: "gzip",
+		},
+		{
+			desc:            "custom preference order",
+			encodings:       []string{"br", "zstd"},
+			acceptEncHeader: "gzip, zstd, br",
+			expEncoding:     "br",
+		},
+		{
+			desc:            "unsupported encoding in custom preference order",
+			encodings:       []string{"br"},
+			acceptEncHeader: "zstd",
+			expEncoding:     "",
+		},
 	}
 
 	for _, test := range testCases {
@@ -88,7 +148,7 @@ func TestNegotiation(t *testing.T) {
 			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 				_, _ = rw.Write(generateBytes(10))
 			})
-			handler, err := New(context.Background(), next, dynamic.Compress{MinResponseBodyBytes: 1}, "testing")
+			handler, err := New(context.Background(), next, dynamic.Compress{MinResponseBodyBytes: 1, Encodings: test.encodings}, "testing")
 			require.NoError(t, err)
 
 			rw := httptest.NewRecorder()
@@ -99,6 +159,138 @@ func TestNegotiation(t *testing.T) {
 	}
 }
 
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc string
+		conf dynamic.Compress
+	}{
+		{
+			desc: "unsupported encoding",
+			conf: dynamic.Compress{Encodings: []string{"br", "deflate"}},
+		},
+		{
+			desc: "duplicated encoding",
+			conf: dynamic.Compress{Encodings: []string{"br", "gzip", "br"}},
+		},
+		{
+			desc: "invalid zstd level",
+			conf: dynamic.Compress{Levels: &dynamic.CompressLevels{Zstd: 23}},
+		},
+		{
+			desc: "invalid brotli level",
+			conf: dynamic.Compress{Levels: &dynamic.CompressLevels{Brotli: 12}},
+		},
+		{
+			desc: "invalid gzip level",
+			conf: dynamic.Compress{Levels: &dynamic.CompressLevels{Gzip: 10}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.conf, "testing")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestShouldCompressWithLevels(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		encoding string
+		levels   *dynamic.CompressLevels
+		reader   func(io.Reader) (io.Reader, error)
+	}{
+		{
+			desc:     "zstd",
+			encoding: "zstd",
+			levels:   &dynamic.CompressLevels{Zstd: 19},
+			reader: func(r io.Reader) (io.Reader, error) {
+				return zstd.NewReader(r)
+			},
+		},
+		{
+			desc:     "brotli",
+			encoding: brotliValue,
+			levels:   &dynamic.CompressLevels{Brotli: 11},
+			reader: func(r io.Reader) (io.Reader, error) {
+				return brotli.NewReader(r), nil
+			},
+		},
+		{
+			desc:     "gzip",
+			encoding: gzipValue,
+			levels:   &dynamic.CompressLevels{Gzip: 1},
+			reader: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set(acceptEncodingHeader, test.encoding)
+
+			baseBody := generateBytes(gzhttp.DefaultMinSize)
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				_, err := rw.Write(baseBody)
+				assert.NoError(t, err)
+			})
+			handler, err := New(context.Background(), next, dynamic.Compress{Levels: test.levels}, "testing")
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.encoding, rw.Header().Get(contentEncodingHeader))
+			assert.Equal(t, acceptEncodingHeader, rw.Header().Get(varyHeader))
+
+			reader, err := test.reader(rw.Body)
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, baseBody, got)
+		})
+	}
+}
+
+func TestShouldCompressWithGzipWhenAnyEncodingAccepted(t *testing.T) {
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set(acceptEncodingHeader, "*")
+
+	baseBody := generateBytes(gzhttp.DefaultMinSize)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, err := rw.Write(baseBody)
+		assert.NoError(t, err)
+	})
+	handler, err := New(context.Background(), next, dynamic.Compress{Encodings: []string{"gzip"}}, "testing")
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, gzipValue, rw.Header().Get(contentEncodingHeader))
+
+	gr, err := gzip.NewReader(rw.Body)
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, baseBody, got)
+}
+
 func TestShouldCompressWhenNoContentEncodingHeader(t *testing.T) {
 	req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
 	req.Header.Add(acceptEncodingHeader, gzipValue)
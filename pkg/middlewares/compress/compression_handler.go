@@ -1,4 +1,4 @@
-package brotli
+package compress
 
 import (
 	"bufio"
@@ -7,8 +7,10 @@ import (
 	"mime"
 	"net"
 	"net/http"
+	"sync"
 
 	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
@@ -19,20 +21,62 @@ const (
 	contentType     = "Content-Type"
 )
 
-// Config is the Brotli handler configuration.
+// Config is the compression handler configuration.
 type Config struct {
 	// ExcludedContentTypes is the list of content types for which we should not compress.
 	ExcludedContentTypes []string
 	// MinSize is the minimum size (in bytes) required to enable compression.
 	MinSize int
+	// Algorithm is the compression algorithm, either brotli or zstd.
+	Algorithm string
+	// Level is the compression level, the default level of the algorithm is used when it is zero.
+	Level int
 }
 
-// NewWrapper returns a new Brotli compressing wrapper.
+// compressionWriter is the writer compressing the response body.
+type compressionWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// writerPool pools the compression writers of an algorithm, with a given level,
+// as creating them for each response is expensive, notably for zstd.
+type writerPool struct {
+	pool      sync.Pool
+	newWriter func(w io.Writer) (compressionWriter, error)
+}
+
+// get returns a writer compressing to the given writer.
+func (p *writerPool) get(w io.Writer) (compressionWriter, error) {
+	if cw, ok := p.pool.Get().(compressionWriter); ok {
+		cw.Reset(w)
+		return cw, nil
+	}
+
+	return p.newWriter(w)
+}
+
+// put puts back the given closed writer in the pool.
+func (p *writerPool) put(cw compressionWriter) {
+	// The writer must not keep a reference to the response writer.
+	cw.Reset(nil)
+	p.pool.Put(cw)
+}
+
+// NewWrapper returns a new compressing wrapper, using the configured algorithm.
 func NewWrapper(cfg Config) (func(http.Handler) http.HandlerFunc, error) {
 	if cfg.MinSize < 0 {
 		return nil, fmt.Errorf("minimum size must be greater than or equal to zero")
 	}
 
+	newWriter, err := newCompressionWriter(cfg.Algorithm, cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &writerPool{newWriter: newWriter}
+
 	var contentTypes []parsedContentType
 	for _, v := range cfg.ExcludedContentTypes {
 		mediaType, params, err := mime.ParseMediaType(v)
@@ -47,25 +91,65 @@ func NewWrapper(cfg Config) (func(http.Handler) http.HandlerFunc, error) {
 		return func(rw http.ResponseWriter, r *http.Request) {
 			rw.Header().Add(vary, acceptEncoding)
 
-			brw := &responseWriter{
+			crw := &responseWriter{
 				rw:                   rw,
-				bw:                   brotli.NewWriter(rw),
+				pool:                 pool,
+				algorithm:            cfg.Algorithm,
 				minSize:              cfg.MinSize,
 				statusCode:           http.StatusOK,
 				excludedContentTypes: contentTypes,
 			}
-			defer brw.close()
+			defer crw.close()
 
-			h.ServeHTTP(brw, r)
+			h.ServeHTTP(crw, r)
 		}
 	}, nil
 }
 
+// newCompressionWriter returns a function creating the writers of the given algorithm, with the given level.
+func newCompressionWriter(algorithm string, level int) (func(w io.Writer) (compressionWriter, error), error) {
+	switch algorithm {
+	case brotliName:
+		if level < 0 || level > brotli.BestCompression {
+			return nil, fmt.Errorf("invalid brotli compression level %d, must be between 1 and %d", level, brotli.BestCompression)
+		}
+
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+
+		return func(w io.Writer) (compressionWriter, error) {
+			return brotli.NewWriterLevel(w, level), nil
+		}, nil
+
+	case zstdName:
+		if level < 0 || level > maxZstdLevel {
+			return nil, fmt.Errorf("invalid zstd compression level %d, must be between 1 and %d", level, maxZstdLevel)
+		}
+
+		encoderLevel := zstd.SpeedDefault
+		if level > 0 {
+			encoderLevel = zstd.EncoderLevelFromZstd(level)
+		}
+
+		return func(w io.Writer) (compressionWriter, error) {
+			// A single goroutine is enough, as each response is compressed by its own encoder.
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %q", algorithm)
+	}
+}
+
 // TODO: check whether we want to implement content-type sniffing (as gzip does)
 // TODO: check whether we should support Accept-Ranges (as gzip does, see https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Accept-Ranges)
 type responseWriter struct {
 	rw http.ResponseWriter
-	bw *brotli.Writer
+	// cw is taken from the pool when the compression starts.
+	cw        compressionWriter
+	pool      *writerPool
+	algorithm string
 
 	minSize              int
 	excludedContentTypes []parsedContentType
@@ -76,8 +160,8 @@ type responseWriter struct {
 	compressionDisabled bool
 	headersSent         bool
 
-	// Mostly needed to avoid calling bw.Flush/bw.Close when no data was
-	// written in bw.
+	// Mostly needed to avoid calling cw.Flush/cw.Close when no data was
+	// written in cw.
 	seenData bool
 
 	statusCodeSet bool
@@ -112,7 +196,7 @@ func (r *responseWriter) Write(p []byte) (int, error) {
 	// We are now in compression cruise mode until the end of times.
 	if r.compressionStarted {
 		// If compressionStarted we assume we have sent headers already
-		return r.bw.Write(p)
+		return r.cw.Write(p)
 	}
 
 	// If we detect a contentEncoding, we know we are never going to compress.
@@ -144,18 +228,25 @@ func (r *responseWriter) Write(p []byte) (int, error) {
 
 	// If we ever make it here, we have received at least minSize, which means we want to compress,
 	// and we are going to send headers right away.
+	cw, err := r.pool.get(r.rw)
+	if err != nil {
+		// Return zero because we haven't taken care of the bytes in argument yet.
+		return 0, fmt.Errorf("creating %s writer: %w", r.algorithm, err)
+	}
+
+	r.cw = cw
 	r.compressionStarted = true
 
 	// Since we know we are going to compress we will never be able to know the actual length.
 	r.rw.Header().Del(contentLength)
 
-	r.rw.Header().Set(contentEncoding, "br")
+	r.rw.Header().Set(contentEncoding, r.algorithm)
 	r.rw.WriteHeader(r.statusCode)
 	r.headersSent = true
 
 	// Start with sending what we have previously buffered, before actually writing
 	// the bytes in argument.
-	n, err := r.bw.Write(r.buf)
+	n, err := r.cw.Write(r.buf)
 	if err != nil {
 		r.buf = r.buf[n:]
 		// Return zero because we haven't taken care of the bytes in argument yet.
@@ -174,7 +265,7 @@ func (r *responseWriter) Write(p []byte) (int, error) {
 	r.buf = r.buf[:0]
 
 	// Now that we emptied the buffer, we can actually write the given bytes.
-	return r.bw.Write(p)
+	return r.cw.Write(p)
 }
 
 // Flush flushes data to the appropriate underlying writer(s), although it does
@@ -183,7 +274,7 @@ func (r *responseWriter) Write(p []byte) (int, error) {
 // no flushing will take place.
 func (r *responseWriter) Flush() {
 	if !r.seenData {
-		// we should not flush if there never was any data, because flushing the bw
+		// we should not flush if there never was any data, because flushing the cw
 		// (just like closing) would send some extra end of compressionStarted stream bytes.
 		return
 	}
@@ -198,7 +289,7 @@ func (r *responseWriter) Flush() {
 		return
 	}
 
-	// Here, nothing was ever written either to rw or to bw (since we're still
+	// Here, nothing was ever written either to rw or to cw (since we're still
 	// waiting to decide whether to compress), so we do not need to flush anything.
 	// Note that we diverge with klauspost's gzip behavior, where they instead
 	// force compression and flush whatever was in the buffer in this case.
@@ -206,13 +297,13 @@ func (r *responseWriter) Flush() {
 		return
 	}
 
-	// Conversely, we here know that something was already written to bw (or is
-	// going to be written right after anyway), so bw will have to be flushed.
-	// Also, since we know that bw writes to rw, but (apparently) never flushes it,
+	// Conversely, we here know that something was already written to cw (or is
+	// going to be written right after anyway), so cw will have to be flushed.
+	// Also, since we know that cw writes to rw, but (apparently) never flushes it,
 	// we have to do it ourselves.
 	defer func() {
 		// because we also ignore the error returned by Write anyway
-		_ = r.bw.Flush()
+		_ = r.cw.Flush()
 
 		if rw, ok := r.rw.(http.Flusher); ok {
 			rw.Flush()
@@ -220,7 +311,7 @@ func (r *responseWriter) Flush() {
 	}()
 
 	// We empty whatever is left of the buffer that Write never took care of.
-	n, err := r.bw.Write(r.buf)
+	n, err := r.cw.Write(r.buf)
 	if err != nil {
 		return
 	}
@@ -268,14 +359,14 @@ func (r *responseWriter) close() error {
 	}
 
 	// If compression was disabled, there never was anything in the buffer to flush,
-	// and nothing was ever written to bw.
+	// and nothing was ever written to cw.
 	if r.compressionDisabled {
 		return nil
 	}
 
 	if len(r.buf) == 0 {
-		// If we got here we know compression has started, so we can safely flush on bw.
-		return r.bw.Close()
+		// If we got here we know compression has started, so we can safely flush on cw.
+		return r.closeWriter()
 	}
 
 	// There is still data in the buffer, because we never reached minSize (to
@@ -293,16 +384,25 @@ func (r *responseWriter) close() error {
 
 	// There is still data in the buffer, simply because Write did not take care of it all.
 	// We flush it to the compressed writer.
-	n, err := r.bw.Write(r.buf)
+	n, err := r.cw.Write(r.buf)
 	if err != nil {
-		r.bw.Close()
+		_ = r.closeWriter()
 		return err
 	}
 	if n < len(r.buf) {
-		r.bw.Close()
+		_ = r.closeWriter()
 		return io.ErrShortWrite
 	}
-	return r.bw.Close()
+	return r.closeWriter()
+}
+
+// closeWriter closes the compressed writer, and puts it back in the pool.
+func (r *responseWriter) closeWriter() error {
+	err := r.cw.Close()
+	r.pool.put(r.cw)
+	r.cw = nil
+
+	return err
 }
 
 // parsedContentType is the parsed representation of one of the inputs to ContentTypes.
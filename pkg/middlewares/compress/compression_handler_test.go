@@ -1,4 +1,4 @@
-package brotli
+package compress
 
 import (
 	"bytes"
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -91,7 +92,7 @@ func Test_NoBody(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			h := mustNewWrapper(t, Config{MinSize: 1024})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			h := mustNewWrapper(t, Config{MinSize: 1024, Algorithm: brotliName})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 				rw.WriteHeader(test.statusCode)
 
 				_, err := rw.Write(test.body)
@@ -115,7 +116,8 @@ func Test_NoBody(t *testing.T) {
 
 func Test_MinSize(t *testing.T) {
 	cfg := Config{
-		MinSize: 128,
+		MinSize:   128,
+		Algorithm: brotliName,
 	}
 
 	var bodySize int
@@ -150,7 +152,7 @@ func Test_MinSize(t *testing.T) {
 }
 
 func Test_MultipleWriteHeader(t *testing.T) {
-	h := mustNewWrapper(t, Config{MinSize: 1024})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	h := mustNewWrapper(t, Config{MinSize: 1024, Algorithm: brotliName})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		// We ensure that the subsequent call to WriteHeader is a noop.
 		rw.WriteHeader(http.StatusInternalServerError)
 		rw.WriteHeader(http.StatusNotFound)
@@ -166,7 +168,7 @@ func Test_MultipleWriteHeader(t *testing.T) {
 }
 
 func Test_FlushBeforeWrite(t *testing.T) {
-	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024, Algorithm: brotliName})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusOK)
 		rw.(http.Flusher).Flush()
 
@@ -194,7 +196,7 @@ func Test_FlushBeforeWrite(t *testing.T) {
 }
 
 func Test_FlushAfterWrite(t *testing.T) {
-	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024, Algorithm: brotliName})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusOK)
 
 		_, err := rw.Write(bigTestBody[0:1])
@@ -227,7 +229,7 @@ func Test_FlushAfterWrite(t *testing.T) {
 }
 
 func Test_FlushAfterWriteNil(t *testing.T) {
-	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024, Algorithm: brotliName})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusOK)
 
 		_, err := rw.Write(nil)
@@ -256,7 +258,7 @@ func Test_FlushAfterWriteNil(t *testing.T) {
 }
 
 func Test_FlushAfterAllWrites(t *testing.T) {
-	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	srv := httptest.NewServer(mustNewWrapper(t, Config{MinSize: 1024, Algorithm: brotliName})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		for i := range bigTestBody {
 			_, err := rw.Write(bigTestBody[i : i+1])
 			require.NoError(t, err)
@@ -283,6 +285,56 @@ func Test_FlushAfterAllWrites(t *testing.T) {
 	assert.Equal(t, bigTestBody, got)
 }
 
+func Test_PooledWriters(t *testing.T) {
+	testCases := []struct {
+		algorithm string
+		newReader func(r io.Reader) (io.Reader, error)
+	}{
+		{
+			algorithm: brotliName,
+			newReader: func(r io.Reader) (io.Reader, error) {
+				return brotli.NewReader(r), nil
+			},
+		},
+		{
+			algorithm: zstdName,
+			newReader: func(r io.Reader) (io.Reader, error) {
+				return zstd.NewReader(r)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.algorithm, func(t *testing.T) {
+			t.Parallel()
+
+			h := mustNewWrapper(t, Config{MinSize: 1024, Algorithm: test.algorithm})(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, err := rw.Write(bigTestBody)
+				require.NoError(t, err)
+			}))
+
+			// The writers released by the previous responses are reused by the next ones.
+			for i := 0; i < 3; i++ {
+				req, _ := http.NewRequest(http.MethodGet, "/whatever", nil)
+				req.Header.Set(acceptEncoding, test.algorithm)
+
+				rw := httptest.NewRecorder()
+				h.ServeHTTP(rw, req)
+
+				assert.Equal(t, test.algorithm, rw.Header().Get(contentEncoding))
+
+				reader, err := test.newReader(rw.Body)
+				require.NoError(t, err)
+
+				got, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Equal(t, bigTestBody, got)
+			}
+		})
+	}
+}
+
 func Test_ExcludedContentTypes(t *testing.T) {
 	testCases := []struct {
 		desc                 string
@@ -353,6 +405,7 @@ func Test_ExcludedContentTypes(t *testing.T) {
 
 			cfg := Config{
 				MinSize:              1024,
+				Algorithm:            brotliName,
 				ExcludedContentTypes: test.excludedContentTypes,
 			}
 			h := mustNewWrapper(t, cfg)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -459,6 +512,7 @@ func Test_FlushExcludedContentTypes(t *testing.T) {
 
 			cfg := Config{
 				MinSize:              1024,
+				Algorithm:            brotliName,
 				ExcludedContentTypes: test.excludedContentTypes,
 			}
 			h := mustNewWrapper(t, cfg)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -521,7 +575,7 @@ func mustNewWrapper(t *testing.T, cfg Config) func(http.Handler) http.HandlerFun
 func newTestHandler(t *testing.T, body []byte) http.Handler {
 	t.Helper()
 
-	return mustNewWrapper(t, Config{MinSize: 1024})(
+	return mustNewWrapper(t, Config{MinSize: 1024, Algorithm: brotliName})(
 		http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 			if req.URL.Path == "/compressed" {
 				rw.Header().Set("Content-Encoding", "br")
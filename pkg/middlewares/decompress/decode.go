@@ -0,0 +1,97 @@
+package decompress
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrBodyTooLarge is returned by Decode when the decompressed body exceeds the maximum size.
+var ErrBodyTooLarge = errors.New("decompressed body too large")
+
+// ContentCodings returns the content codings listed in the Content-Encoding header, in the order they were applied.
+func ContentCodings(header http.Header) []string {
+	var codings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" || coding == "identity" {
+				continue
+			}
+
+			codings = append(codings, coding)
+		}
+	}
+
+	return codings
+}
+
+// Supported reports whether all the given content codings can be decoded.
+func Supported(codings []string) bool {
+	for _, coding := range codings {
+		switch coding {
+		case "gzip", "x-gzip", "br", "zstd":
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Decode reads the whole body, decoding the given content codings in the reverse order of their application,
+// and returns ErrBodyTooLarge as soon as the decompressed body exceeds the maximum size.
+func Decode(body io.Reader, codings []string, maxBodyBytes int64) ([]byte, error) {
+	reader := body
+	for i := len(codings) - 1; i >= 0; i-- {
+		decoder, err := newDecoder(codings[i], reader, maxBodyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("creating %s decoder: %w", codings[i], err)
+		}
+		defer func() { _ = decoder.Close() }()
+
+		reader = decoder
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxBodyBytes+1))
+	if errors.Is(err, zstd.ErrWindowSizeExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, ErrBodyTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > maxBodyBytes {
+		return nil, ErrBodyTooLarge
+	}
+
+	return data, nil
+}
+
+func newDecoder(coding string, reader io.Reader, maxBodyBytes int64) (io.ReadCloser, error) {
+	switch coding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(reader)
+
+	case "br":
+		return io.NopCloser(brotli.NewReader(reader)), nil
+
+	case "zstd":
+		// The window size is bounded by the maximum body size, so that a malicious frame header cannot make the decoder allocate too much memory.
+		decoder, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(maxBodyBytes)))
+		if err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported content coding %q", coding)
+	}
+}
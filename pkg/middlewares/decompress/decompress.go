@@ -0,0 +1,99 @@
+// Package decompress implements a middleware decompressing the request bodies before forwarding them.
+package decompress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/tracing"
+)
+
+const typeName = "Decompress"
+
+// DefaultMaxDecompressedBodyBytes is the default maximum size (in bytes) of a decompressed request body.
+const DefaultMaxDecompressedBodyBytes = 10 * 1024 * 1024
+
+// supportedEncodings is the list of the supported content codings, sent in the Accept-Encoding header
+// of the responses to the requests with an unsupported content coding.
+const supportedEncodings = "br, gzip, zstd"
+
+// decompress is a middleware decoding the request bodies according to their Content-Encoding header,
+// so that the backends receive them as if they were sent without compression.
+type decompress struct {
+	next         http.Handler
+	name         string
+	maxBodyBytes int64
+}
+
+// New creates a new decompress middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Decompress, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if config.MaxDecompressedBodyBytes < 0 {
+		return nil, fmt.Errorf("negative value not valid for maxDecompressedBodyBytes: %d", config.MaxDecompressedBodyBytes)
+	}
+
+	maxBodyBytes := int64(DefaultMaxDecompressedBodyBytes)
+	if config.MaxDecompressedBodyBytes > 0 {
+		maxBodyBytes = config.MaxDecompressedBodyBytes
+	}
+
+	return &decompress{
+		next:         next,
+		name:         name,
+		maxBodyBytes: maxBodyBytes,
+	}, nil
+}
+
+func (d *decompress) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return d.name, tracing.SpanKindNoneEnum
+}
+
+func (d *decompress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), d.name, typeName)
+
+	codings := ContentCodings(req.Header)
+	if len(codings) == 0 || req.Body == nil || req.Body == http.NoBody {
+		d.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if !Supported(codings) {
+		logger.Debug().Msgf("Unsupported content codings %q", codings)
+
+		// See https://www.rfc-editor.org/rfc/rfc9110.html#section-12.5.3
+		rw.Header().Set("Accept-Encoding", supportedEncodings)
+		http.Error(rw, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := Decode(req.Body, codings, d.maxBodyBytes)
+	_ = req.Body.Close()
+	if err != nil {
+		logger.Debug().Err(err).Msg("Error while decompressing the request body")
+
+		if errors.Is(err, ErrBodyTooLarge) {
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.TransferEncoding = nil
+	req.Header.Del("Content-Encoding")
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	d.next.ServeHTTP(rw, req)
+}
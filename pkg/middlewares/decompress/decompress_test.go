@@ -0,0 +1,200 @@
+package decompress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestDecompress_ServeHTTP(t *testing.T) {
+	body := strings.Repeat("foobar", 100)
+
+	testCases := []struct {
+		desc                   string
+		contentEncoding        string
+		body                   []byte
+		maxBodyBytes           int64
+		expectedStatusCode     int
+		expectedBody           string
+		expectedAcceptEncoding string
+		// expectedContentEncoding is the Content-Encoding header of the forwarded request.
+		expectedContentEncoding string
+	}{
+		{
+			desc:               "no content encoding",
+			body:               []byte(body),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       body,
+		},
+		{
+			desc:                    "identity",
+			contentEncoding:         "identity",
+			body:                    []byte(body),
+			expectedStatusCode:      http.StatusOK,
+			expectedBody:            body,
+			expectedContentEncoding: "identity",
+		},
+		{
+			desc:               "gzip",
+			contentEncoding:    "gzip",
+			body:               gzipBytes(t, []byte(body)),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       body,
+		},
+		{
+			desc:               "x-gzip",
+			contentEncoding:    "x-gzip",
+			body:               gzipBytes(t, []byte(body)),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       body,
+		},
+		{
+			desc:               "brotli",
+			contentEncoding:    "br",
+			body:               brotliBytes(t, []byte(body)),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       body,
+		},
+		{
+			desc:               "zstd",
+			contentEncoding:    "ZSTD",
+			body:               zstdBytes(t, []byte(body)),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       body,
+		},
+		{
+			desc:               "multiple content codings",
+			contentEncoding:    "gzip, br",
+			body:               brotliBytes(t, gzipBytes(t, []byte(body))),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       body,
+		},
+		{
+			desc:                   "unsupported content coding",
+			contentEncoding:        "deflate",
+			body:                   []byte(body),
+			expectedStatusCode:     http.StatusUnsupportedMediaType,
+			expectedAcceptEncoding: "br, gzip, zstd",
+		},
+		{
+			desc:               "invalid compressed body",
+			contentEncoding:    "gzip",
+			body:               []byte(body),
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "body at the maximum size",
+			contentEncoding:    "gzip",
+			body:               gzipBytes(t, []byte(body)),
+			maxBodyBytes:       int64(len(body)),
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       body,
+		},
+		{
+			desc:               "gzip body too large",
+			contentEncoding:    "gzip",
+			body:               gzipBytes(t, []byte(body)),
+			maxBodyBytes:       int64(len(body)) - 1,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:               "brotli body too large",
+			contentEncoding:    "br",
+			body:               brotliBytes(t, []byte(body)),
+			maxBodyBytes:       int64(len(body)) - 1,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:               "zstd body too large",
+			contentEncoding:    "zstd",
+			body:               zstdBytes(t, make([]byte, 10*1024*1024)),
+			maxBodyBytes:       1024 * 1024,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, test.expectedContentEncoding, req.Header.Get("Content-Encoding"))
+
+				got, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, int64(len(got)), req.ContentLength)
+
+				_, _ = rw.Write(got)
+			})
+
+			handler, err := New(context.Background(), next, dynamic.Decompress{MaxDecompressedBodyBytes: test.maxBodyBytes}, "test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", bytes.NewReader(test.body))
+			if test.contentEncoding != "" {
+				req.Header.Set("Content-Encoding", test.contentEncoding)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.Equal(t, test.expectedAcceptEncoding, recorder.Header().Get("Accept-Encoding"))
+
+			if test.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.Decompress{MaxDecompressedBodyBytes: -1}, "test")
+	assert.Error(t, err)
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func brotliBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := brotli.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	w, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+
+	return w.EncodeAll(data, nil)
+}
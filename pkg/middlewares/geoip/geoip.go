@@ -0,0 +1,239 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/ip"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/mmdb"
+	"traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "GeoIP"
+)
+
+// geoIP is a middleware that accepts / refuses requests based on the geolocation of the client IP.
+type geoIP struct {
+	next            http.Handler
+	countryDatabase *mmdb.Database
+	asnDatabase     *mmdb.Database
+
+	allowedCountries map[string]struct{}
+	deniedCountries  map[string]struct{}
+	allowedASNs      map[uint]struct{}
+	deniedASNs       map[uint]struct{}
+
+	countryHeader string
+	asnHeader     string
+	strategy      ip.Strategy
+	name          string
+}
+
+// New builds a new GeoIP middleware given the databases to look up the client IPs in.
+func New(ctx context.Context, next http.Handler, config dynamic.GeoIP, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.CountryDatabase == "" && config.ASNDatabase == "" {
+		return nil, errors.New("countryDatabase and asnDatabase are empty, GeoIP not created")
+	}
+
+	if config.CountryDatabase == "" && (len(config.AllowedCountries) > 0 || len(config.DeniedCountries) > 0 || config.CountryHeader != "") {
+		return nil, errors.New("countryDatabase is required to filter the countries, or to set the country header")
+	}
+
+	if config.ASNDatabase == "" && (len(config.AllowedASNs) > 0 || len(config.DeniedASNs) > 0 || config.ASNHeader != "") {
+		return nil, errors.New("asnDatabase is required to filter the autonomous systems, or to set the autonomous system header")
+	}
+
+	g := &geoIP{
+		next:             next,
+		allowedCountries: countrySet(config.AllowedCountries),
+		deniedCountries:  countrySet(config.DeniedCountries),
+		allowedASNs:      asnSet(config.AllowedASNs),
+		deniedASNs:       asnSet(config.DeniedASNs),
+		countryHeader:    config.CountryHeader,
+		asnHeader:        config.ASNHeader,
+		name:             name,
+	}
+
+	var err error
+	if config.CountryDatabase != "" {
+		g.countryDatabase, err = mmdb.Watch(config.CountryDatabase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ASNDatabase != "" {
+		g.asnDatabase, err = mmdb.Watch(config.ASNDatabase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g.strategy, err = config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug().Msgf("Setting up GeoIP with countryDatabase: %s and asnDatabase: %s", config.CountryDatabase, config.ASNDatabase)
+
+	return g, nil
+}
+
+func (g *geoIP) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return g.name, tracing.SpanKindNoneEnum
+}
+
+func (g *geoIP) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), g.name, typeName)
+	ctx := logger.WithContext(req.Context())
+
+	// The headers are only set by this middleware, they must not be forged by the clients.
+	if g.countryHeader != "" {
+		req.Header.Del(g.countryHeader)
+	}
+	if g.asnHeader != "" {
+		req.Header.Del(g.asnHeader)
+	}
+
+	clientIP := g.strategy.GetIP(req)
+
+	country, asn, err := g.lookup(clientIP)
+	if err != nil {
+		if !g.filtering() {
+			// Only the headers are set, the request is forwarded without them.
+			logger.Debug().Msgf("Unable to look up IP %s: %v", clientIP, err)
+			g.next.ServeHTTP(rw, req)
+			return
+		}
+
+		msg := fmt.Sprintf("Rejecting IP %s: %v", clientIP, err)
+		logger.Debug().Msg(msg)
+		tracing.SetErrorWithEvent(req, msg)
+		reject(ctx, rw)
+		return
+	}
+
+	if country != "" && g.countryHeader != "" {
+		req.Header.Set(g.countryHeader, country)
+	}
+
+	if asn != 0 && g.asnHeader != "" {
+		req.Header.Set(g.asnHeader, strconv.FormatUint(uint64(asn), 10))
+	}
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		if country != "" {
+			logData.Core[accesslog.GeoCountry] = country
+		}
+		if asn != 0 {
+			logData.Core[accesslog.GeoASN] = asn
+		}
+	}
+
+	if reason := g.rejectionReason(country, asn); reason != "" {
+		msg := fmt.Sprintf("Rejecting IP %s: %s", clientIP, reason)
+		logger.Debug().Msg(msg)
+		tracing.SetErrorWithEvent(req, msg)
+		reject(ctx, rw)
+		return
+	}
+	logger.Debug().Msgf("Accepting IP %s", clientIP)
+
+	g.next.ServeHTTP(rw, req)
+}
+
+// lookup returns the country and the autonomous system number of the given IP,
+// which are empty when not found in the databases.
+func (g *geoIP) lookup(addr string) (string, uint, error) {
+	var country string
+	if g.countryDatabase != nil {
+		record, _, err := g.countryDatabase.Lookup(addr)
+		if err != nil {
+			return "", 0, err
+		}
+		country = record.Country
+	}
+
+	var asn uint
+	if g.asnDatabase != nil {
+		record, _, err := g.asnDatabase.Lookup(addr)
+		if err != nil {
+			return country, 0, err
+		}
+		asn = record.ASN
+	}
+
+	return country, asn, nil
+}
+
+// filtering returns whether the requests are filtered on their country or autonomous system.
+func (g *geoIP) filtering() bool {
+	return len(g.allowedCountries) > 0 || len(g.deniedCountries) > 0 || len(g.allowedASNs) > 0 || len(g.deniedASNs) > 0
+}
+
+// rejectionReason returns why a request with the given country and autonomous system number must be refused,
+// or an empty string if it must be accepted.
+func (g *geoIP) rejectionReason(country string, asn uint) string {
+	if _, ok := g.deniedCountries[country]; ok {
+		return fmt.Sprintf("country %s is denied", country)
+	}
+
+	if len(g.allowedCountries) > 0 {
+		if _, ok := g.allowedCountries[country]; !ok {
+			return fmt.Sprintf("country %q is not allowed", country)
+		}
+	}
+
+	if _, ok := g.deniedASNs[asn]; ok {
+		return fmt.Sprintf("autonomous system %d is denied", asn)
+	}
+
+	if len(g.allowedASNs) > 0 {
+		if _, ok := g.allowedASNs[asn]; !ok {
+			return fmt.Sprintf("autonomous system %d is not allowed", asn)
+		}
+	}
+
+	return ""
+}
+
+func countrySet(countries []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(countries))
+	for _, country := range countries {
+		set[strings.ToUpper(strings.TrimSpace(country))] = struct{}{}
+	}
+
+	return set
+}
+
+func asnSet(asns []uint) map[uint]struct{} {
+	set := make(map[uint]struct{}, len(asns))
+	for _, asn := range asns {
+		set[asn] = struct{}{}
+	}
+
+	return set
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Send()
+	}
+}
@@ -0,0 +1,268 @@
+package geoip
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/testhelpers"
+)
+
+func writeDatabases(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	countryDatabase := filepath.Join(dir, "country.mmdb")
+	err := testhelpers.WriteMaxMindDatabase(countryDatabase, "GeoLite2-Country", 6, 24, []testhelpers.MaxMindNetwork{
+		{CIDR: "1.0.0.0/16", Data: map[string]interface{}{"country": map[string]interface{}{"iso_code": "FR"}}},
+		{CIDR: "2.0.0.0/16", Data: map[string]interface{}{"country": map[string]interface{}{"iso_code": "US"}}},
+		{CIDR: "2001:db8::/32", Data: map[string]interface{}{"country": map[string]interface{}{"iso_code": "DE"}}},
+	})
+	require.NoError(t, err)
+
+	asnDatabase := filepath.Join(dir, "asn.mmdb")
+	err = testhelpers.WriteMaxMindDatabase(asnDatabase, "GeoLite2-ASN", 6, 24, []testhelpers.MaxMindNetwork{
+		{CIDR: "1.0.0.0/24", Data: map[string]interface{}{"autonomous_system_number": uint32(64512)}},
+		{CIDR: "2.0.0.0/24", Data: map[string]interface{}{"autonomous_system_number": uint32(64513)}},
+	})
+	require.NoError(t, err)
+
+	return countryDatabase, asnDatabase
+}
+
+func TestNew(t *testing.T) {
+	countryDatabase, asnDatabase := writeDatabases(t)
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.GeoIP
+		expectedError bool
+	}{
+		{
+			desc:          "no database",
+			config:        dynamic.GeoIP{DeniedCountries: []string{"FR"}},
+			expectedError: true,
+		},
+		{
+			desc:          "missing country database",
+			config:        dynamic.GeoIP{ASNDatabase: asnDatabase, AllowedCountries: []string{"FR"}},
+			expectedError: true,
+		},
+		{
+			desc:          "missing ASN database",
+			config:        dynamic.GeoIP{CountryDatabase: countryDatabase, ASNHeader: "X-Geo-ASN"},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid database",
+			config:        dynamic.GeoIP{CountryDatabase: filepath.Join(t.TempDir(), "missing.mmdb")},
+			expectedError: true,
+		},
+		{
+			desc: "valid",
+			config: dynamic.GeoIP{
+				CountryDatabase:  countryDatabase,
+				ASNDatabase:      asnDatabase,
+				AllowedCountries: []string{"FR"},
+				DeniedASNs:       []uint{64512},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestGeoIP_ServeHTTP(t *testing.T) {
+	countryDatabase, asnDatabase := writeDatabases(t)
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.GeoIP
+		remoteAddr      string
+		xForwardedFor   string
+		expected        int
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:       "allowed country",
+			config:     dynamic.GeoIP{CountryDatabase: countryDatabase, AllowedCountries: []string{"fr"}},
+			remoteAddr: "1.0.1.1:1234",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "not allowed country",
+			config:     dynamic.GeoIP{CountryDatabase: countryDatabase, AllowedCountries: []string{"FR"}},
+			remoteAddr: "2.0.1.1:1234",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc:       "unknown country with allowed countries",
+			config:     dynamic.GeoIP{CountryDatabase: countryDatabase, AllowedCountries: []string{"FR"}},
+			remoteAddr: "3.0.1.1:1234",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc:       "denied country",
+			config:     dynamic.GeoIP{CountryDatabase: countryDatabase, DeniedCountries: []string{"DE"}},
+			remoteAddr: "[2001:db8::1]:1234",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc:       "unknown country with denied countries",
+			config:     dynamic.GeoIP{CountryDatabase: countryDatabase, DeniedCountries: []string{"DE"}},
+			remoteAddr: "3.0.1.1:1234",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "allowed ASN",
+			config:     dynamic.GeoIP{ASNDatabase: asnDatabase, AllowedASNs: []uint{64513}},
+			remoteAddr: "2.0.0.1:1234",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "denied ASN",
+			config:     dynamic.GeoIP{CountryDatabase: countryDatabase, ASNDatabase: asnDatabase, AllowedCountries: []string{"FR"}, DeniedASNs: []uint{64512}},
+			remoteAddr: "1.0.0.1:1234",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc: "IP strategy",
+			config: dynamic.GeoIP{
+				CountryDatabase: countryDatabase,
+				DeniedCountries: []string{"US"},
+				IPStrategy:      &dynamic.IPStrategy{Depth: 1},
+			},
+			remoteAddr:    "1.0.0.1:1234",
+			xForwardedFor: "2.0.0.1",
+			expected:      http.StatusForbidden,
+		},
+		{
+			desc: "undetermined client IP",
+			config: dynamic.GeoIP{
+				CountryDatabase: countryDatabase,
+				DeniedCountries: []string{"US"},
+				IPStrategy:      &dynamic.IPStrategy{Depth: 2},
+			},
+			remoteAddr:    "1.0.0.1:1234",
+			xForwardedFor: "1.0.0.2",
+			expected:      http.StatusForbidden,
+		},
+		{
+			desc: "undetermined client IP without filtering",
+			config: dynamic.GeoIP{
+				CountryDatabase: countryDatabase,
+				CountryHeader:   "X-Geo-Country",
+				IPStrategy:      &dynamic.IPStrategy{Depth: 2},
+			},
+			remoteAddr:    "1.0.0.1:1234",
+			xForwardedFor: "1.0.0.2",
+			expected:      http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Geo-Country": "",
+			},
+		},
+		{
+			desc: "headers",
+			config: dynamic.GeoIP{
+				CountryDatabase: countryDatabase,
+				ASNDatabase:     asnDatabase,
+				CountryHeader:   "X-Geo-Country",
+				ASNHeader:       "X-Geo-ASN",
+			},
+			remoteAddr: "2.0.0.1:1234",
+			expected:   http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Geo-Country": "US",
+				"X-Geo-ASN":     "64513",
+			},
+		},
+		{
+			desc: "forged headers",
+			config: dynamic.GeoIP{
+				CountryDatabase: countryDatabase,
+				ASNDatabase:     asnDatabase,
+				CountryHeader:   "X-Geo-Country",
+				ASNHeader:       "X-Geo-ASN",
+			},
+			remoteAddr: "3.0.0.1:1234",
+			expected:   http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Geo-Country": "",
+				"X-Geo-ASN":     "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwarded http.Header
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				forwarded = r.Header
+			})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://10.10.10.10", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set("X-Geo-Country", "FR")
+			req.Header.Set("X-Geo-ASN", "64512")
+			if test.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, forwarded.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestGeoIP_ServeHTTP_accessLog(t *testing.T) {
+	countryDatabase, asnDatabase := writeDatabases(t)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	config := dynamic.GeoIP{CountryDatabase: countryDatabase, ASNDatabase: asnDatabase}
+	handler, err := New(context.Background(), next, config, "traefikTest")
+	require.NoError(t, err)
+
+	logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+
+	req := httptest.NewRequest(http.MethodGet, "http://10.10.10.10", nil)
+	req.RemoteAddr = "1.0.0.1:1234"
+	req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "FR", logData.Core[accesslog.GeoCountry])
+	assert.Equal(t, uint(64512), logData.Core[accesslog.GeoASN])
+}
@@ -0,0 +1,120 @@
+package ipdenylist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/ip"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "IPDenyLister"
+)
+
+// ipDenyLister is a middleware that provides Checks of the Requesting IP against a set of Denylists.
+type ipDenyLister struct {
+	next       http.Handler
+	denyLister *ip.Checker
+	rangesFile *ip.RangesFile
+	strategy   ip.Strategy
+	name       string
+}
+
+// New builds a new IPDenyLister given a list of CIDR-Strings to deny.
+func New(ctx context.Context, next http.Handler, config dynamic.IPDenyList, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if len(config.SourceRange) == 0 && config.SourceRangeFile == "" {
+		return nil, errors.New("sourceRange and sourceRangeFile are empty, IPDenyLister not created")
+	}
+
+	dl := &ipDenyLister{
+		next: next,
+		name: name,
+	}
+
+	if len(config.SourceRange) > 0 {
+		checker, err := ip.NewChecker(config.SourceRange)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDRs %s: %w", config.SourceRange, err)
+		}
+		dl.denyLister = checker
+	}
+
+	if config.SourceRangeFile != "" {
+		rangesFile, err := ip.WatchRangesFile(config.SourceRangeFile)
+		if err != nil {
+			return nil, err
+		}
+		dl.rangesFile = rangesFile
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+	dl.strategy = strategy
+
+	logger.Debug().Msgf("Setting up IPDenyLister with sourceRange: %s and sourceRangeFile: %s", config.SourceRange, config.SourceRangeFile)
+
+	return dl, nil
+}
+
+func (dl *ipDenyLister) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return dl.name, tracing.SpanKindNoneEnum
+}
+
+func (dl *ipDenyLister) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), dl.name, typeName)
+	ctx := logger.WithContext(req.Context())
+
+	clientIP := dl.strategy.GetIP(req)
+	denied, err := dl.isDenied(clientIP)
+	if err != nil || denied {
+		msg := fmt.Sprintf("Rejecting IP %s", clientIP)
+		if err != nil {
+			msg = fmt.Sprintf("Rejecting IP %s: %v", clientIP, err)
+		}
+		logger.Debug().Msg(msg)
+		tracing.SetErrorWithEvent(req, msg)
+		reject(ctx, rw)
+		return
+	}
+	logger.Debug().Msgf("Accepting IP %s", clientIP)
+
+	dl.next.ServeHTTP(rw, req)
+}
+
+// isDenied checks if provided address is in the denied IPs.
+func (dl *ipDenyLister) isDenied(addr string) (bool, error) {
+	if dl.denyLister != nil {
+		denied, err := dl.denyLister.Contains(addr)
+		if err != nil || denied {
+			return denied, err
+		}
+	}
+
+	if dl.rangesFile != nil {
+		return dl.rangesFile.Contains(addr)
+	}
+
+	return false, nil
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Send()
+	}
+}
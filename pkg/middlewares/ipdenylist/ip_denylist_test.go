@@ -0,0 +1,148 @@
+package ipdenylist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestNewIPDenyLister(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		denyList      dynamic.IPDenyList
+		expectedError bool
+	}{
+		{
+			desc:          "empty",
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "missing file",
+			denyList: dynamic.IPDenyList{
+				SourceRangeFile: "/does/not/exist",
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid IP",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"10.10.10.10"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			denyLister, err := New(context.Background(), next, test.denyList, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, denyLister)
+			}
+		})
+	}
+}
+
+func TestIPDenyLister_ServeHTTP(t *testing.T) {
+	sourceRangeFile := filepath.Join(t.TempDir(), "denylist")
+	err := os.WriteFile(sourceRangeFile, []byte("# Abusive range\n30.30.30.0/24\n"), 0o600)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		denyList      dynamic.IPDenyList
+		remoteAddr    string
+		xForwardedFor string
+		expected      int
+	}{
+		{
+			desc: "denied with remote address",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.20:1234",
+			expected:   403,
+		},
+		{
+			desc: "not denied with remote address",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.21:1234",
+			expected:   200,
+		},
+		{
+			desc: "denied by the file",
+			denyList: dynamic.IPDenyList{
+				SourceRangeFile: sourceRangeFile,
+			},
+			remoteAddr: "30.30.30.30:1234",
+			expected:   403,
+		},
+		{
+			desc: "not denied by the file",
+			denyList: dynamic.IPDenyList{
+				SourceRangeFile: sourceRangeFile,
+			},
+			remoteAddr: "30.30.31.30:1234",
+			expected:   200,
+		},
+		{
+			desc: "denied with X-Forwarded-For",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+				IPStrategy:  &dynamic.IPStrategy{Depth: 1},
+			},
+			remoteAddr:    "10.10.10.10:1234",
+			xForwardedFor: "20.20.20.20",
+			expected:      403,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			denyLister, err := New(context.Background(), next, test.denyList, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, "http://10.10.10.10", nil)
+
+			if len(test.remoteAddr) > 0 {
+				req.RemoteAddr = test.remoteAddr
+			}
+
+			if len(test.xForwardedFor) > 0 {
+				req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+			}
+
+			denyLister.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
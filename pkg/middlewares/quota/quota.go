@@ -0,0 +1,206 @@
+// Package quota implements a middleware limiting the number of requests allowed for a given source over fixed calendar windows.
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog/log"
+	"github.com/vulcand/oxy/v2/utils"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/tracing"
+)
+
+const typeName = "Quota"
+
+// defaultMaxSources is the default maximum number of sources counted in a window.
+const defaultMaxSources = 100000
+
+// stores holds the request counts stores, keyed by middleware name, period, and store configuration,
+// so that the counts are kept when the middlewares are rebuilt on configuration reload.
+// A store is closed once it is no longer used by any middleware.
+var stores = newStores()
+
+func newStores() *middlewares.Shared[store] {
+	return middlewares.NewShared(func(st store) {
+		if err := st.Close(); err != nil {
+			log.Error().Err(err).Msg("Could not close quota store")
+		}
+	})
+}
+
+// quota counts the requests of each source over fixed calendar windows,
+// and rejects them once the limit of the current window is reached.
+type quota struct {
+	name          string
+	limit         int64
+	period        string
+	sourceMatcher utils.SourceExtractor
+	store         store
+	next          http.Handler
+}
+
+// New returns a quota middleware.
+// If info is not nil, the usage of the quota is reported to it.
+func New(ctx context.Context, next http.Handler, config dynamic.Quota, info *runtime.MiddlewareInfo, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	ctxLog := logger.WithContext(ctx)
+
+	if config.Limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than zero: %d", config.Limit)
+	}
+
+	period := config.Period
+	switch period {
+	case "":
+		period = dynamic.QuotaPeriodDay
+	case dynamic.QuotaPeriodMinute, dynamic.QuotaPeriodHour, dynamic.QuotaPeriodDay, dynamic.QuotaPeriodMonth:
+	default:
+		return nil, fmt.Errorf("unknown quota period %q", config.Period)
+	}
+
+	if config.SourceCriterion == nil ||
+		config.SourceCriterion.IPStrategy == nil &&
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+		config.SourceCriterion = &dynamic.SourceCriterion{
+			IPStrategy: &dynamic.IPStrategy{},
+		}
+	}
+
+	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSources := config.MaxSources
+	if maxSources <= 0 {
+		maxSources = defaultMaxSources
+	}
+
+	st, err := getStore(ctxLog, name, period, maxSources, config.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &quota{
+		name:          name,
+		limit:         config.Limit,
+		period:        period,
+		sourceMatcher: sourceMatcher,
+		store:         st,
+		next:          next,
+	}
+
+	if info != nil {
+		info.SetQuotaUsage(q.usage)
+	}
+
+	return q, nil
+}
+
+func (q *quota) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return q.name, tracing.SpanKindNoneEnum
+}
+
+func (q *quota) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), q.name, typeName)
+	ctx := logger.WithContext(req.Context())
+
+	source, _, err := q.sourceMatcher.Extract(req)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not extract source of request")
+		http.Error(rw, "could not extract source of request", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	start, end := window(q.period, now)
+
+	_, ok, err := q.store.Increment(ctx, start, source, q.limit)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not count request")
+		http.Error(rw, "could not count request", http.StatusInternalServerError)
+		return
+	}
+
+	if !ok {
+		logger.Debug().Msgf("Quota of %d requests per %s reached for source %s", q.limit, q.period, source)
+
+		rw.Header().Set("Retry-After", strconv.FormatFloat(math.Ceil(end.Sub(now).Seconds()), 'f', 0, 64))
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	q.next.ServeHTTP(rw, req)
+}
+
+// usage returns the usage of the quota over the current window.
+func (q *quota) usage(ctx context.Context) (*runtime.QuotaUsage, error) {
+	start, end := window(q.period, time.Now())
+
+	counts, err := q.store.Counts(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	return &runtime.QuotaUsage{
+		Limit:       q.limit,
+		Period:      q.period,
+		WindowStart: start,
+		WindowEnd:   end,
+		Sources:     counts,
+	}, nil
+}
+
+// window returns the bounds of the window of the given period containing t.
+// The windows are aligned on the calendar, in UTC.
+func window(period string, t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+
+	switch period {
+	case dynamic.QuotaPeriodMinute:
+		start := t.Truncate(time.Minute)
+		return start, start.Add(time.Minute)
+	case dynamic.QuotaPeriodHour:
+		start := t.Truncate(time.Hour)
+		return start, start.Add(time.Hour)
+	case dynamic.QuotaPeriodMonth:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+// getStore returns the request counts store of the given middleware, creating it if needed.
+// The store is released when the given context is done.
+func getStore(ctx context.Context, name, period string, maxSources int, config *dynamic.QuotaStore) (store, error) {
+	key := name + ":" + period + ":" + strconv.Itoa(maxSources)
+	if config != nil {
+		data, err := json.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling quota store configuration: %w", err)
+		}
+
+		key += ":" + string(data)
+	}
+
+	return stores.Acquire(ctx, key, func() (store, error) {
+		if config != nil && config.File != nil {
+			return newFileStore(ctx, config.File, period, maxSources)
+		}
+
+		return newMemoryStore(maxSources), nil
+	})
+}
@@ -0,0 +1,227 @@
+package quota
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/config/runtime"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.Quota
+		expErr bool
+	}{
+		{
+			desc:   "default period",
+			config: dynamic.Quota{Limit: 10},
+		},
+		{
+			desc:   "monthly quota",
+			config: dynamic.Quota{Limit: 10, Period: dynamic.QuotaPeriodMonth},
+		},
+		{
+			desc:   "no limit",
+			config: dynamic.Quota{Period: dynamic.QuotaPeriodDay},
+			expErr: true,
+		},
+		{
+			desc:   "unknown period",
+			config: dynamic.Quota{Limit: 10, Period: "week"},
+			expErr: true,
+		},
+		{
+			desc: "several source criteria",
+			config: dynamic.Quota{
+				Limit: 10,
+				SourceCriterion: &dynamic.SourceCriterion{
+					RequestHeaderName: "X-Api-Key",
+					RequestHost:       true,
+				},
+			},
+			expErr: true,
+		},
+		{
+			desc: "file store without path",
+			config: dynamic.Quota{
+				Limit: 10,
+				Store: &dynamic.QuotaStore{File: &dynamic.QuotaFileStore{}},
+			},
+			expErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, nil, "quota-"+test.desc)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestQuota(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	config := dynamic.Quota{
+		Limit:  2,
+		Period: dynamic.QuotaPeriodDay,
+		SourceCriterion: &dynamic.SourceCriterion{
+			RequestHeaderName: "X-Api-Key",
+		},
+	}
+
+	info := &runtime.MiddlewareInfo{Middleware: &dynamic.Middleware{Quota: &config}}
+
+	resetStores(t)
+
+	h, err := New(context.Background(), next, config, info, "quota-test")
+	require.NoError(t, err)
+
+	expected := []struct {
+		apiKey string
+		code   int
+	}{
+		{apiKey: "foo", code: http.StatusOK},
+		{apiKey: "foo", code: http.StatusOK},
+		{apiKey: "bar", code: http.StatusOK},
+		{apiKey: "foo", code: http.StatusTooManyRequests},
+		{apiKey: "bar", code: http.StatusOK},
+		{apiKey: "bar", code: http.StatusTooManyRequests},
+	}
+
+	for _, exp := range expected {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("X-Api-Key", exp.apiKey)
+
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+
+		assert.Equal(t, exp.code, recorder.Code)
+		if exp.code == http.StatusTooManyRequests {
+			assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+		}
+	}
+
+	usage, err := info.GetQuotaUsage(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, usage)
+
+	start, end := window(dynamic.QuotaPeriodDay, time.Now())
+
+	assert.Equal(t, &runtime.QuotaUsage{
+		Limit:       2,
+		Period:      dynamic.QuotaPeriodDay,
+		WindowStart: start,
+		WindowEnd:   end,
+		Sources:     map[string]int64{"foo": 2, "bar": 2},
+	}, usage)
+}
+
+func TestQuota_keptOnReload(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	resetStores(t)
+
+	config := dynamic.Quota{Limit: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h, err := New(ctx, next, config, nil, "quota-reload")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// The middleware is rebuilt on each configuration reload, and the previous one released.
+	h, err = New(context.Background(), next, config, nil, "quota-reload")
+	require.NoError(t, err)
+
+	cancel()
+
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	// The counts of another period are not shared.
+	config.Period = dynamic.QuotaPeriodHour
+
+	h, err = New(context.Background(), next, config, nil, "quota-reload")
+	require.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, 2, stores.Len())
+}
+
+func TestWindow(t *testing.T) {
+	now := time.Date(2023, time.December, 31, 23, 42, 12, 0, time.FixedZone("CET", 3600))
+
+	testCases := []struct {
+		period   string
+		expStart time.Time
+		expEnd   time.Time
+	}{
+		{
+			period:   dynamic.QuotaPeriodMinute,
+			expStart: time.Date(2023, time.December, 31, 22, 42, 0, 0, time.UTC),
+			expEnd:   time.Date(2023, time.December, 31, 22, 43, 0, 0, time.UTC),
+		},
+		{
+			period:   dynamic.QuotaPeriodHour,
+			expStart: time.Date(2023, time.December, 31, 22, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2023, time.December, 31, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			period:   dynamic.QuotaPeriodDay,
+			expStart: time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			period:   dynamic.QuotaPeriodMonth,
+			expStart: time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.period, func(t *testing.T) {
+			t.Parallel()
+
+			start, end := window(test.period, now)
+
+			assert.Equal(t, test.expStart, start)
+			assert.Equal(t, test.expEnd, end)
+		})
+	}
+}
+
+// resetStores replaces the request counts stores for the duration of the test.
+func resetStores(t *testing.T) {
+	t.Helper()
+
+	previous := stores
+	stores = newStores()
+
+	t.Cleanup(func() { stores = previous })
+}
@@ -0,0 +1,218 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+// store holds the request counts of the current window, keyed by source.
+type store interface {
+	// Increment increments the count of the given source in the window starting at the given time,
+	// unless it has already reached the limit.
+	// It returns the resulting count, and whether it has been incremented.
+	Increment(ctx context.Context, window time.Time, source string, limit int64) (int64, bool, error)
+	// Counts returns the counts of all the sources in the window starting at the given time.
+	Counts(ctx context.Context, window time.Time) (map[string]int64, error)
+	// Close releases the store once it is no longer used.
+	Close() error
+}
+
+// memoryStore stores the request counts in memory.
+// Only the counts of the latest window are kept.
+type memoryStore struct {
+	// maxSources is the maximum number of sources counted in a window.
+	maxSources int
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[string]int64
+}
+
+func newMemoryStore(maxSources int) *memoryStore {
+	return &memoryStore{
+		maxSources: maxSources,
+		counts:     make(map[string]int64),
+	}
+}
+
+func (m *memoryStore) Increment(ctx context.Context, window time.Time, source string, limit int64) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if window.After(m.window) {
+		m.window = window
+		m.counts = make(map[string]int64)
+	}
+
+	count, ok := m.counts[source]
+	if count >= limit {
+		return count, false, nil
+	}
+
+	// The requests of the new sources are rejected until the next window,
+	// as evicting the counts of the known ones would let them bypass the quota.
+	if !ok && len(m.counts) >= m.maxSources {
+		log.Ctx(ctx).Debug().Msgf("Maximum number of %d sources reached, rejecting source %s", m.maxSources, source)
+		return count, false, nil
+	}
+
+	count++
+	m.counts[source] = count
+
+	return count, true, nil
+}
+
+func (m *memoryStore) Counts(_ context.Context, window time.Time) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !window.Equal(m.window) {
+		return nil, nil
+	}
+
+	counts := make(map[string]int64, len(m.counts))
+	for source, count := range m.counts {
+		counts[source] = count
+	}
+
+	return counts, nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
+
+// fileContent is the content of the file of a fileStore.
+type fileContent struct {
+	Period string           `json:"period,omitempty"`
+	Window time.Time        `json:"window"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+// fileStore stores the request counts in memory, and persists them in a file, so that they survive restarts.
+// The counts are written at most once per flush interval, so the latest ones can be lost on a crash.
+type fileStore struct {
+	*memoryStore
+
+	ctx           context.Context
+	path          string
+	period        string
+	flushInterval time.Duration
+
+	flushMu      sync.Mutex
+	flushPending bool
+
+	// writeMu prevents concurrent writes of the file.
+	writeMu sync.Mutex
+}
+
+func newFileStore(ctx context.Context, config *dynamic.QuotaFileStore, period string, maxSources int) (*fileStore, error) {
+	if config.Path == "" {
+		return nil, errors.New("quota file store path is empty")
+	}
+
+	flushInterval := time.Duration(config.FlushInterval)
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	s := &fileStore{
+		memoryStore:   newMemoryStore(maxSources),
+		ctx:           ctx,
+		path:          config.Path,
+		period:        period,
+		flushInterval: flushInterval,
+	}
+
+	data, err := os.ReadFile(config.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading quota store file: %w", err)
+	}
+
+	var content fileContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("decoding quota store file %s: %w", config.Path, err)
+	}
+
+	// The counts of another period do not apply to the windows of the configured one.
+	if content.Period != "" && content.Period != period {
+		log.Ctx(ctx).Info().Str("path", config.Path).Msgf("Ignoring the request counts of the %s period", content.Period)
+		return s, nil
+	}
+
+	s.window = content.Window
+	if content.Counts != nil {
+		s.counts = content.Counts
+	}
+
+	return s, nil
+}
+
+func (s *fileStore) Increment(ctx context.Context, window time.Time, source string, limit int64) (int64, bool, error) {
+	count, ok, err := s.memoryStore.Increment(ctx, window, source, limit)
+	if ok {
+		s.scheduleFlush()
+	}
+
+	return count, ok, err
+}
+
+// scheduleFlush writes the request counts to the file after the flush interval, unless a write is already scheduled.
+func (s *fileStore) scheduleFlush() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	if s.flushPending {
+		return
+	}
+
+	s.flushPending = true
+
+	time.AfterFunc(s.flushInterval, func() {
+		s.flushMu.Lock()
+		s.flushPending = false
+		s.flushMu.Unlock()
+
+		if err := s.flush(); err != nil {
+			log.Ctx(s.ctx).Error().Err(err).Str("path", s.path).Msg("Could not write quota store file")
+		}
+	})
+}
+
+// Close writes the latest request counts to the file.
+func (s *fileStore) Close() error {
+	return s.flush()
+}
+
+// flush writes the request counts to the file.
+func (s *fileStore) flush() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	data, err := json.Marshal(fileContent{Period: s.period, Window: s.window, Counts: s.counts})
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// The file is replaced atomically, so that it is never left partially written.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,157 @@
+package quota
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore(10)
+
+	window := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	count, ok, err := store.Increment(ctx, window, "foo", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.True(t, ok)
+
+	count, ok, err = store.Increment(ctx, window, "foo", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.True(t, ok)
+
+	count, ok, err = store.Increment(ctx, window, "foo", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.False(t, ok)
+
+	counts, err := store.Counts(ctx, window)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"foo": 2}, counts)
+
+	// A new window resets the counts.
+	nextWindow := window.AddDate(0, 0, 1)
+
+	count, ok, err = store.Increment(ctx, nextWindow, "foo", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.True(t, ok)
+
+	counts, err = store.Counts(ctx, window)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func TestMemoryStore_maxSources(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore(2)
+
+	window := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, source := range []string{"foo", "bar"} {
+		_, ok, err := store.Increment(ctx, window, source, 2)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	// The new sources are rejected, while the known ones are still counted.
+	_, ok, err := store.Increment(ctx, window, "baz", 2)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	count, ok, err := store.Increment(ctx, window, "foo", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.True(t, ok)
+
+	// The new sources are counted again in the next window.
+	_, ok, err = store.Increment(ctx, window.AddDate(0, 0, 1), "baz", 2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "quota.json")
+
+	config := &dynamic.QuotaFileStore{
+		Path:          path,
+		FlushInterval: ptypes.Duration(10 * time.Millisecond),
+	}
+
+	store, err := newFileStore(ctx, config, dynamic.QuotaPeriodDay, 10)
+	require.NoError(t, err)
+
+	window := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		_, _, err = store.Increment(ctx, window, "foo", 10)
+		require.NoError(t, err)
+	}
+
+	_, _, err = store.Increment(ctx, window, "bar", 10)
+	require.NoError(t, err)
+
+	// The counts are restored from the file, as on a restart.
+	assert.Eventually(t, func() bool {
+		store.writeMu.Lock()
+		defer store.writeMu.Unlock()
+
+		restored, err := newFileStore(ctx, config, dynamic.QuotaPeriodDay, 10)
+		require.NoError(t, err)
+
+		counts, err := restored.Counts(ctx, window)
+		require.NoError(t, err)
+
+		return assert.ObjectsAreEqual(map[string]int64{"foo": 3, "bar": 1}, counts)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFileStore_invalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+
+	err := os.WriteFile(path, []byte("foo"), 0o600)
+	require.NoError(t, err)
+
+	_, err = newFileStore(context.Background(), &dynamic.QuotaFileStore{Path: path}, dynamic.QuotaPeriodDay, 10)
+	assert.Error(t, err)
+}
+
+func TestFileStore_otherPeriod(t *testing.T) {
+	ctx := context.Background()
+	config := &dynamic.QuotaFileStore{Path: filepath.Join(t.TempDir(), "quota.json")}
+
+	window := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := newFileStore(ctx, config, dynamic.QuotaPeriodMonth, 10)
+	require.NoError(t, err)
+
+	_, _, err = store.Increment(ctx, window, "foo", 10)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Close())
+
+	// The counts of the monthly windows are not restored for the daily ones.
+	restored, err := newFileStore(ctx, config, dynamic.QuotaPeriodDay, 10)
+	require.NoError(t, err)
+
+	counts, err := restored.Counts(ctx, window)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+
+	restored, err = newFileStore(ctx, config, dynamic.QuotaPeriodMonth, 10)
+	require.NoError(t, err)
+
+	counts, err = restored.Counts(ctx, window)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"foo": 1}, counts)
+}
@@ -0,0 +1,71 @@
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"golang.org/x/time/rate"
+)
+
+// inMemoryRateLimiter stores the token buckets in memory.
+type inMemoryRateLimiter struct {
+	rate  rate.Limit // reqs/s
+	burst int64
+	// maxDelay is the maximum duration we're willing to wait for a bucket reservation to become effective, in nanoseconds.
+	// For now it is somewhat arbitrarily set to 1/(2*rate).
+	maxDelay time.Duration
+	// each rate limiter for a given source is stored in the buckets ttlmap.
+	// To keep this ttlmap constrained in size,
+	// each ratelimiter is "garbage collected" when it is considered expired.
+	// It is considered expired after it hasn't been used for ttl seconds.
+	ttl int
+
+	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+}
+
+func newInMemoryRateLimiter(rate rate.Limit, burst int64, maxDelay time.Duration, ttl int) (*inMemoryRateLimiter, error) {
+	buckets, err := ttlmap.NewConcurrent(maxSources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inMemoryRateLimiter{
+		rate:     rate,
+		burst:    burst,
+		maxDelay: maxDelay,
+		ttl:      ttl,
+		buckets:  buckets,
+	}, nil
+}
+
+func (i *inMemoryRateLimiter) Allow(_ context.Context, source string) (reservation, error) {
+	var bucket *rate.Limiter
+	if rlSource, exists := i.buckets.Get(source); exists {
+		bucket = rlSource.(*rate.Limiter)
+	} else {
+		bucket = rate.NewLimiter(i.rate, int(i.burst))
+	}
+
+	// We Set even in the case where the source already exists,
+	// because we want to update the expiryTime everytime we get the source,
+	// as the expiryTime is supposed to reflect the activity (or lack thereof) on that source.
+	if err := i.buckets.Set(source, bucket, i.ttl); err != nil {
+		return reservation{}, fmt.Errorf("inserting/updating bucket: %w", err)
+	}
+
+	res := bucket.Reserve()
+	if !res.OK() {
+		// No bursty traffic allowed.
+		return reservation{}, nil
+	}
+
+	delay := res.Delay()
+	if delay > i.maxDelay {
+		res.Cancel()
+		return reservation{delay: delay, remaining: bucket.Tokens()}, nil
+	}
+
+	return reservation{ok: true, delay: delay, remaining: bucket.Tokens()}, nil
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/mailgun/ttlmap"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rs/zerolog/log"
 	"github.com/vulcand/oxy/v2/utils"
@@ -23,28 +25,49 @@ const (
 	maxSources = 65536
 )
 
+type serviceBuilder interface {
+	BuildHTTP(ctx context.Context, serviceName string) (http.Handler, error)
+}
+
+// limiter is a store of token buckets, keyed by source.
+type limiter interface {
+	// Allow reserves a token from the bucket of the given source, if it becomes available within maxDelay.
+	Allow(ctx context.Context, source string) (reservation, error)
+}
+
+// reservation is the outcome of a token reservation.
+type reservation struct {
+	// ok is whether a token has been reserved.
+	ok bool
+	// delay is the duration to wait before the reserved token becomes available,
+	// or, when no token has been reserved, the duration after which one would be.
+	delay time.Duration
+	// remaining is the number of tokens left in the bucket.
+	remaining float64
+}
+
 // rateLimiter implements rate limiting and traffic shaping with a set of token buckets;
 // one for each traffic source. The same parameters are applied to all the buckets.
 type rateLimiter struct {
-	name  string
-	rate  rate.Limit // reqs/s
-	burst int64
-	// maxDelay is the maximum duration we're willing to wait for a bucket reservation to become effective, in nanoseconds.
-	// For now it is somewhat arbitrarily set to 1/(2*rate).
-	maxDelay time.Duration
-	// each rate limiter for a given source is stored in the buckets ttlmap.
-	// To keep this ttlmap constrained in size,
-	// each ratelimiter is "garbage collected" when it is considered expired.
-	// It is considered expired after it hasn't been used for ttl seconds.
-	ttl           int
+	name          string
+	rate          rate.Limit // reqs/s
+	burst         int64
+	headers       bool
 	sourceMatcher utils.SourceExtractor
 	next          http.Handler
 
-	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+	limiter limiter
+
+	rejectionStatusCode  int
+	rejectionBody        []byte
+	rejectionContentType string
+	// rejectionHandler is the service serving the rejection response, if any.
+	rejectionHandler http.Handler
+	rejectionQuery   string
 }
 
 // New returns a rate limiter middleware.
-func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name string) (http.Handler, error) {
+func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, serviceBuilder serviceBuilder, name string) (http.Handler, error) {
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug().Msg("Creating middleware")
 
@@ -63,11 +86,6 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		return nil, err
 	}
 
-	buckets, err := ttlmap.NewConcurrent(maxSources)
-	if err != nil {
-		return nil, err
-	}
-
 	burst := config.Burst
 	if burst < 1 {
 		burst = 1
@@ -110,16 +128,60 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		ttl += int(1 / rtl)
 	}
 
-	return &rateLimiter{
-		name:          name,
-		rate:          rate.Limit(rtl),
-		burst:         burst,
-		maxDelay:      maxDelay,
-		next:          next,
-		sourceMatcher: sourceMatcher,
-		buckets:       buckets,
-		ttl:           ttl,
-	}, nil
+	localLimiter, err := newInMemoryRateLimiter(rate.Limit(rtl), burst, maxDelay, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	var limiter limiter = localLimiter
+	if config.Redis != nil {
+		limiter, err = newRedisLimiter(ctxLog, name, config.Redis, rate.Limit(rtl), burst, maxDelay, localLimiter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rl := &rateLimiter{
+		name:                name,
+		rate:                rate.Limit(rtl),
+		burst:               burst,
+		headers:             config.Headers,
+		next:                next,
+		sourceMatcher:       sourceMatcher,
+		limiter:             limiter,
+		rejectionStatusCode: http.StatusTooManyRequests,
+	}
+
+	if config.Rejection != nil {
+		if config.Rejection.StatusCode != 0 {
+			if config.Rejection.StatusCode < http.StatusBadRequest || config.Rejection.StatusCode > 599 {
+				return nil, fmt.Errorf("invalid rejection status code %d: must be a 4xx or 5xx status code", config.Rejection.StatusCode)
+			}
+
+			rl.rejectionStatusCode = config.Rejection.StatusCode
+		}
+
+		rl.rejectionBody = []byte(config.Rejection.Body)
+		rl.rejectionContentType = config.Rejection.ContentType
+		rl.rejectionQuery = config.Rejection.Query
+
+		if config.Rejection.Service != "" {
+			if serviceBuilder == nil {
+				return nil, fmt.Errorf("rejection service %q cannot be used without a service builder", config.Rejection.Service)
+			}
+
+			rl.rejectionHandler, err = serviceBuilder.BuildHTTP(ctx, config.Rejection.Service)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(rl.rejectionBody) == 0 {
+		rl.rejectionBody = []byte(http.StatusText(rl.rejectionStatusCode))
+	}
+
+	return rl, nil
 }
 
 func (rl *rateLimiter) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -141,45 +203,108 @@ func (rl *rateLimiter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		logger.Info().Msgf("ignoring token bucket amount > 1: %d", amount)
 	}
 
-	var bucket *rate.Limiter
-	if rlSource, exists := rl.buckets.Get(source); exists {
-		bucket = rlSource.(*rate.Limiter)
-	} else {
-		bucket = rate.NewLimiter(rl.rate, int(rl.burst))
-	}
-
-	// We Set even in the case where the source already exists,
-	// because we want to update the expiryTime everytime we get the source,
-	// as the expiryTime is supposed to reflect the activity (or lack thereof) on that source.
-	if err := rl.buckets.Set(source, bucket, rl.ttl); err != nil {
-		logger.Error().Err(err).Msg("Could not insert/update bucket")
-		http.Error(rw, "could not insert/update bucket", http.StatusInternalServerError)
+	res, err := rl.limiter.Allow(ctx, source)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not reserve token from bucket")
+		http.Error(rw, "could not reserve token from bucket", http.StatusInternalServerError)
 		return
 	}
 
-	res := bucket.Reserve()
-	if !res.OK() {
-		http.Error(rw, "No bursty traffic allowed", http.StatusTooManyRequests)
-		return
+	if rl.headers && rl.rate != rate.Inf {
+		rl.setHeaders(rw, res)
 	}
 
-	delay := res.Delay()
-	if delay > rl.maxDelay {
-		res.Cancel()
-		rl.serveDelayError(ctx, rw, delay)
+	if !res.ok {
+		rl.serveDelayError(ctx, rw, req, res.delay)
 		return
 	}
 
-	time.Sleep(delay)
+	time.Sleep(res.delay)
 	rl.next.ServeHTTP(rw, req)
 }
 
-func (rl *rateLimiter) serveDelayError(ctx context.Context, w http.ResponseWriter, delay time.Duration) {
+// setHeaders sets the RateLimit headers, computed from the state of the token bucket.
+// The limit is the burst, and the reset is the number of seconds until the bucket is full again.
+func (rl *rateLimiter) setHeaders(rw http.ResponseWriter, res reservation) {
+	remaining := math.Max(math.Floor(res.remaining), 0)
+
+	var reset float64
+	if missing := float64(rl.burst) - res.remaining; missing > 0 {
+		reset = math.Ceil(missing / float64(rl.rate))
+	}
+
+	rw.Header().Set("RateLimit-Limit", strconv.FormatInt(rl.burst, 10))
+	rw.Header().Set("RateLimit-Remaining", strconv.FormatFloat(remaining, 'f', 0, 64))
+	rw.Header().Set("RateLimit-Reset", strconv.FormatFloat(reset, 'f', 0, 64))
+}
+
+func (rl *rateLimiter) serveDelayError(ctx context.Context, w http.ResponseWriter, req *http.Request, delay time.Duration) {
 	w.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(delay.Seconds())))
 	w.Header().Set("X-Retry-In", delay.String())
-	w.WriteHeader(http.StatusTooManyRequests)
 
-	if _, err := w.Write([]byte(http.StatusText(http.StatusTooManyRequests))); err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("Could not serve 429")
+	if rl.rejectionHandler != nil {
+		rl.serveRejectionService(ctx, w, req)
+		return
+	}
+
+	if rl.rejectionContentType != "" {
+		w.Header().Set("Content-Type", rl.rejectionContentType)
 	}
+
+	w.WriteHeader(rl.rejectionStatusCode)
+
+	if _, err := w.Write(rl.rejectionBody); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("Could not serve %d", rl.rejectionStatusCode)
+	}
+}
+
+// serveRejectionService delegates the rejection response to the rejection service,
+// the way the errors middleware does for error pages.
+func (rl *rateLimiter) serveRejectionService(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	var query string
+	if len(rl.rejectionQuery) > 0 {
+		query = "/" + strings.TrimPrefix(rl.rejectionQuery, "/")
+		query = strings.ReplaceAll(query, "{status}", strconv.Itoa(rl.rejectionStatusCode))
+	}
+
+	rejectionURL, err := url.Parse("http://" + req.Host + query)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Could not build rejection request")
+		http.Error(w, http.StatusText(rl.rejectionStatusCode), rl.rejectionStatusCode)
+		return
+	}
+
+	rejectionReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, rejectionURL.String(), nil)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Could not build rejection request")
+		http.Error(w, http.StatusText(rl.rejectionStatusCode), rl.rejectionStatusCode)
+		return
+	}
+
+	rejectionReq.RequestURI = rejectionURL.RequestURI()
+	utils.CopyHeaders(rejectionReq.Header, req.Header)
+
+	rl.rejectionHandler.ServeHTTP(&codeModifier{ResponseWriter: w, code: rl.rejectionStatusCode}, rejectionReq)
+}
+
+// codeModifier forces the status code of the response written by the rejection service.
+type codeModifier struct {
+	http.ResponseWriter
+	code        int
+	headersSent bool
+}
+
+// WriteHeader writes the forced status code instead of the given one.
+func (c *codeModifier) WriteHeader(_ int) {
+	if c.headersSent {
+		return
+	}
+
+	c.headersSent = true
+	c.ResponseWriter.WriteHeader(c.code)
+}
+
+func (c *codeModifier) Write(b []byte) (int, error) {
+	c.WriteHeader(c.code)
+	return c.ResponseWriter.Write(b)
 }
@@ -93,7 +93,7 @@ func TestNewRateLimiter(t *testing.T) {
 
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
 
-			h, err := New(context.Background(), next, test.config, "rate-limiter")
+			h, err := New(context.Background(), next, test.config, nil, "rate-limiter")
 			if test.expectedError != "" {
 				assert.EqualError(t, err, test.expectedError)
 			} else {
@@ -102,7 +102,9 @@ func TestNewRateLimiter(t *testing.T) {
 
 			rtl, _ := h.(*rateLimiter)
 			if test.expectedMaxDelay != 0 {
-				assert.Equal(t, test.expectedMaxDelay, rtl.maxDelay)
+				limiter, ok := rtl.limiter.(*inMemoryRateLimiter)
+				require.True(t, ok, "Not an inMemoryRateLimiter")
+				assert.Equal(t, test.expectedMaxDelay, limiter.maxDelay)
 			}
 
 			if test.expectedSourceIP != "" {
@@ -131,7 +133,9 @@ func TestNewRateLimiter(t *testing.T) {
 				assert.Equal(t, test.requestHeader, hd)
 			}
 			if test.expectedRTL != 0 {
-				assert.Equal(t, test.expectedRTL, rtl.rate)
+				limiter, ok := rtl.limiter.(*inMemoryRateLimiter)
+				require.True(t, ok, "Not an inMemoryRateLimiter")
+				assert.Equal(t, test.expectedRTL, limiter.rate)
 			}
 		})
 	}
@@ -258,7 +262,7 @@ func TestRateLimit(t *testing.T) {
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				reqCount++
 			})
-			h, err := New(context.Background(), next, test.config, "rate-limiter")
+			h, err := New(context.Background(), next, test.config, nil, "rate-limiter")
 			require.NoError(t, err)
 
 			loadPeriod := time.Duration(1e9 / test.incomingLoad)
@@ -342,3 +346,155 @@ func computeMinCount(wantCount int) int {
 
 	return wantCount * 95 / 100
 }
+
+func TestRateLimit_headers(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	config := dynamic.RateLimit{
+		Average: 1,
+		Burst:   2,
+		Headers: true,
+	}
+
+	h, err := New(context.Background(), next, config, nil, "rate-limiter")
+	require.NoError(t, err)
+
+	expected := []struct {
+		code      int
+		remaining string
+		reset     string
+	}{
+		{code: http.StatusOK, remaining: "1", reset: "1"},
+		{code: http.StatusOK, remaining: "0", reset: "2"},
+		{code: http.StatusTooManyRequests, remaining: "0", reset: "2"},
+	}
+
+	for _, exp := range expected {
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+		assert.Equal(t, exp.code, recorder.Code)
+		assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, exp.remaining, recorder.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, exp.reset, recorder.Header().Get("RateLimit-Reset"))
+	}
+}
+
+func TestRateLimit_rejection(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		rejection      *dynamic.RateLimitRejection
+		backend        http.Handler
+		expCode        int
+		expBody        string
+		expContentType string
+	}{
+		{
+			desc:    "default rejection",
+			expCode: http.StatusTooManyRequests,
+			expBody: "Too Many Requests",
+		},
+		{
+			desc: "custom status code",
+			rejection: &dynamic.RateLimitRejection{
+				StatusCode: http.StatusServiceUnavailable,
+			},
+			expCode: http.StatusServiceUnavailable,
+			expBody: "Service Unavailable",
+		},
+		{
+			desc: "custom body",
+			rejection: &dynamic.RateLimitRejection{
+				Body:        `{"error":"slow down"}`,
+				ContentType: "application/json",
+			},
+			expCode:        http.StatusTooManyRequests,
+			expBody:        `{"error":"slow down"}`,
+			expContentType: "application/json",
+		},
+		{
+			desc: "rejection service",
+			rejection: &dynamic.RateLimitRejection{
+				Service: "rejection",
+				Query:   "/{status}.html",
+			},
+			backend: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusOK)
+				_, _ = fmt.Fprintf(w, "Rejection page at %s for %s", r.RequestURI, r.Header.Get("X-Foo"))
+			}),
+			expCode:        http.StatusTooManyRequests,
+			expBody:        "Rejection page at /429.html for bar",
+			expContentType: "text/html",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+			config := dynamic.RateLimit{
+				Average:   1,
+				Burst:     1,
+				Rejection: test.rejection,
+			}
+
+			h, err := New(context.Background(), next, config, &mockServiceBuilder{handler: test.backend}, "rate-limiter")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+			req.Header.Set("X-Foo", "bar")
+
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expCode, recorder.Code)
+			assert.Equal(t, test.expBody, recorder.Body.String())
+			assert.Equal(t, test.expContentType, recorder.Header().Get("Content-Type"))
+			assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestNew_rejectionServiceWithoutServiceBuilder(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	config := dynamic.RateLimit{
+		Average: 10,
+		Rejection: &dynamic.RateLimitRejection{
+			Service: "rejection",
+		},
+	}
+
+	_, err := New(context.Background(), next, config, nil, "rate-limiter")
+	assert.Error(t, err)
+}
+
+func TestNew_invalidRejectionStatusCode(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, statusCode := range []int{-1, 200, 302, 600} {
+		config := dynamic.RateLimit{
+			Average: 10,
+			Rejection: &dynamic.RateLimitRejection{
+				StatusCode: statusCode,
+			},
+		}
+
+		_, err := New(context.Background(), next, config, nil, "rate-limiter")
+		assert.Error(t, err, "status code %d", statusCode)
+	}
+}
+
+type mockServiceBuilder struct {
+	handler http.Handler
+}
+
+func (m *mockServiceBuilder) BuildHTTP(_ context.Context, _ string) (http.Handler, error) {
+	return m.handler, nil
+}
@@ -0,0 +1,223 @@
+package ratelimiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+)
+
+const redisKeyPrefix = "traefik:ratelimit:"
+
+// tokenBucketScript atomically reserves a token from the bucket stored at KEYS[1].
+// The bucket is a hash holding the number of available tokens and the time of the last update, in microseconds.
+// Like golang.org/x/time/rate reservations, a token can be reserved ahead of time,
+// in which case the number of available tokens becomes negative.
+// It returns whether the token has been reserved, the delay in microseconds before it becomes available,
+// and the number of tokens left in the bucket.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local maxDelay = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1]) or burst
+local last = tonumber(bucket[2]) or now
+
+if now > last then
+  tokens = math.min(burst, tokens + (now - last) * rate / 1000000)
+  last = now
+end
+
+local delay = 0
+if tokens < 1 then
+  delay = math.ceil((1 - tokens) * 1000000 / rate)
+end
+
+if delay > maxDelay then
+  return {0, delay, math.floor(tokens)}
+end
+
+tokens = tokens - 1
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'last', last)
+redis.call('PEXPIRE', KEYS[1], ttl)
+
+return {1, delay, math.floor(tokens)}
+`)
+
+// redisClients holds the Redis clients, keyed by configuration,
+// so that the middlewares rebuilt on each configuration reload share the same connection pool.
+// A client is closed once it is no longer used by any middleware.
+var redisClients = middlewares.NewShared(func(client redis.UniversalClient) {
+	if err := client.Close(); err != nil {
+		log.Debug().Err(err).Msg("Error while closing Redis client")
+	}
+})
+
+// redisLimiter stores the token buckets in Redis,
+// so that they are shared between all the Traefik instances using the same Redis server.
+type redisLimiter struct {
+	name     string
+	rate     rate.Limit // reqs/s
+	burst    int64
+	maxDelay time.Duration
+	// ttl is the duration after which an unused bucket is full again, and can therefore be removed.
+	ttl     time.Duration
+	timeout time.Duration
+
+	client        redis.Scripter
+	failurePolicy string
+	// fallback is the limiter used when Redis is unreachable, with the local failure policy.
+	fallback limiter
+
+	// unreachable is whether the last request to Redis failed,
+	// so that the failures are only logged when Redis becomes unreachable.
+	unreachable atomic.Bool
+}
+
+func newRedisLimiter(ctx context.Context, name string, config *dynamic.Redis, limit rate.Limit, burst int64, maxDelay time.Duration, fallback limiter) (*redisLimiter, error) {
+	failurePolicy := config.FailurePolicy
+	switch failurePolicy {
+	case "":
+		failurePolicy = dynamic.RedisFailurePolicyLocal
+	case dynamic.RedisFailurePolicyAllow, dynamic.RedisFailurePolicyDeny, dynamic.RedisFailurePolicyLocal:
+	default:
+		return nil, fmt.Errorf("unknown Redis failure policy %q", config.FailurePolicy)
+	}
+
+	client, err := getRedisClient(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = 500 * time.Millisecond
+	}
+
+	ttl := time.Second
+	if limit > 0 && limit != rate.Inf {
+		ttl += time.Duration(float64(burst) / float64(limit) * float64(time.Second))
+	}
+
+	return &redisLimiter{
+		name:          name,
+		rate:          limit,
+		burst:         burst,
+		maxDelay:      maxDelay,
+		ttl:           ttl,
+		timeout:       timeout,
+		client:        client,
+		failurePolicy: failurePolicy,
+		fallback:      fallback,
+	}, nil
+}
+
+func (r *redisLimiter) Allow(ctx context.Context, source string) (reservation, error) {
+	if r.rate == rate.Inf {
+		// No rate limiting.
+		return reservation{ok: true, remaining: float64(r.burst)}, nil
+	}
+
+	res, err := r.reserve(ctx, source)
+	if err == nil {
+		if r.unreachable.CompareAndSwap(true, false) {
+			log.Ctx(ctx).Info().Msg("Redis is reachable again")
+		}
+
+		return res, nil
+	}
+
+	if r.unreachable.CompareAndSwap(false, true) {
+		log.Ctx(ctx).Warn().Err(err).Str("failurePolicy", r.failurePolicy).Msg("Could not reserve token from Redis, applying the failure policy until Redis is reachable again")
+	} else {
+		log.Ctx(ctx).Debug().Err(err).Msg("Could not reserve token from Redis")
+	}
+
+	switch r.failurePolicy {
+	case dynamic.RedisFailurePolicyAllow:
+		return reservation{ok: true, remaining: float64(r.burst)}, nil
+	case dynamic.RedisFailurePolicyDeny:
+		// The request is rejected like a rate limited one, and can be retried once a token would be available.
+		return reservation{delay: time.Duration(float64(time.Second) / float64(r.rate))}, nil
+	default:
+		return r.fallback.Allow(ctx, source)
+	}
+}
+
+func (r *redisLimiter) reserve(ctx context.Context, source string) (reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	keys := []string{redisKeyPrefix + r.name + ":" + source}
+	args := []interface{}{
+		float64(r.rate),
+		r.burst,
+		r.maxDelay.Microseconds(),
+		r.ttl.Milliseconds(),
+	}
+
+	res, err := tokenBucketScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return reservation{}, fmt.Errorf("running token bucket script: %w", err)
+	}
+
+	if len(res) != 3 {
+		return reservation{}, fmt.Errorf("unexpected token bucket script result: %v", res)
+	}
+
+	return reservation{
+		ok:        res[0] == 1,
+		delay:     time.Duration(res[1]) * time.Microsecond,
+		remaining: float64(res[2]),
+	}, nil
+}
+
+// getRedisClient returns the Redis client for the given configuration, creating it if needed.
+func getRedisClient(ctx context.Context, config *dynamic.Redis) (redis.UniversalClient, error) {
+	key, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling Redis configuration: %w", err)
+	}
+
+	return redisClients.Acquire(ctx, string(key), func() (redis.UniversalClient, error) {
+		return newRedisClient(ctx, config)
+	})
+}
+
+func newRedisClient(ctx context.Context, config *dynamic.Redis) (redis.UniversalClient, error) {
+	options := &redis.UniversalOptions{
+		Addrs:    config.Endpoints,
+		Username: config.Username,
+		Password: config.Password,
+		DB:       config.DB,
+	}
+
+	if config.Timeout > 0 {
+		options.DialTimeout = time.Duration(config.Timeout)
+		options.ReadTimeout = time.Duration(config.Timeout)
+		options.WriteTimeout = time.Duration(config.Timeout)
+	}
+
+	if config.TLS != nil {
+		var err error
+		options.TLSConfig, err = config.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("creating Redis client TLS configuration: %w", err)
+		}
+	}
+
+	return redis.NewUniversalClient(options), nil
+}
@@ -0,0 +1,415 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lua "github.com/yuin/gopher-lua"
+	"golang.org/x/time/rate"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		failurePolicy string
+		result        []interface{}
+		err           error
+		expDelay      time.Duration
+		expOK         bool
+		expRemaining  float64
+		expFallback   bool
+	}{
+		{
+			desc:         "token available",
+			result:       []interface{}{int64(1), int64(0), int64(19)},
+			expOK:        true,
+			expRemaining: 19,
+		},
+		{
+			desc:         "token available after a delay",
+			result:       []interface{}{int64(1), int64(1000), int64(-1)},
+			expDelay:     time.Millisecond,
+			expOK:        true,
+			expRemaining: -1,
+		},
+		{
+			desc:     "token not available",
+			result:   []interface{}{int64(0), int64(2000000), int64(0)},
+			expDelay: 2 * time.Second,
+		},
+		{
+			desc:          "Redis unreachable with allow policy",
+			failurePolicy: dynamic.RedisFailurePolicyAllow,
+			err:           errors.New("connection refused"),
+			expOK:         true,
+			expRemaining:  20,
+		},
+		{
+			desc:          "Redis unreachable with deny policy",
+			failurePolicy: dynamic.RedisFailurePolicyDeny,
+			err:           errors.New("connection refused"),
+			expDelay:      100 * time.Millisecond,
+		},
+		{
+			desc:          "Redis unreachable with local policy",
+			failurePolicy: dynamic.RedisFailurePolicyLocal,
+			err:           errors.New("connection refused"),
+			expOK:         true,
+			expFallback:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &scripterMock{result: test.result, err: test.err}
+			fallback := &limiterMock{}
+
+			limiter := &redisLimiter{
+				name:          "test",
+				rate:          rate.Limit(10),
+				burst:         20,
+				maxDelay:      50 * time.Millisecond,
+				ttl:           3 * time.Second,
+				timeout:       time.Second,
+				client:        client,
+				failurePolicy: test.failurePolicy,
+				fallback:      fallback,
+			}
+
+			res, err := limiter.Allow(context.Background(), "127.0.0.1")
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expDelay, res.delay)
+			assert.Equal(t, test.expOK, res.ok)
+			assert.Equal(t, test.expRemaining, res.remaining)
+			assert.Equal(t, test.expFallback, fallback.called)
+
+			assert.Equal(t, []string{"traefik:ratelimit:test:127.0.0.1"}, client.keys)
+			assert.Equal(t, []interface{}{float64(10), int64(20), int64(50000), int64(3000)}, client.args)
+		})
+	}
+}
+
+func TestRedisLimiter_noRateLimit(t *testing.T) {
+	client := &scripterMock{err: errors.New("unexpected call")}
+
+	limiter := &redisLimiter{
+		rate:   rate.Inf,
+		client: client,
+	}
+
+	res, err := limiter.Allow(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Duration(0), res.delay)
+	assert.True(t, res.ok)
+	assert.Nil(t, client.keys)
+}
+
+func TestRedisLimiter_tokenBucketScript(t *testing.T) {
+	client := newLuaScripter(time.Unix(1700000000, 0))
+
+	limiter := &redisLimiter{
+		name:     "test",
+		rate:     rate.Limit(10),
+		burst:    2,
+		maxDelay: 50 * time.Millisecond,
+		ttl:      3 * time.Second,
+		timeout:  time.Second,
+		client:   client,
+	}
+
+	testCases := []struct {
+		desc         string
+		elapsed      time.Duration
+		maxDelay     time.Duration
+		expDelay     time.Duration
+		expOK        bool
+		expRemaining float64
+	}{
+		{
+			desc:         "first token of the burst",
+			expOK:        true,
+			expRemaining: 1,
+		},
+		{
+			desc:         "last token of the burst",
+			expOK:        true,
+			expRemaining: 0,
+		},
+		{
+			desc:     "no token available",
+			expDelay: 100 * time.Millisecond,
+		},
+		{
+			desc:         "token available after its delay",
+			elapsed:      100 * time.Millisecond,
+			expOK:        true,
+			expRemaining: 0,
+		},
+		{
+			desc:         "token reserved ahead of time",
+			elapsed:      50 * time.Millisecond,
+			maxDelay:     100 * time.Millisecond,
+			expDelay:     50 * time.Millisecond,
+			expOK:        true,
+			expRemaining: -1,
+		},
+		{
+			desc:         "bucket refilled up to the burst",
+			elapsed:      time.Minute,
+			expOK:        true,
+			expRemaining: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		client.now = client.now.Add(test.elapsed)
+
+		limiter.maxDelay = 50 * time.Millisecond
+		if test.maxDelay > 0 {
+			limiter.maxDelay = test.maxDelay
+		}
+
+		res, err := limiter.Allow(context.Background(), "127.0.0.1")
+		require.NoError(t, err, test.desc)
+
+		assert.Equal(t, test.expDelay, res.delay, test.desc)
+		assert.Equal(t, test.expOK, res.ok, test.desc)
+		assert.Equal(t, test.expRemaining, res.remaining, test.desc)
+	}
+
+	assert.Equal(t, map[string]time.Duration{"traefik:ratelimit:test:127.0.0.1": 3 * time.Second}, client.ttls)
+}
+
+func TestRateLimit_redis(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	config := dynamic.RateLimit{
+		Average: 10,
+		Burst:   1,
+		Redis: &dynamic.Redis{
+			Endpoints:     []string{"127.0.0.1:6379"},
+			FailurePolicy: dynamic.RedisFailurePolicyDeny,
+		},
+	}
+
+	h, err := New(context.Background(), next, config, nil, "rate-limiter")
+	require.NoError(t, err)
+
+	rtl, ok := h.(*rateLimiter)
+	require.True(t, ok)
+
+	limiter, ok := rtl.limiter.(*redisLimiter)
+	require.True(t, ok, "Not a redisLimiter")
+
+	limiter.client = &scripterMock{result: []interface{}{int64(0), int64(1500000), int64(0)}}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+
+	limiter.client = &scripterMock{err: errors.New("connection refused")}
+
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+}
+
+func TestNew_invalidRedisFailurePolicy(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	config := dynamic.RateLimit{
+		Average: 10,
+		Redis: &dynamic.Redis{
+			Endpoints:     []string{"127.0.0.1:6379"},
+			FailurePolicy: "foo",
+		},
+	}
+
+	_, err := New(context.Background(), next, config, nil, "rate-limiter")
+	assert.Error(t, err)
+}
+
+// scripterMock returns the configured result to any script evaluation, and records the last keys and arguments.
+type scripterMock struct {
+	result []interface{}
+	err    error
+
+	keys []string
+	args []interface{}
+}
+
+func (s *scripterMock) Eval(_ context.Context, _ string, keys []string, args ...interface{}) *redis.Cmd {
+	s.keys = keys
+	s.args = args
+
+	return redis.NewCmdResult(s.result, s.err)
+}
+
+func (s *scripterMock) EvalSha(ctx context.Context, _ string, keys []string, args ...interface{}) *redis.Cmd {
+	return s.Eval(ctx, "", keys, args...)
+}
+
+func (s *scripterMock) ScriptExists(_ context.Context, hashes ...string) *redis.BoolSliceCmd {
+	return redis.NewBoolSliceResult(make([]bool, len(hashes)), nil)
+}
+
+func (s *scripterMock) ScriptLoad(_ context.Context, _ string) *redis.StringCmd {
+	return redis.NewStringResult("", nil)
+}
+
+// luaScripter runs the scripts with a Lua interpreter, against an in-memory store of hashes,
+// implementing the few Redis commands used by the token bucket script,
+// and converting their arguments and replies as Redis does.
+type luaScripter struct {
+	now    time.Time
+	hashes map[string]map[string]string
+	ttls   map[string]time.Duration
+}
+
+func newLuaScripter(now time.Time) *luaScripter {
+	return &luaScripter{
+		now:    now,
+		hashes: make(map[string]map[string]string),
+		ttls:   make(map[string]time.Duration),
+	}
+}
+
+func (s *luaScripter) Eval(_ context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	state := lua.NewState()
+	defer state.Close()
+
+	luaKeys := state.NewTable()
+	for _, key := range keys {
+		luaKeys.Append(lua.LString(key))
+	}
+	state.SetGlobal("KEYS", luaKeys)
+
+	luaArgs := state.NewTable()
+	for _, arg := range args {
+		luaArgs.Append(lua.LString(fmt.Sprint(arg)))
+	}
+	state.SetGlobal("ARGV", luaArgs)
+
+	luaRedis := state.NewTable()
+	luaRedis.RawSetString("call", state.NewFunction(s.call))
+	state.SetGlobal("redis", luaRedis)
+
+	if err := state.DoString(script); err != nil {
+		return redis.NewCmdResult(nil, err)
+	}
+
+	return redis.NewCmdResult(toRedisReply(state.Get(-1)), nil)
+}
+
+func (s *luaScripter) EvalSha(_ context.Context, _ string, _ []string, _ ...interface{}) *redis.Cmd {
+	return redis.NewCmdResult(nil, errors.New("NOSCRIPT No matching script. Please use EVAL."))
+}
+
+func (s *luaScripter) ScriptExists(_ context.Context, hashes ...string) *redis.BoolSliceCmd {
+	return redis.NewBoolSliceResult(make([]bool, len(hashes)), nil)
+}
+
+func (s *luaScripter) ScriptLoad(_ context.Context, _ string) *redis.StringCmd {
+	return redis.NewStringResult("", nil)
+}
+
+func (s *luaScripter) call(state *lua.LState) int {
+	switch command := strings.ToUpper(state.CheckString(1)); command {
+	case "TIME":
+		reply := state.NewTable()
+		reply.Append(lua.LString(strconv.FormatInt(s.now.Unix(), 10)))
+		reply.Append(lua.LString(strconv.Itoa(s.now.Nanosecond() / 1000)))
+		state.Push(reply)
+
+	case "HMGET":
+		hash := s.hashes[state.CheckString(2)]
+
+		reply := state.NewTable()
+		for i := 3; i <= state.GetTop(); i++ {
+			value, ok := hash[state.CheckString(i)]
+			if !ok {
+				// Redis converts the nil replies to false.
+				reply.Append(lua.LFalse)
+				continue
+			}
+
+			reply.Append(lua.LString(value))
+		}
+		state.Push(reply)
+
+	case "HSET":
+		key := state.CheckString(2)
+		if s.hashes[key] == nil {
+			s.hashes[key] = make(map[string]string)
+		}
+
+		var added int
+		for i := 3; i < state.GetTop(); i += 2 {
+			field := state.CheckString(i)
+			if _, ok := s.hashes[key][field]; !ok {
+				added++
+			}
+
+			s.hashes[key][field] = state.CheckString(i + 1)
+		}
+		state.Push(lua.LNumber(added))
+
+	case "PEXPIRE":
+		s.ttls[state.CheckString(2)] = time.Duration(state.CheckInt64(3)) * time.Millisecond
+		state.Push(lua.LNumber(1))
+
+	default:
+		state.RaiseError("unsupported command %q", command)
+	}
+
+	return 1
+}
+
+// toRedisReply converts a Lua value to a Redis reply:
+// the numbers are truncated to integers, and the tables are converted to arrays.
+func toRedisReply(value lua.LValue) interface{} {
+	switch v := value.(type) {
+	case lua.LNumber:
+		return int64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		reply := make([]interface{}, 0, v.Len())
+		for i := 1; i <= v.Len(); i++ {
+			reply = append(reply, toRedisReply(v.RawGetInt(i)))
+		}
+		return reply
+	default:
+		return nil
+	}
+}
+
+type limiterMock struct {
+	called bool
+}
+
+func (l *limiterMock) Allow(_ context.Context, _ string) (reservation, error) {
+	l.called = true
+
+	return reservation{ok: true}, nil
+}
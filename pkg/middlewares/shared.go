@@ -0,0 +1,110 @@
+package middlewares
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultSharedGracePeriod is the default duration during which a resource no longer used is kept,
+// before being closed.
+const DefaultSharedGracePeriod = time.Minute
+
+// Shared holds resources, such as connection pools or stores, shared by the middlewares
+// built on successive dynamic configuration reloads, and keyed by their configuration.
+// A resource is acquired by a middleware for the lifetime of the context it is built with,
+// which is canceled when the configuration is reloaded.
+// Once a resource is no longer used by any middleware, it is closed after a grace period,
+// which lets the middlewares of the new configuration reuse it,
+// and the requests still handled by the previous ones complete.
+type Shared[T any] struct {
+	mu        sync.Mutex
+	resources map[string]*sharedResource[T]
+
+	closeFn     func(T)
+	gracePeriod time.Duration
+}
+
+type sharedResource[T any] struct {
+	value T
+	refs  int
+	// timer is the pending close of the resource, if it is no longer used.
+	timer *time.Timer
+}
+
+// NewShared creates a new Shared, closing the resources no longer used with the given function, if any.
+func NewShared[T any](closeFn func(T)) *Shared[T] {
+	return &Shared[T]{
+		resources:   make(map[string]*sharedResource[T]),
+		closeFn:     closeFn,
+		gracePeriod: DefaultSharedGracePeriod,
+	}
+}
+
+// Acquire returns the resource for the given key, creating it with the given function if needed.
+// The resource is released when the given context is done.
+func (s *Shared[T]) Acquire(ctx context.Context, key string, create func() (T, error)) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, ok := s.resources[key]
+	if !ok {
+		value, err := create()
+		if err != nil {
+			return value, err
+		}
+
+		res = &sharedResource[T]{value: value}
+		s.resources[key] = res
+	}
+
+	if res.timer != nil {
+		res.timer.Stop()
+		res.timer = nil
+	}
+
+	res.refs++
+
+	if done := ctx.Done(); done != nil {
+		go func() {
+			<-done
+			s.release(key, res)
+		}()
+	}
+
+	return res.value, nil
+}
+
+// Len returns the number of resources held.
+func (s *Shared[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.resources)
+}
+
+func (s *Shared[T]) release(key string, res *sharedResource[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res.refs--
+	if res.refs > 0 {
+		return
+	}
+
+	res.timer = time.AfterFunc(s.gracePeriod, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// The resource has been acquired again in the meantime.
+		if res.refs > 0 || s.resources[key] != res {
+			return
+		}
+
+		delete(s.resources, key)
+
+		if s.closeFn != nil {
+			s.closeFn(res.value)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShared(t *testing.T) {
+	var mu sync.Mutex
+	var closed []string
+
+	shared := NewShared(func(value string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		closed = append(closed, value)
+	})
+	shared.gracePeriod = 50 * time.Millisecond
+
+	created := 0
+	create := func(value string) func() (string, error) {
+		return func() (string, error) {
+			created++
+			return value, nil
+		}
+	}
+
+	// The middlewares of the first configuration share the same resource.
+	ctx1, cancel1 := context.WithCancel(context.Background())
+
+	value, err := shared.Acquire(ctx1, "foo", create("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", value)
+
+	value, err = shared.Acquire(ctx1, "foo", create("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", value)
+
+	value, err = shared.Acquire(ctx1, "bar", create("bar"))
+	require.NoError(t, err)
+	assert.Equal(t, "bar", value)
+
+	assert.Equal(t, 2, created)
+
+	// The configuration is reloaded, and the middlewares of the new one only use the first resource.
+	cancel1()
+
+	value, err = shared.Acquire(context.Background(), "foo", create("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", value)
+	assert.Equal(t, 2, created)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(closed) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"bar"}, closed)
+	assert.Equal(t, 1, shared.Len())
+}
+
+func TestShared_createError(t *testing.T) {
+	shared := NewShared[string](nil)
+
+	_, err := shared.Acquire(context.Background(), "foo", func() (string, error) {
+		return "", errors.New("boom")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, 0, shared.Len())
+}
@@ -8,6 +8,7 @@ import (
 
 	"traefik/v3/pkg/config/dynamic"
 	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
 	"traefik/v3/pkg/tcp"
 )
 
@@ -49,6 +50,7 @@ func (i *inFlightConn) ServeTCP(conn tcp.WriteCloser) {
 
 	if err = i.increment(ip); err != nil {
 		logger.Error().Err(err).Msg("Connection rejected")
+		accesslog.SetTCPCloseReason(conn, fmt.Sprintf("rejected by %s: %v", i.name, err))
 		conn.Close()
 		return
 	}
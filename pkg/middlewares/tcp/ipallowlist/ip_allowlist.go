@@ -8,6 +8,7 @@ import (
 	"traefik/v3/pkg/config/dynamic"
 	"traefik/v3/pkg/ip"
 	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
 	"traefik/v3/pkg/tcp"
 )
 
@@ -53,6 +54,7 @@ func (al *ipAllowLister) ServeTCP(conn tcp.WriteCloser) {
 	err := al.allowLister.IsAuthorized(addr)
 	if err != nil {
 		logger.Error().Err(err).Msgf("Connection from %s rejected", addr)
+		accesslog.SetTCPCloseReason(conn, fmt.Sprintf("rejected by %s: %v", al.name, err))
 		conn.Close()
 		return
 	}
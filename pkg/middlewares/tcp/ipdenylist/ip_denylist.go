@@ -0,0 +1,107 @@
+package ipdenylist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/ip"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/tcp"
+)
+
+const (
+	typeName = "IPDenyListerTCP"
+)
+
+// ipDenyLister is a middleware that provides Checks of the Requesting IP against a set of Denylists.
+type ipDenyLister struct {
+	next       tcp.Handler
+	denyLister *ip.Checker
+	rangesFile *ip.RangesFile
+	name       string
+}
+
+// New builds a new TCP IPDenyLister given a list of CIDR-Strings to deny.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPIPDenyList, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if len(config.SourceRange) == 0 && config.SourceRangeFile == "" {
+		return nil, errors.New("sourceRange and sourceRangeFile are empty, IPDenyLister not created")
+	}
+
+	dl := &ipDenyLister{
+		next: next,
+		name: name,
+	}
+
+	if len(config.SourceRange) > 0 {
+		checker, err := ip.NewChecker(config.SourceRange)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDRs %s: %w", config.SourceRange, err)
+		}
+		dl.denyLister = checker
+	}
+
+	if config.SourceRangeFile != "" {
+		rangesFile, err := ip.WatchRangesFile(config.SourceRangeFile)
+		if err != nil {
+			return nil, err
+		}
+		dl.rangesFile = rangesFile
+	}
+
+	logger.Debug().Msgf("Setting up IPDenyLister with sourceRange: %s and sourceRangeFile: %s", config.SourceRange, config.SourceRangeFile)
+
+	return dl, nil
+}
+
+func (dl *ipDenyLister) ServeTCP(conn tcp.WriteCloser) {
+	logger := middlewares.GetLogger(context.Background(), dl.name, typeName)
+
+	addr := conn.RemoteAddr().String()
+
+	denied, err := dl.isDenied(addr)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Connection from %s rejected", addr)
+		accesslog.SetTCPCloseReason(conn, fmt.Sprintf("rejected by %s: %v", dl.name, err))
+		conn.Close()
+		return
+	}
+
+	if denied {
+		logger.Debug().Msgf("Connection from %s rejected", addr)
+		accesslog.SetTCPCloseReason(conn, fmt.Sprintf("rejected by %s: IP denied", dl.name))
+		conn.Close()
+		return
+	}
+
+	logger.Debug().Msgf("Connection from %s accepted", addr)
+
+	dl.next.ServeTCP(conn)
+}
+
+// isDenied checks if provided address is in the denied IPs.
+func (dl *ipDenyLister) isDenied(addr string) (bool, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	if dl.denyLister != nil {
+		denied, err := dl.denyLister.Contains(host)
+		if err != nil || denied {
+			return denied, err
+		}
+	}
+
+	if dl.rangesFile != nil {
+		return dl.rangesFile.Contains(host)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,149 @@
+// Package ratelimiter implements a rate limiting middleware for the new TCP connections,
+// with a set of token buckets, and a throttling of the throughput of each connection.
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mailgun/ttlmap"
+	"golang.org/x/time/rate"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/tcp"
+)
+
+const (
+	typeName   = "RateLimiterTCP"
+	maxSources = 65536
+)
+
+// rateLimiter limits the rate of the new connections with a set of token buckets,
+// one for each remote IP. The same parameters are applied to all the buckets.
+type rateLimiter struct {
+	name string
+	next tcp.Handler
+
+	rate  rate.Limit // conns/s
+	burst int64
+	// each bucket is "garbage collected" after it hasn't been used for ttl seconds.
+	ttl     int
+	buckets *ttlmap.TtlMap // actual buckets, keyed by remote IP.
+
+	bytesPerSecond int64
+
+	rejectedConns metrics.Counter
+}
+
+// New returns a rate limiter middleware.
+// The connections are identified and grouped by remote IP.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPRateLimit, name string, rejectedConns metrics.Counter) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.Average < 0 {
+		return nil, fmt.Errorf("negative value not valid for average: %d", config.Average)
+	}
+
+	if config.BytesPerSecond < 0 {
+		return nil, fmt.Errorf("negative value not valid for bytesPerSecond: %d", config.BytesPerSecond)
+	}
+
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	period := time.Duration(config.Period)
+	if period < 0 {
+		return nil, fmt.Errorf("negative value not valid for period: %v", period)
+	}
+	if period == 0 {
+		period = time.Second
+	}
+
+	rl := &rateLimiter{
+		name:           name,
+		next:           next,
+		burst:          burst,
+		bytesPerSecond: config.BytesPerSecond,
+		rejectedConns:  rejectedConns.With("middleware", name),
+	}
+
+	if config.Average > 0 {
+		rl.rate = rate.Limit(float64(config.Average*int64(time.Second)) / float64(period))
+
+		// Make the ttl inversely proportional to how often a bucket is supposed to see any activity (when maxed out),
+		// for the low rates, with an extra second for continuity with the high rates.
+		rl.ttl = 2
+		if rl.rate < 1 {
+			rl.ttl = 1 + int(1/rl.rate)
+		}
+
+		var err error
+		rl.buckets, err = ttlmap.NewConcurrent(maxSources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rl, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (rl *rateLimiter) ServeTCP(conn tcp.WriteCloser) {
+	logger := middlewares.GetLogger(context.Background(), rl.name, typeName)
+
+	if rl.buckets != nil {
+		ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			logger.Error().Err(err).Msg("Cannot parse IP from remote addr")
+			conn.Close()
+			return
+		}
+
+		allowed, err := rl.allow(ip)
+		if err != nil {
+			logger.Error().Err(err).Msg("Cannot check the connection rate")
+		}
+
+		if !allowed {
+			logger.Debug().Msgf("Connection rejected, rate limit reached for %s", ip)
+			rl.rejectedConns.Add(1)
+			accesslog.SetTCPCloseReason(conn, fmt.Sprintf("rejected by %s: rate limit reached", rl.name))
+			conn.Close()
+			return
+		}
+	}
+
+	if rl.bytesPerSecond > 0 {
+		conn = newThrottledConn(conn, rl.bytesPerSecond)
+	}
+
+	rl.next.ServeTCP(conn)
+}
+
+// allow reports whether a new connection can be opened for the given IP,
+// and takes a token from its bucket if so.
+// The connections are allowed when the bucket cannot be stored.
+func (rl *rateLimiter) allow(ip string) (bool, error) {
+	var bucket *rate.Limiter
+	if rlSource, exists := rl.buckets.Get(ip); exists {
+		bucket = rlSource.(*rate.Limiter)
+	} else {
+		bucket = rate.NewLimiter(rl.rate, int(rl.burst))
+	}
+
+	// We Set even in the case where the source already exists,
+	// because we want to update the expiryTime everytime we get the source,
+	// as the expiryTime is supposed to reflect the activity (or lack thereof) on that source.
+	if err := rl.buckets.Set(ip, bucket, rl.ttl); err != nil {
+		return true, fmt.Errorf("inserting/updating bucket: %w", err)
+	}
+
+	return bucket.Allow(), nil
+}
@@ -0,0 +1,143 @@
+package ratelimiter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/tcp"
+	"traefik/v3/pkg/testhelpers"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.TCPRateLimit
+		expectedError bool
+	}{
+		{
+			desc:   "valid",
+			config: dynamic.TCPRateLimit{Average: 10, Burst: 5, BytesPerSecond: 1024},
+		},
+		{
+			desc:          "negative average",
+			config:        dynamic.TCPRateLimit{Average: -1},
+			expectedError: true,
+		},
+		{
+			desc:          "negative period",
+			config:        dynamic.TCPRateLimit{Average: 1, Period: ptypes.Duration(-time.Second)},
+			expectedError: true,
+		},
+		{
+			desc:          "negative bytes per second",
+			config:        dynamic.TCPRateLimit{BytesPerSecond: -1},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {})
+			_, err := New(context.Background(), next, test.config, "foo", &testhelpers.CollectingCounter{})
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRateLimiter_ServeTCP(t *testing.T) {
+	var served []string
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		served = append(served, conn.RemoteAddr().String())
+	})
+
+	rejectedConns := &testhelpers.CollectingCounter{}
+
+	config := dynamic.TCPRateLimit{Average: 1, Period: ptypes.Duration(time.Hour), Burst: 2}
+	middleware, err := New(context.Background(), next, config, "foo", rejectedConns)
+	require.NoError(t, err)
+
+	// The connections within the burst succeed.
+	for i := 0; i < 2; i++ {
+		conn := &fakeConn{addr: "127.0.0.1:9000"}
+		middleware.ServeTCP(conn)
+		assert.False(t, conn.closed)
+	}
+
+	// The next connection from the same remote IP is closed, and counted.
+	conn := &fakeConn{addr: "127.0.0.1:9001"}
+	middleware.ServeTCP(conn)
+	assert.True(t, conn.closed)
+	assert.Equal(t, float64(1), rejectedConns.CounterValue)
+	assert.Equal(t, []string{"middleware", "foo"}, rejectedConns.LastLabelValues)
+
+	// The connection from another remote IP succeeds.
+	conn = &fakeConn{addr: "127.0.0.2:9000"}
+	middleware.ServeTCP(conn)
+	assert.False(t, conn.closed)
+
+	assert.Equal(t, []string{"127.0.0.1:9000", "127.0.0.1:9000", "127.0.0.2:9000"}, served)
+}
+
+func TestRateLimiter_ServeTCP_noAverage(t *testing.T) {
+	var served int
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		served++
+		_, throttled := conn.(*throttledConn)
+		assert.True(t, throttled)
+	})
+
+	config := dynamic.TCPRateLimit{Burst: 1, BytesPerSecond: 1024}
+	middleware, err := New(context.Background(), next, config, "foo", &testhelpers.CollectingCounter{})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		middleware.ServeTCP(&fakeConn{addr: "127.0.0.1:9000"})
+	}
+
+	assert.Equal(t, 10, served)
+}
+
+type fakeConn struct {
+	net.Conn
+
+	addr   string
+	closed bool
+}
+
+func (c *fakeConn) RemoteAddr() net.Addr {
+	return fakeAddr{addr: c.addr}
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) CloseWrite() error {
+	panic("implement me")
+}
+
+type fakeAddr struct {
+	addr string
+}
+
+func (a fakeAddr) Network() string {
+	return "tcp"
+}
+
+func (a fakeAddr) String() string {
+	return a.addr
+}
@@ -0,0 +1,78 @@
+package ratelimiter
+
+import (
+	"context"
+	"net"
+
+	"golang.org/x/time/rate"
+	"traefik/v3/pkg/tcp"
+)
+
+// throttledConn limits the throughput of a connection, in each direction,
+// by waiting for the read and written bytes to be available in token buckets.
+type throttledConn struct {
+	tcp.WriteCloser
+
+	readLimiter  *rate.Limiter
+	writeLimiter *rate.Limiter
+}
+
+func newThrottledConn(conn tcp.WriteCloser, bytesPerSecond int64) *throttledConn {
+	// The buckets hold a second of throughput, which is the largest chunk of bytes read or written at once.
+	burst := int(bytesPerSecond)
+
+	return &throttledConn{
+		WriteCloser:  conn,
+		readLimiter:  rate.NewLimiter(rate.Limit(bytesPerSecond), burst),
+		writeLimiter: rate.NewLimiter(rate.Limit(bytesPerSecond), burst),
+	}
+}
+
+// NetConn returns the underlying connection.
+func (c *throttledConn) NetConn() net.Conn {
+	return c.WriteCloser
+}
+
+// Read reads data from the connection, and then waits for the read bytes to be available in the read bucket,
+// which delays the next reads.
+func (c *throttledConn) Read(p []byte) (int, error) {
+	if len(p) > c.readLimiter.Burst() {
+		p = p[:c.readLimiter.Burst()]
+	}
+
+	n, err := c.WriteCloser.Read(p)
+	if n > 0 {
+		if waitErr := c.readLimiter.WaitN(context.Background(), n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+
+	return n, err
+}
+
+// Write waits for the bytes to be available in the write bucket, and then writes them to the connection,
+// by chunks of at most the bucket size.
+func (c *throttledConn) Write(p []byte) (int, error) {
+	var written int
+
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > c.writeLimiter.Burst() {
+			chunk = chunk[:c.writeLimiter.Burst()]
+		}
+
+		if err := c.writeLimiter.WaitN(context.Background(), len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := c.WriteCloser.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		p = p[n:]
+	}
+
+	return written, nil
+}
@@ -0,0 +1,74 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottledConn_Read(t *testing.T) {
+	t.Parallel()
+
+	data := bytes.Repeat([]byte("a"), 1500)
+	conn := newThrottledConn(&bufferConn{reader: bytes.NewReader(data)}, 1000)
+
+	start := time.Now()
+
+	var reads []int
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			reads = append(reads, n)
+		}
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	// The first second of throughput is available at once, the remaining bytes wait for the bucket to refill.
+	assert.Equal(t, []int{1000, 500}, reads)
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+func TestThrottledConn_Write(t *testing.T) {
+	t.Parallel()
+
+	bc := &bufferConn{}
+	conn := newThrottledConn(bc, 1000)
+
+	start := time.Now()
+
+	n, err := conn.Write(bytes.Repeat([]byte("a"), 1500))
+	require.NoError(t, err)
+	assert.Equal(t, 1500, n)
+
+	assert.Equal(t, []int{1000, 500}, bc.writes)
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+type bufferConn struct {
+	net.Conn
+
+	reader io.Reader
+	writes []int
+}
+
+func (c *bufferConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func (c *bufferConn) Write(p []byte) (int, error) {
+	c.writes = append(c.writes, len(p))
+	return len(p), nil
+}
+
+func (c *bufferConn) CloseWrite() error {
+	return nil
+}
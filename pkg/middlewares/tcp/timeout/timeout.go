@@ -0,0 +1,203 @@
+// Package timeout implements a middleware closing the TCP connections
+// which are idle for too long, or which are opened for too long.
+package timeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+	"traefik/v3/pkg/middlewares/accesslog"
+	"traefik/v3/pkg/tcp"
+)
+
+const typeName = "TimeoutTCP"
+
+type timeout struct {
+	name        string
+	next        tcp.Handler
+	idleTimeout time.Duration
+	maxLifetime time.Duration
+}
+
+// New creates a timeout middleware.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPTimeout, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	idleTimeout := time.Duration(config.IdleTimeout)
+	if idleTimeout < 0 {
+		return nil, fmt.Errorf("negative value not valid for idleTimeout: %v", idleTimeout)
+	}
+
+	maxLifetime := time.Duration(config.MaxLifetime)
+	if maxLifetime < 0 {
+		return nil, fmt.Errorf("negative value not valid for maxLifetime: %v", maxLifetime)
+	}
+
+	if idleTimeout == 0 && maxLifetime == 0 {
+		return nil, errors.New("idleTimeout and maxLifetime are empty, timeout not created")
+	}
+
+	return &timeout{
+		name:        name,
+		next:        next,
+		idleTimeout: idleTimeout,
+		maxLifetime: maxLifetime,
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (t *timeout) ServeTCP(conn tcp.WriteCloser) {
+	logger := middlewares.GetLogger(context.Background(), t.name, typeName)
+
+	tc := newTimeoutConn(conn, t.idleTimeout, t.maxLifetime, func(reason string) {
+		logger.Debug().
+			Str("remoteAddr", conn.RemoteAddr().String()).
+			Str("reason", reason).
+			Msg("Closing TCP connection")
+	})
+	defer tc.stop()
+
+	t.next.ServeTCP(tc)
+}
+
+// timeoutConn ends the connection when no bytes are read from or written to it during the idle timeout,
+// or when its maximum lifetime is reached.
+// The connection is ended by expiring its deadlines, which unblocks the pending reads and writes,
+// and the reads then return io.EOF, so that the connection is terminated as if the peer had closed it.
+type timeoutConn struct {
+	tcp.WriteCloser
+
+	idleTimeout  time.Duration
+	lastActivity atomic.Int64 // unix nano time of the last read or write.
+
+	mu        sync.Mutex
+	idleTimer *time.Timer
+	lifeTimer *time.Timer
+	expired   bool
+	onExpired func(reason string)
+}
+
+func newTimeoutConn(conn tcp.WriteCloser, idleTimeout, maxLifetime time.Duration, onExpired func(reason string)) *timeoutConn {
+	c := &timeoutConn{
+		WriteCloser: conn,
+		idleTimeout: idleTimeout,
+		onExpired:   onExpired,
+	}
+	c.lastActivity.Store(time.Now().UnixNano())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if idleTimeout > 0 {
+		c.idleTimer = time.AfterFunc(idleTimeout, c.checkIdle)
+	}
+
+	if maxLifetime > 0 {
+		c.lifeTimer = time.AfterFunc(maxLifetime, func() {
+			c.expire(fmt.Sprintf("max lifetime of %s reached", maxLifetime))
+		})
+	}
+
+	return c
+}
+
+// Read reads data from the connection.
+// It returns io.EOF instead of the deadline error once the connection has expired.
+func (c *timeoutConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	if n > 0 {
+		c.lastActivity.Store(time.Now().UnixNano())
+	}
+
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) && c.isExpired() {
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+// Write writes data to the connection.
+func (c *timeoutConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	if n > 0 {
+		c.lastActivity.Store(time.Now().UnixNano())
+	}
+
+	return n, err
+}
+
+// NetConn returns the underlying connection.
+func (c *timeoutConn) NetConn() net.Conn {
+	return c.WriteCloser
+}
+
+// checkIdle expires the connection if it has been idle for the idle timeout,
+// or checks it again when the idle timeout from the last activity has elapsed.
+func (c *timeoutConn) checkIdle() {
+	idle := time.Since(time.Unix(0, c.lastActivity.Load()))
+	if idle >= c.idleTimeout {
+		c.expire(fmt.Sprintf("idle for %s", c.idleTimeout))
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.expired && c.idleTimer != nil {
+		c.idleTimer.Reset(c.idleTimeout - idle)
+	}
+}
+
+func (c *timeoutConn) expire(reason string) {
+	c.mu.Lock()
+	if c.expired {
+		c.mu.Unlock()
+		return
+	}
+	c.expired = true
+	c.stopTimers()
+	c.mu.Unlock()
+
+	c.onExpired(reason)
+	accesslog.SetTCPCloseReason(c.WriteCloser, reason)
+
+	_ = c.WriteCloser.SetDeadline(time.Now())
+}
+
+func (c *timeoutConn) isExpired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.expired
+}
+
+// stop stops the timers, once the connection has been handled.
+func (c *timeoutConn) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expired = true
+	c.stopTimers()
+}
+
+func (c *timeoutConn) stopTimers() {
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+		c.idleTimer = nil
+	}
+
+	if c.lifeTimer != nil {
+		c.lifeTimer.Stop()
+		c.lifeTimer = nil
+	}
+}
@@ -0,0 +1,148 @@
+package timeout
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/tcp"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.TCPTimeout
+		expectError bool
+	}{
+		{
+			desc:        "empty",
+			expectError: true,
+		},
+		{
+			desc:        "negative idle timeout",
+			config:      dynamic.TCPTimeout{IdleTimeout: ptypes.Duration(-time.Second)},
+			expectError: true,
+		},
+		{
+			desc:        "negative max lifetime",
+			config:      dynamic.TCPTimeout{MaxLifetime: ptypes.Duration(-time.Second)},
+			expectError: true,
+		},
+		{
+			desc:   "idle timeout",
+			config: dynamic.TCPTimeout{IdleTimeout: ptypes.Duration(time.Second)},
+		},
+		{
+			desc:   "max lifetime",
+			config: dynamic.TCPTimeout{MaxLifetime: ptypes.Duration(time.Second)},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), tcp.HandlerFunc(func(conn tcp.WriteCloser) {}), test.config, "foo")
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTimeout_IdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	config := dynamic.TCPTimeout{IdleTimeout: ptypes.Duration(200 * time.Millisecond)}
+
+	readErr := make(chan error, 1)
+	handler, err := New(context.Background(), tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		_, err := io.Copy(io.Discard, conn)
+		readErr <- err
+	}), config, "foo")
+	require.NoError(t, err)
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	start := time.Now()
+	go handler.ServeTCP(writeCloser{server})
+
+	// The traffic delays the idle timeout.
+	for i := 0; i < 4; i++ {
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = client.Write([]byte("foo"))
+		require.NoError(t, err)
+	}
+
+	select {
+	case err := <-readErr:
+		// The connection is ended as if the client had closed it.
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 600*time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed on idle timeout")
+	}
+}
+
+func TestTimeout_MaxLifetime(t *testing.T) {
+	t.Parallel()
+
+	config := dynamic.TCPTimeout{MaxLifetime: ptypes.Duration(300 * time.Millisecond)}
+
+	readErr := make(chan error, 1)
+	handler, err := New(context.Background(), tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		_, err := io.Copy(io.Discard, conn)
+		readErr <- err
+	}), config, "foo")
+	require.NoError(t, err)
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	start := time.Now()
+	go handler.ServeTCP(writeCloser{server})
+
+	stopWriting := make(chan struct{})
+	defer close(stopWriting)
+
+	// The traffic does not prevent the connection from being closed.
+	go func() {
+		for {
+			select {
+			case <-stopWriting:
+				return
+			case <-time.After(50 * time.Millisecond):
+				if _, err := client.Write([]byte("foo")); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	select {
+	case err := <-readErr:
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not closed on max lifetime")
+	}
+}
+
+type writeCloser struct {
+	net.Conn
+}
+
+func (c writeCloser) CloseWrite() error {
+	return nil
+}
@@ -0,0 +1,119 @@
+package mmdb
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/fsnotify.v1"
+	"traefik/v3/pkg/safe"
+)
+
+var (
+	databasesMu sync.Mutex
+	databases   = make(map[string]*Database)
+)
+
+// Database is a database file in the MaxMind DB format, such as the GeoLite2 and GeoIP2 databases.
+// The file is watched, and the database is reloaded when it changes.
+type Database struct {
+	path   string
+	reader atomic.Pointer[reader]
+}
+
+// Watch returns the database of the given file, watched for changes.
+// A file is watched once for the lifetime of the process,
+// as the middlewares using it are built again on every configuration change.
+func Watch(path string) (*Database, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	databasesMu.Lock()
+	defer databasesMu.Unlock()
+
+	if database, ok := databases[path]; ok {
+		return database, nil
+	}
+
+	database := &Database{path: path}
+	if err := database.load(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+
+	// The directory is watched, to be notified when the file is replaced,
+	// as done by the database update tools and by Kubernetes for the mounted volumes.
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("adding file watcher: %w", err)
+	}
+
+	safe.Go(func() { database.watch(watcher) })
+
+	databases[path] = database
+
+	return database, nil
+}
+
+// Lookup returns the record of the given IP, and whether it was found in the database.
+func (d *Database) Lookup(ip string) (Record, bool, error) {
+	address := net.ParseIP(ip)
+	if address == nil {
+		return Record{}, false, fmt.Errorf("unable to parse address: %s", ip)
+	}
+
+	return d.reader.Load().lookup(address)
+}
+
+func (d *Database) watch(watcher *fsnotify.Watcher) {
+	logger := log.With().Str("file", d.path).Logger()
+
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if err := d.load(); err != nil {
+				logger.Error().Err(err).Msg("Error reloading MaxMind database, keeping the previous one")
+				continue
+			}
+
+			logger.Debug().Msg("MaxMind database reloaded")
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+			logger.Error().Err(err).Msg("MaxMind database file watcher error")
+		}
+	}
+}
+
+func (d *Database) load() error {
+	content, err := os.ReadFile(d.path)
+	if err != nil {
+		return fmt.Errorf("reading MaxMind database: %w", err)
+	}
+
+	r, err := newReader(content)
+	if err != nil {
+		return fmt.Errorf("parsing MaxMind database %s: %w", d.path, err)
+	}
+
+	d.reader.Store(r)
+
+	return nil
+}
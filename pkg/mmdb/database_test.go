@@ -0,0 +1,176 @@
+package mmdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/testhelpers"
+)
+
+var testNetworks = []testhelpers.MaxMindNetwork{
+	{
+		CIDR: "1.2.3.0/24",
+		Data: map[string]interface{}{
+			"country": map[string]interface{}{"iso_code": "FR", "geoname_id": uint32(3017382)},
+		},
+	},
+	{
+		CIDR: "1.2.4.0/24",
+		Data: map[string]interface{}{
+			"registered_country": map[string]interface{}{"iso_code": "US", "geoname_id": uint32(6252001)},
+		},
+	},
+	{
+		CIDR: "5.6.0.0/16",
+		Data: map[string]interface{}{
+			"autonomous_system_number":       uint32(64512),
+			"autonomous_system_organization": "Example Networks",
+		},
+	},
+	{
+		CIDR: "2001:db8::/32",
+		Data: map[string]interface{}{
+			"country":                  map[string]interface{}{"iso_code": "FR"},
+			"autonomous_system_number": uint32(64513),
+		},
+	},
+}
+
+func TestDatabase_Lookup(t *testing.T) {
+	testCases := []struct {
+		ip        string
+		expected  Record
+		found     bool
+		foundIPv4 bool
+	}{
+		{
+			ip:        "1.2.3.4",
+			expected:  Record{Country: "FR"},
+			found:     true,
+			foundIPv4: true,
+		},
+		{
+			ip:        "1.2.4.4",
+			expected:  Record{Country: "US"},
+			found:     true,
+			foundIPv4: true,
+		},
+		{
+			ip:        "5.6.7.8",
+			expected:  Record{ASN: 64512, ASNOrganization: "Example Networks"},
+			found:     true,
+			foundIPv4: true,
+		},
+		{
+			ip: "1.2.5.4",
+		},
+		{
+			ip:       "2001:db8::1",
+			expected: Record{Country: "FR", ASN: 64513},
+			found:    true,
+		},
+		{
+			ip: "2001:db9::1",
+		},
+	}
+
+	for _, ipVersion := range []int{4, 6} {
+		for _, recordSize := range []int{24, 28, 32} {
+			ipVersion, recordSize := ipVersion, recordSize
+			t.Run(fmt.Sprintf("IPv%d with %d bits records", ipVersion, recordSize), func(t *testing.T) {
+				t.Parallel()
+
+				networks := testNetworks
+				if ipVersion == 4 {
+					networks = networks[:3]
+				}
+
+				path := filepath.Join(t.TempDir(), "test.mmdb")
+				err := testhelpers.WriteMaxMindDatabase(path, "Test", ipVersion, recordSize, networks)
+				require.NoError(t, err)
+
+				database, err := Watch(path)
+				require.NoError(t, err)
+
+				for _, test := range testCases {
+					found := test.found
+					if ipVersion == 4 {
+						found = test.foundIPv4
+					}
+
+					record, ok, err := database.Lookup(test.ip)
+					require.NoError(t, err, test.ip)
+					assert.Equal(t, found, ok, test.ip)
+
+					if found {
+						assert.Equal(t, test.expected, record, test.ip)
+					}
+				}
+
+				_, _, err = database.Lookup("foo")
+				assert.Error(t, err)
+			})
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	err := testhelpers.WriteMaxMindDatabase(path, "Test", 6, 24, testNetworks[:1])
+	require.NoError(t, err)
+
+	database, err := Watch(path)
+	require.NoError(t, err)
+
+	same, err := Watch(path)
+	require.NoError(t, err)
+	assert.Same(t, database, same)
+
+	record, found, err := database.Lookup("1.2.3.4")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "FR", record.Country)
+
+	// The database is replaced, as done by the database update tools.
+	tmpPath := path + ".tmp"
+	err = testhelpers.WriteMaxMindDatabase(tmpPath, "Test", 6, 24, testNetworks[1:2])
+	require.NoError(t, err)
+	err = os.Rename(tmpPath, path)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, found, err := database.Lookup("1.2.4.4")
+		return err == nil && found
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, found, err = database.Lookup("1.2.3.4")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// The invalid databases are ignored, the previous one is kept.
+	err = os.WriteFile(path, []byte("foo"), 0o600)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, found, err = database.Lookup("1.2.4.4")
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestWatch_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	err := os.WriteFile(path, []byte("foo"), 0o600)
+	require.NoError(t, err)
+
+	_, err = Watch(path)
+	assert.Error(t, err)
+
+	_, err = Watch(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,531 @@
+package mmdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+)
+
+// metadataStartMarker precedes the metadata section, at the end of the database.
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparatorSize is the size of the zeroed bytes between the search tree and the data section.
+const dataSectionSeparatorSize = 16
+
+// maxDecodingDepth bounds the nesting of the decoded values, to stop on malformed databases.
+const maxDecodingDepth = 32
+
+// Data section types, as defined by the MaxMind DB format specification.
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEnd       = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// Record is the geolocation of an IP address, as found in a MaxMind database.
+type Record struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country.
+	Country string
+	// ASN is the number of the autonomous system.
+	ASN uint
+	// ASNOrganization is the organization of the autonomous system.
+	ASNOrganization string
+}
+
+// reader reads a database in the MaxMind DB format (https://maxmind.github.io/MaxMind-DB/).
+type reader struct {
+	tree       []byte
+	data       decoder
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// ipv4Start is the node of the IPv4 addresses, in an IPv6 search tree.
+	ipv4Start uint
+}
+
+func newReader(buffer []byte) (*reader, error) {
+	metadataStart := bytes.LastIndex(buffer, metadataStartMarker)
+	if metadataStart < 0 {
+		return nil, errors.New("invalid MaxMind database: metadata not found")
+	}
+
+	metadata := decoder{buffer: buffer[metadataStart+len(metadataStartMarker):]}
+	value, _, err := metadata.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("decoding metadata: %w", err)
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid MaxMind database: metadata is not a map")
+	}
+
+	r := &reader{}
+	r.nodeCount, err = uintField(fields, "node_count")
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordSize, err = uintField(fields, "record_size")
+	if err != nil {
+		return nil, err
+	}
+
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("unsupported record size: %d", r.recordSize)
+	}
+
+	r.ipVersion, err = uintField(fields, "ip_version")
+	if err != nil {
+		return nil, err
+	}
+
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported IP version: %d", r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+dataSectionSeparatorSize > uint(metadataStart) {
+		return nil, errors.New("invalid MaxMind database: search tree exceeds the database size")
+	}
+
+	r.tree = buffer[:treeSize]
+	r.data = decoder{buffer: buffer[treeSize+dataSectionSeparatorSize : metadataStart]}
+
+	if r.ipVersion == 6 {
+		// The IPv4 addresses are stored in the ::/96 subnet.
+		for i := 0; i < 96 && r.ipv4Start < r.nodeCount; i++ {
+			r.ipv4Start = r.readNode(r.ipv4Start, 0)
+		}
+	}
+
+	return r, nil
+}
+
+// lookup returns the record of the given IP, and whether it was found in the database.
+func (r *reader) lookup(ip net.IP) (Record, bool, error) {
+	offset, found, err := r.lookupOffset(ip)
+	if err != nil || !found {
+		return Record{}, false, err
+	}
+
+	var record Record
+
+	country, err := r.data.lookupPath(offset, "country", "iso_code")
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	if country == nil {
+		// The anycast and satellite providers only have a registered country.
+		country, err = r.data.lookupPath(offset, "registered_country", "iso_code")
+		if err != nil {
+			return Record{}, false, err
+		}
+	}
+	record.Country, _ = country.(string)
+
+	asn, err := r.data.lookupPath(offset, "autonomous_system_number")
+	if err != nil {
+		return Record{}, false, err
+	}
+	if asn, ok := asn.(uint64); ok {
+		record.ASN = uint(asn)
+	}
+
+	organization, err := r.data.lookupPath(offset, "autonomous_system_organization")
+	if err != nil {
+		return Record{}, false, err
+	}
+	record.ASNOrganization, _ = organization.(string)
+
+	return record, true, nil
+}
+
+// lookupOffset returns the offset in the data section of the record of the given IP.
+func (r *reader) lookupOffset(ip net.IP) (uint, bool, error) {
+	node := uint(0)
+
+	address := ip.To4()
+	if address != nil {
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else {
+		if r.ipVersion == 4 {
+			// An IPv6 address cannot be found in an IPv4 database.
+			return 0, false, nil
+		}
+
+		address = ip.To16()
+		if address == nil {
+			return 0, false, fmt.Errorf("invalid IP: %s", ip)
+		}
+	}
+
+	for i := 0; i < len(address)*8 && node < r.nodeCount; i++ {
+		bit := (address[i>>3] >> (7 - uint(i)&7)) & 1
+		node = r.readNode(node, bit)
+	}
+
+	switch {
+	case node == r.nodeCount:
+		return 0, false, nil
+	case node > r.nodeCount:
+		offset := node - r.nodeCount - dataSectionSeparatorSize
+		if offset >= uint(len(r.data.buffer)) {
+			return 0, false, errors.New("invalid MaxMind database: record pointer exceeds the data section")
+		}
+		return offset, true, nil
+	default:
+		return 0, false, errors.New("invalid MaxMind database: search tree is deeper than the IP")
+	}
+}
+
+// readNode returns the record of the given node, for the given bit of the IP.
+func (r *reader) readNode(node uint, bit byte) uint {
+	nodeSize := r.recordSize / 4
+	b := r.tree[node*nodeSize : (node+1)*nodeSize]
+
+	switch r.recordSize {
+	case 24:
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		if bit == 0 {
+			return uint(binary.BigEndian.Uint32(b[:4]))
+		}
+		return uint(binary.BigEndian.Uint32(b[4:]))
+	}
+}
+
+func uintField(fields map[string]interface{}, name string) (uint, error) {
+	value, ok := fields[name].(uint64)
+	if !ok {
+		return 0, fmt.Errorf("invalid MaxMind database: missing %s in metadata", name)
+	}
+
+	return uint(value), nil
+}
+
+// decoder decodes the values of a data section.
+type decoder struct {
+	buffer []byte
+}
+
+// lookupPath returns the value at the given path of keys, in the map at the given offset.
+// It returns nil if the path does not exist.
+func (d *decoder) lookupPath(offset uint, path ...string) (interface{}, error) {
+	for depth, key := range path {
+		typeNum, size, next, err := d.decodeControl(offset)
+		if err != nil {
+			return nil, err
+		}
+
+		if typeNum == typePointer {
+			offset, _, err = d.decodePointer(size, next)
+			if err != nil {
+				return nil, err
+			}
+
+			typeNum, size, next, err = d.decodeControl(offset)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if typeNum != typeMap {
+			return nil, nil
+		}
+
+		found := false
+		offset = next
+		for i := uint(0); i < size; i++ {
+			var name interface{}
+			name, offset, err = d.decode(offset, depth)
+			if err != nil {
+				return nil, err
+			}
+
+			if name == key {
+				found = true
+				break
+			}
+
+			offset, err = d.skip(offset, depth)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !found {
+			return nil, nil
+		}
+	}
+
+	value, _, err := d.decode(offset, len(path))
+	return value, err
+}
+
+// decode returns the value at the given offset, and the offset following it.
+func (d *decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDecodingDepth {
+		return nil, 0, errors.New("invalid MaxMind database: maximum data structure depth exceeded")
+	}
+
+	typeNum, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typeNum == typePointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+
+	switch typeNum {
+	case typeMap:
+		value := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			var key, item interface{}
+			key, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("invalid MaxMind database: map key is not a string")
+			}
+
+			item, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			value[name] = item
+		}
+		return value, offset, nil
+
+	case typeArray:
+		value := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			var item interface{}
+			item, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			value = append(value, item)
+		}
+		return value, offset, nil
+
+	case typeBool:
+		if size > 1 {
+			return nil, 0, fmt.Errorf("invalid MaxMind database: invalid boolean size %d", size)
+		}
+		return size == 1, offset, nil
+	}
+
+	if offset+size > uint(len(d.buffer)) {
+		return nil, 0, errors.New("invalid MaxMind database: unexpected end of data")
+	}
+	payload := d.buffer[offset : offset+size]
+	next := offset + size
+
+	switch typeNum {
+	case typeString:
+		return string(payload), next, nil
+
+	case typeBytes:
+		return append([]byte(nil), payload...), next, nil
+
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid MaxMind database: invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), next, nil
+
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid MaxMind database: invalid float size %d", size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(payload)), next, nil
+
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid MaxMind database: invalid unsigned integer size %d", size)
+		}
+
+		var value uint64
+		for _, b := range payload {
+			value = value<<8 | uint64(b)
+		}
+		return value, next, nil
+
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid MaxMind database: invalid integer size %d", size)
+		}
+
+		var value uint32
+		for _, b := range payload {
+			value = value<<8 | uint32(b)
+		}
+		return int32(value), next, nil
+
+	case typeUint128:
+		if size > 16 {
+			return nil, 0, fmt.Errorf("invalid MaxMind database: invalid unsigned integer size %d", size)
+		}
+		return new(big.Int).SetBytes(payload), next, nil
+
+	default:
+		return nil, 0, fmt.Errorf("invalid MaxMind database: unexpected data type %d", typeNum)
+	}
+}
+
+// skip returns the offset following the value at the given offset, without decoding it.
+func (d *decoder) skip(offset uint, depth int) (uint, error) {
+	if depth > maxDecodingDepth {
+		return 0, errors.New("invalid MaxMind database: maximum data structure depth exceeded")
+	}
+
+	typeNum, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return 0, err
+	}
+
+	switch typeNum {
+	case typePointer:
+		_, next, err := d.decodePointer(size, offset)
+		return next, err
+
+	case typeMap, typeArray:
+		count := size
+		if typeNum == typeMap {
+			count *= 2
+		}
+
+		for i := uint(0); i < count; i++ {
+			offset, err = d.skip(offset, depth+1)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return offset, nil
+
+	case typeBool:
+		return offset, nil
+
+	default:
+		if offset+size > uint(len(d.buffer)) {
+			return 0, errors.New("invalid MaxMind database: unexpected end of data")
+		}
+		return offset + size, nil
+	}
+}
+
+// decodeControl decodes the control byte at the given offset,
+// and returns the type and the size of the value, and the offset of its payload.
+// The size of a pointer is returned as is, as it holds the pointer size and the first bits of its value.
+func (d *decoder) decodeControl(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buffer)) {
+		return 0, 0, 0, errors.New("invalid MaxMind database: unexpected end of data")
+	}
+
+	control := d.buffer[offset]
+	offset++
+
+	typeNum := int(control >> 5)
+	if typeNum == typeExtended {
+		if offset >= uint(len(d.buffer)) {
+			return 0, 0, 0, errors.New("invalid MaxMind database: unexpected end of data")
+		}
+
+		typeNum = 7 + int(d.buffer[offset])
+		offset++
+	}
+
+	size := uint(control & 0x1F)
+	if typeNum == typePointer || size < 29 {
+		return typeNum, size, offset, nil
+	}
+
+	bytesToRead := size - 28
+	if offset+bytesToRead > uint(len(d.buffer)) {
+		return 0, 0, 0, errors.New("invalid MaxMind database: unexpected end of data")
+	}
+
+	var extra uint
+	for _, b := range d.buffer[offset : offset+bytesToRead] {
+		extra = extra<<8 | uint(b)
+	}
+	offset += bytesToRead
+
+	switch size {
+	case 29:
+		size = 29 + extra
+	case 30:
+		size = 285 + extra
+	default:
+		size = 65821 + extra
+	}
+
+	return typeNum, size, offset, nil
+}
+
+// decodePointer returns the offset pointed by the pointer with the given size bits, and the offset following it.
+func (d *decoder) decodePointer(size, offset uint) (uint, uint, error) {
+	pointerSize := ((size >> 3) & 0x3) + 1
+	if offset+pointerSize > uint(len(d.buffer)) {
+		return 0, 0, errors.New("invalid MaxMind database: unexpected end of data")
+	}
+
+	var pointer uint
+	if pointerSize != 4 {
+		pointer = size & 0x7
+	}
+
+	for _, b := range d.buffer[offset : offset+pointerSize] {
+		pointer = pointer<<8 | uint(b)
+	}
+
+	switch pointerSize {
+	case 2:
+		pointer += 2048
+	case 3:
+		pointer += 526336
+	}
+
+	return pointer, offset + pointerSize, nil
+}
@@ -0,0 +1,164 @@
+package mmdb
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test vectors are the ones of the decoder tests of the MaxMind reference readers.
+
+func TestDecoder_decode(t *testing.T) {
+	testCases := map[string]interface{}{
+		// Booleans.
+		"0007": false,
+		"0107": true,
+		// Doubles.
+		"680000000000000000": 0.0,
+		"683FE0000000000000": 0.5,
+		"68400921FB54442EEA": 3.14159265359,
+		"68405EC00000000000": 123.0,
+		"6841D000000007F8F4": 1073741824.12457,
+		"68BFE0000000000000": -0.5,
+		"68C00921FB54442EEA": -3.14159265359,
+		"68C1D000000007F8F4": -1073741824.12457,
+		// Floats.
+		"040800000000": float32(0.0),
+		"04083F800000": float32(1.0),
+		"04083F8CCCCD": float32(1.1),
+		"04084048F5C3": float32(3.14),
+		"0408461C3FF6": float32(9999.99),
+		"0408BF800000": float32(-1.0),
+		"0408BF8CCCCD": float32(-1.1),
+		"0408C048F5C3": float32(-3.14),
+		"0408C61C3FF6": float32(-9999.99),
+		// Signed integers.
+		"0001":         int32(0),
+		"0401ffffffff": int32(-1),
+		"0101ff":       int32(255),
+		"0401ffffff01": int32(-255),
+		"020101f4":     int32(500),
+		"0401fffffe0c": int32(-500),
+		"0201ffff":     int32(65535),
+		"0401ffff0001": int32(-65535),
+		"0301ffffff":   int32(16777215),
+		"0401ff000001": int32(-16777215),
+		"04017fffffff": int32(2147483647),
+		"040180000001": int32(-2147483647),
+		// Unsigned integers.
+		"a0":                   uint64(0),
+		"a1ff":                 uint64(255),
+		"a201f4":               uint64(500),
+		"a22a78":               uint64(10872),
+		"a2ffff":               uint64(65535),
+		"c0":                   uint64(0),
+		"c3ffffff":             uint64(16777215),
+		"c4ffffffff":           uint64(4294967295),
+		"0002":                 uint64(0),
+		"080200000000ffffffff": uint64(4294967295),
+		"0003":                 new(big.Int),
+		"1003ffffffffffffffffffffffffffffffff": new(big.Int).SetBytes([]byte{
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		}),
+		// Strings.
+		"40":       "",
+		"4131":     "1",
+		"43E4BABA": "人",
+		"5b313233343536373839303132333435363738393031323334353637":       "123456789012345678901234567",
+		"5c31323334353637383930313233343536373839303132333435363738":     "1234567890123456789012345678",
+		"5d003132333435363738393031323334353637383930313233343536373839": "12345678901234567890123456789",
+		"5e00d7" + strings.Repeat("78", 500):                             strings.Repeat("x", 500),
+		"5e06b3" + strings.Repeat("78", 2000):                            strings.Repeat("x", 2000),
+		"5f001053" + strings.Repeat("78", 70000):                         strings.Repeat("x", 70000),
+		// Bytes.
+		"83E4BABA": []byte("人"),
+		// Maps.
+		"e0":                             map[string]interface{}{},
+		"e142656e43466f6f":               map[string]interface{}{"en": "Foo"},
+		"e242656e43466f6f427a6843e4baba": map[string]interface{}{"en": "Foo", "zh": "人"},
+		"e1446e616d65e242656e43466f6f427a6843e4baba": map[string]interface{}{
+			"name": map[string]interface{}{"en": "Foo", "zh": "人"},
+		},
+		"e1496c616e677561676573020442656e427a68": map[string]interface{}{
+			"languages": []interface{}{"en", "zh"},
+		},
+		// Arrays.
+		"0004":                 []interface{}{},
+		"010443466f6f":         []interface{}{"Foo"},
+		"020443466f6f43e4baba": []interface{}{"Foo", "人"},
+	}
+
+	for input, expected := range testCases {
+		buffer, err := hex.DecodeString(input)
+		require.NoError(t, err, input)
+
+		d := decoder{buffer: buffer}
+
+		value, next, err := d.decode(0, 0)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, value, input)
+		assert.Equal(t, uint(len(buffer)), next, input)
+
+		next, err = d.skip(0, 0)
+		require.NoError(t, err, input)
+		assert.Equal(t, uint(len(buffer)), next, input)
+	}
+}
+
+func TestDecoder_decodePointer(t *testing.T) {
+	testCases := map[string]uint{
+		"2000":       0,
+		"2005":       5,
+		"200a":       10,
+		"23ff":       1023,
+		"283456":     15446,
+		"2fffff":     526335,
+		"37ffffff":   134744063,
+		"38ffffffff": 4294967295,
+	}
+
+	for input, expected := range testCases {
+		buffer, err := hex.DecodeString(input)
+		require.NoError(t, err, input)
+
+		d := decoder{buffer: buffer}
+
+		typeNum, size, offset, err := d.decodeControl(0)
+		require.NoError(t, err, input)
+		require.Equal(t, typePointer, typeNum, input)
+
+		pointer, next, err := d.decodePointer(size, offset)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, pointer, input)
+		assert.Equal(t, uint(len(buffer)), next, input)
+	}
+}
+
+func TestDecoder_decode_invalid(t *testing.T) {
+	testCases := []string{
+		// Truncated string.
+		"43E4BA",
+		// Truncated extended type.
+		"00",
+		// Double of invalid size.
+		"67000000000000",
+		// Map with a non string key.
+		"e1a0a0",
+		// Pointer to itself.
+		"2000",
+	}
+
+	for _, input := range testCases {
+		buffer, err := hex.DecodeString(input)
+		require.NoError(t, err, input)
+
+		d := decoder{buffer: buffer}
+
+		_, _, err = d.decode(0, 0)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,99 @@
+package udp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/udp"
+)
+
+func Test_ClientIP(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rule     string
+		expected map[string]bool
+		buildErr bool
+	}{
+		{
+			desc:     "Invalid ClientIP matcher (empty host)",
+			rule:     "ClientIP(``)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid ClientIP matcher (non ASCII host)",
+			rule:     "ClientIP(`🦭/32`)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid ClientIP matcher (too many parameters)",
+			rule:     "ClientIP(`127.0.0.1`, `127.0.0.2`)",
+			buildErr: true,
+		},
+		{
+			desc:     "Unknown matcher",
+			rule:     "HostSNI(`example.com`)",
+			buildErr: true,
+		},
+		{
+			desc: "valid ClientIP matcher",
+			rule: "ClientIP(`20.20.20.20`)",
+			expected: map[string]bool{
+				"20.20.20.20": true,
+				"10.10.10.10": false,
+			},
+		},
+		{
+			desc: "valid ClientIP matcher with CIDR",
+			rule: "ClientIP(`20.20.20.20/24`)",
+			expected: map[string]bool{
+				"20.20.20.20": true,
+				"20.20.20.40": true,
+				"10.10.10.10": false,
+			},
+		},
+		{
+			desc: "valid negated ClientIP matcher",
+			rule: "!ClientIP(`20.20.20.20/24`)",
+			expected: map[string]bool{
+				"20.20.20.20": false,
+				"10.10.10.10": true,
+			},
+		},
+		{
+			desc: "valid ClientIP matchers combined",
+			rule: "ClientIP(`20.20.20.20`) || ClientIP(`10.10.10.10`)",
+			expected: map[string]bool{
+				"20.20.20.20": true,
+				"10.10.10.10": true,
+				"30.30.30.30": false,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer()
+			require.NoError(t, err)
+
+			err = muxer.AddRoute(test.rule, 0, udp.HandlerFunc(func(conn *udp.Conn) {}))
+			if test.buildErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for remoteIP, match := range test.expected {
+				meta := ConnData{
+					remoteIP: remoteIP,
+				}
+
+				handler := muxer.Match(meta)
+				assert.Equal(t, match, handler != nil, remoteIP)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package udp
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vulcand/predicate"
+	"traefik/v3/pkg/rules"
+	"traefik/v3/pkg/udp"
+)
+
+// ConnData contains UDP session metadata.
+type ConnData struct {
+	remoteIP string
+}
+
+// NewConnData builds a connData struct from the given parameters.
+func NewConnData(conn *udp.Conn) (ConnData, error) {
+	remoteIP, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return ConnData{}, fmt.Errorf("error while parsing remote address %q: %w", conn.RemoteAddr().String(), err)
+	}
+
+	return ConnData{
+		remoteIP: remoteIP,
+	}, nil
+}
+
+// Muxer defines a muxer that handles UDP routing with rules.
+type Muxer struct {
+	routes routes
+	parser predicate.Parser
+}
+
+// NewMuxer returns a UDP muxer.
+func NewMuxer() (*Muxer, error) {
+	var matcherNames []string
+	for matcherName := range udpFuncs {
+		matcherNames = append(matcherNames, matcherName)
+	}
+
+	parser, err := rules.NewParser(matcherNames)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating rules parser: %w", err)
+	}
+
+	return &Muxer{parser: parser}, nil
+}
+
+// ServeUDP forwards the session to the handler of the first route matching its metadata.
+func (m *Muxer) ServeUDP(conn *udp.Conn) {
+	meta, err := NewConnData(conn)
+	if err != nil {
+		log.Error().Err(err).Msg("Error while reading UDP session metadata")
+		conn.Close()
+		return
+	}
+
+	handler := m.Match(meta)
+	if handler == nil {
+		log.Debug().Str("remoteIP", meta.remoteIP).Msg("No UDP route matching the session")
+		conn.Close()
+		return
+	}
+
+	handler.ServeUDP(conn)
+}
+
+// Match returns the handler of the first route matching the session metadata.
+func (m *Muxer) Match(meta ConnData) udp.Handler {
+	for _, route := range m.routes {
+		if route.matchers == nil || route.matchers.match(meta) {
+			return route.handler
+		}
+	}
+
+	return nil
+}
+
+// AddRoute adds a new route, associated to the given handler, at the given
+// priority, to the muxer.
+// An empty rule matches all the sessions.
+// Among routes of equal priority, the first added one takes precedence.
+func (m *Muxer) AddRoute(rule string, priority int, handler udp.Handler) error {
+	newRoute := &route{
+		handler:  handler,
+		priority: priority,
+	}
+
+	if rule != "" {
+		parse, err := m.parser.Parse(rule)
+		if err != nil {
+			return fmt.Errorf("error while parsing rule %s: %w", rule, err)
+		}
+
+		buildTree, ok := parse.(rules.TreeBuilder)
+		if !ok {
+			return fmt.Errorf("error while parsing rule %s", rule)
+		}
+
+		var matchers matchersTree
+		err = matchers.addRule(buildTree())
+		if err != nil {
+			return fmt.Errorf("error while adding rule %s: %w", rule, err)
+		}
+
+		newRoute.matchers = &matchers
+	}
+
+	m.routes = append(m.routes, newRoute)
+
+	sort.Stable(m.routes)
+
+	return nil
+}
+
+// HasRoutes returns whether the muxer has routes.
+func (m *Muxer) HasRoutes() bool {
+	return len(m.routes) > 0
+}
+
+// GetRulePriority computes the priority for a given rule.
+// The priority is calculated using the length of rule.
+func GetRulePriority(rule string) int {
+	return len(rule)
+}
+
+// routes implements sort.Interface.
+type routes []*route
+
+// Len implements sort.Interface.
+func (r routes) Len() int { return len(r) }
+
+// Swap implements sort.Interface.
+func (r routes) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+
+// Less implements sort.Interface.
+func (r routes) Less(i, j int) bool { return r[i].priority > r[j].priority }
+
+// route holds the matchers to match UDP route,
+// and the handler that will serve the session.
+type route struct {
+	// matchers tree structure reflecting the rule.
+	// A nil matchers tree matches all the sessions.
+	matchers *matchersTree
+	// handler responsible for handling the route.
+	handler udp.Handler
+	// priority is used to disambiguate between two (or more) rules that would
+	// all match for a given session.
+	// Computed from the matching rule length, if not user-set.
+	priority int
+}
+
+// matchersTree represents the matchers tree structure.
+type matchersTree struct {
+	// matcher is a matcher func used to match session properties.
+	// If matcher is not nil, it means that this matcherTree is a leaf of the tree.
+	// It is therefore mutually exclusive with left and right.
+	matcher func(ConnData) bool
+	// operator to combine the evaluation of left and right leaves.
+	operator string
+	// Mutually exclusive with matcher.
+	left  *matchersTree
+	right *matchersTree
+}
+
+func (m *matchersTree) match(meta ConnData) bool {
+	if m == nil {
+		// This should never happen as it should have been detected during parsing.
+		log.Warn().Msg("Rule matcher is nil")
+		return false
+	}
+
+	if m.matcher != nil {
+		return m.matcher(meta)
+	}
+
+	switch m.operator {
+	case "or":
+		return m.left.match(meta) || m.right.match(meta)
+	case "and":
+		return m.left.match(meta) && m.right.match(meta)
+	default:
+		// This should never happen as it should have been detected during parsing.
+		log.Warn().Str("operator", m.operator).Msg("Invalid rule operator")
+		return false
+	}
+}
+
+func (m *matchersTree) addRule(rule *rules.Tree) error {
+	switch rule.Matcher {
+	case "and", "or":
+		m.operator = rule.Matcher
+		m.left = &matchersTree{}
+		err := m.left.addRule(rule.RuleLeft)
+		if err != nil {
+			return err
+		}
+
+		m.right = &matchersTree{}
+		return m.right.addRule(rule.RuleRight)
+	default:
+		err := rules.CheckRule(rule)
+		if err != nil {
+			return err
+		}
+
+		err = udpFuncs[rule.Matcher](m, rule.Value...)
+		if err != nil {
+			return err
+		}
+
+		if rule.Not {
+			matcherFunc := m.matcher
+			m.matcher = func(meta ConnData) bool {
+				return !matcherFunc(meta)
+			}
+		}
+	}
+
+	return nil
+}
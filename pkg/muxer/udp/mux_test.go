@@ -0,0 +1,122 @@
+package udp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/udp"
+)
+
+func Test_Priority(t *testing.T) {
+	type rule struct {
+		rule     string
+		priority int
+	}
+
+	testCases := []struct {
+		desc         string
+		rules        []rule
+		remoteIP     string
+		expectedRule string
+	}{
+		{
+			desc: "One matching rule, calculated priority",
+			rules: []rule{
+				{rule: "ClientIP(`10.0.0.1`)"},
+				{rule: "ClientIP(`10.0.0.2`)"},
+			},
+			remoteIP:     "10.0.0.2",
+			expectedRule: "ClientIP(`10.0.0.2`)",
+		},
+		{
+			desc: "Two matching rules, calculated priority",
+			rules: []rule{
+				{rule: "ClientIP(`10.0.0.0/8`)"},
+				{rule: "ClientIP(`10.0.0.0/16`)"},
+			},
+			remoteIP:     "10.0.0.1",
+			expectedRule: "ClientIP(`10.0.0.0/16`)",
+		},
+		{
+			desc: "Two matching rules, custom priority",
+			rules: []rule{
+				{rule: "ClientIP(`10.0.0.0/8`)", priority: 100},
+				{rule: "ClientIP(`10.0.0.0/16`)"},
+			},
+			remoteIP:     "10.0.0.1",
+			expectedRule: "ClientIP(`10.0.0.0/8`)",
+		},
+		{
+			desc: "Rule matching before the catch-all route",
+			rules: []rule{
+				{rule: ""},
+				{rule: "ClientIP(`10.0.0.0/8`)"},
+			},
+			remoteIP:     "10.0.0.1",
+			expectedRule: "ClientIP(`10.0.0.0/8`)",
+		},
+		{
+			desc: "Catch-all route",
+			rules: []rule{
+				{rule: ""},
+				{rule: "ClientIP(`10.0.0.0/8`)"},
+			},
+			remoteIP:     "192.168.0.1",
+			expectedRule: "",
+		},
+		{
+			desc: "Two catch-all routes, the first added one takes precedence",
+			rules: []rule{
+				{rule: "", priority: 1},
+				{rule: "ClientIP(`0.0.0.0/0`)", priority: 1},
+			},
+			remoteIP:     "192.168.0.1",
+			expectedRule: "",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer()
+			require.NoError(t, err)
+
+			matchedRule := "none"
+			for _, rule := range test.rules {
+				rule := rule
+
+				priority := rule.priority
+				if priority == 0 {
+					priority = GetRulePriority(rule.rule)
+				}
+
+				err := muxer.AddRoute(rule.rule, priority, udp.HandlerFunc(func(conn *udp.Conn) {
+					matchedRule = rule.rule
+				}))
+				require.NoError(t, err)
+			}
+
+			handler := muxer.Match(ConnData{
+				remoteIP: test.remoteIP,
+			})
+			require.NotNil(t, handler)
+
+			handler.ServeUDP(nil)
+			assert.Equal(t, test.expectedRule, matchedRule)
+		})
+	}
+}
+
+func Test_NoMatch(t *testing.T) {
+	muxer, err := NewMuxer()
+	require.NoError(t, err)
+
+	err = muxer.AddRoute("ClientIP(`10.0.0.0/8`)", 0, udp.HandlerFunc(func(conn *udp.Conn) {}))
+	require.NoError(t, err)
+
+	assert.Nil(t, muxer.Match(ConnData{remoteIP: "192.168.0.1"}))
+}
@@ -245,6 +245,12 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			continue
 		}
 
+		apiKey, err := createAPIKeyMiddleware(client, middleware.Namespace, middleware.Spec.APIKey)
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while reading API key middleware")
+			continue
+		}
+
 		errorPage, errorPageService, err := p.createErrorPageMiddleware(client, middleware.Namespace, middleware.Spec.Errors)
 		if err != nil {
 			logger.Error().Err(err).Msg("Error while reading error page middleware")
@@ -281,6 +287,12 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			continue
 		}
 
+		cache, err := createCacheMiddleware(middleware.Spec.Cache)
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while reading cache middleware")
+			continue
+		}
+
 		conf.HTTP.Middlewares[id] = &dynamic.Middleware{
 			AddPrefix:         middleware.Spec.AddPrefix,
 			StripPrefix:       middleware.Spec.StripPrefix,
@@ -289,6 +301,8 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			ReplacePathRegex:  middleware.Spec.ReplacePathRegex,
 			Chain:             createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
 			IPAllowList:       middleware.Spec.IPAllowList,
+			IPDenyList:        middleware.Spec.IPDenyList,
+			GeoIP:             middleware.Spec.GeoIP,
 			Headers:           middleware.Spec.Headers,
 			Errors:            errorPage,
 			RateLimit:         rateLimit,
@@ -297,10 +311,14 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			BasicAuth:         basicAuth,
 			DigestAuth:        digestAuth,
 			ForwardAuth:       forwardAuth,
+			APIKey:            apiKey,
 			InFlightReq:       middleware.Spec.InFlightReq,
 			Buffering:         middleware.Spec.Buffering,
+			BodyRewrite:       middleware.Spec.BodyRewrite,
+			Cache:             cache,
 			CircuitBreaker:    circuitBreaker,
 			Compress:          middleware.Spec.Compress,
+			Decompress:        middleware.Spec.Decompress,
 			PassTLSClientCert: middleware.Spec.PassTLSClientCert,
 			Retry:             retry,
 			ContentType:       middleware.Spec.ContentType,
@@ -312,9 +330,26 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 	for _, middlewareTCP := range client.GetMiddlewareTCPs() {
 		id := provider.Normalize(makeID(middlewareTCP.Namespace, middlewareTCP.Name))
 
+		logger := log.Ctx(ctx).With().Str(logs.MiddlewareName, id).Logger()
+
+		rateLimit, err := createTCPRateLimitMiddleware(middlewareTCP.Spec.RateLimit)
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while reading rateLimit middleware")
+			continue
+		}
+
+		timeout, err := createTCPTimeoutMiddleware(middlewareTCP.Spec.Timeout)
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while reading timeout middleware")
+			continue
+		}
+
 		conf.TCP.Middlewares[id] = &dynamic.TCPMiddleware{
 			InFlightConn: middlewareTCP.Spec.InFlightConn,
 			IPAllowList:  middlewareTCP.Spec.IPAllowList,
+			IPDenyList:   middlewareTCP.Spec.IPDenyList,
+			RateLimit:    rateLimit,
+			Timeout:      timeout,
 		}
 	}
 
@@ -646,6 +681,85 @@ func createCircuitBreakerMiddleware(circuitBreaker *traefikv1alpha1.CircuitBreak
 	return cb, nil
 }
 
+func createCacheMiddleware(cache *traefikv1alpha1.Cache) (*dynamic.Cache, error) {
+	if cache == nil {
+		return nil, nil
+	}
+
+	c := &dynamic.Cache{Disk: cache.Disk}
+	c.SetDefaults()
+
+	if cache.MaxEntries != nil {
+		c.MaxEntries = *cache.MaxEntries
+	}
+
+	if cache.MaxBodyBytes != nil {
+		c.MaxBodyBytes = *cache.MaxBodyBytes
+	}
+
+	if cache.MaxMemory != nil {
+		c.MaxMemory = *cache.MaxMemory
+	}
+
+	if cache.DefaultTTL != nil {
+		if err := c.DefaultTTL.Set(cache.DefaultTTL.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if cache.StatusHeader != "" {
+		c.StatusHeader = cache.StatusHeader
+	}
+
+	return c, nil
+}
+
+func createTCPRateLimitMiddleware(rateLimit *traefikv1alpha1.TCPRateLimit) (*dynamic.TCPRateLimit, error) {
+	if rateLimit == nil {
+		return nil, nil
+	}
+
+	rl := &dynamic.TCPRateLimit{Average: rateLimit.Average, BytesPerSecond: rateLimit.BytesPerSecond}
+	rl.SetDefaults()
+
+	if rateLimit.Burst != nil {
+		rl.Burst = *rateLimit.Burst
+	}
+
+	if rateLimit.Period != nil {
+		err := rl.Period.Set(rateLimit.Period.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rl, nil
+}
+
+func createTCPTimeoutMiddleware(timeout *traefikv1alpha1.TCPTimeout) (*dynamic.TCPTimeout, error) {
+	if timeout == nil {
+		return nil, nil
+	}
+
+	t := &dynamic.TCPTimeout{}
+
+	if timeout.IdleTimeout != nil {
+		err := t.IdleTimeout.Set(timeout.IdleTimeout.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if timeout.MaxLifetime != nil {
+		err := t.MaxLifetime.Set(timeout.MaxLifetime.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
 func createRateLimitMiddleware(rateLimit *traefikv1alpha1.RateLimit) (*dynamic.RateLimit, error) {
 	if rateLimit == nil {
 		return nil, nil
@@ -726,6 +840,15 @@ func createForwardAuthMiddleware(k8sClient Client, namespace string, auth *traef
 		AuthResponseHeaders:      auth.AuthResponseHeaders,
 		AuthResponseHeadersRegex: auth.AuthResponseHeadersRegex,
 		AuthRequestHeaders:       auth.AuthRequestHeaders,
+		ExtAuthz:                 auth.ExtAuthz,
+	}
+
+	if auth.Cache != nil {
+		cache, err := createForwardAuthCache(auth.Cache)
+		if err != nil {
+			return nil, err
+		}
+		forwardAuth.Cache = cache
 	}
 
 	if auth.TLS == nil {
@@ -756,6 +879,27 @@ func createForwardAuthMiddleware(k8sClient Client, namespace string, auth *traef
 	return forwardAuth, nil
 }
 
+func createForwardAuthCache(cache *traefikv1alpha1.ForwardAuthCache) (*dynamic.ForwardAuthCache, error) {
+	forwardAuthCache := &dynamic.ForwardAuthCache{
+		KeyHeaders: cache.KeyHeaders,
+		KeyCookies: cache.KeyCookies,
+		IgnoreURI:  cache.IgnoreURI,
+	}
+	forwardAuthCache.SetDefaults()
+
+	if cache.TTL != nil {
+		if err := forwardAuthCache.TTL.Set(cache.TTL.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if cache.MaxEntries != nil {
+		forwardAuthCache.MaxEntries = *cache.MaxEntries
+	}
+
+	return forwardAuthCache, nil
+}
+
 func loadCASecret(namespace, secretName string, k8sClient Client) (string, error) {
 	secret, ok, err := k8sClient.GetSecret(namespace, secretName)
 	if err != nil {
@@ -883,6 +1027,41 @@ func createDigestAuthMiddleware(client Client, namespace string, digestAuth *tra
 	}, nil
 }
 
+func createAPIKeyMiddleware(client Client, namespace string, apiKey *traefikv1alpha1.APIKey) (*dynamic.APIKey, error) {
+	if apiKey == nil {
+		return nil, nil
+	}
+
+	if apiKey.Secret == "" {
+		return nil, fmt.Errorf("auth secret must be set")
+	}
+
+	secret, ok, err := client.GetSecret(namespace, apiKey.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret '%s/%s': %w", namespace, apiKey.Secret, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("secret '%s/%s' not found", namespace, apiKey.Secret)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("data for secret '%s/%s' must not be nil", namespace, apiKey.Secret)
+	}
+
+	keys, err := loadAuthCredentials(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	return &dynamic.APIKey{
+		Keys:        keys,
+		HeaderName:  apiKey.HeaderName,
+		QueryParam:  apiKey.QueryParam,
+		CookieName:  apiKey.CookieName,
+		HeaderField: apiKey.HeaderField,
+		RemoveKey:   apiKey.RemoveKey,
+	}, nil
+}
+
 func loadBasicAuthCredentials(secret *corev1.Secret) ([]string, error) {
 	username, usernameExists := secret.Data["username"]
 	password, passwordExists := secret.Data["password"]
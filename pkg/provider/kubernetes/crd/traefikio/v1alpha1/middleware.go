@@ -33,6 +33,8 @@ type MiddlewareSpec struct {
 	ReplacePathRegex  *dynamic.ReplacePathRegex  `json:"replacePathRegex,omitempty"`
 	Chain             *Chain                     `json:"chain,omitempty"`
 	IPAllowList       *dynamic.IPAllowList       `json:"ipAllowList,omitempty"`
+	IPDenyList        *dynamic.IPDenyList        `json:"ipDenyList,omitempty"`
+	GeoIP             *dynamic.GeoIP             `json:"geoIP,omitempty"`
 	Headers           *dynamic.Headers           `json:"headers,omitempty"`
 	Errors            *ErrorPage                 `json:"errors,omitempty"`
 	RateLimit         *RateLimit                 `json:"rateLimit,omitempty"`
@@ -41,10 +43,14 @@ type MiddlewareSpec struct {
 	BasicAuth         *BasicAuth                 `json:"basicAuth,omitempty"`
 	DigestAuth        *DigestAuth                `json:"digestAuth,omitempty"`
 	ForwardAuth       *ForwardAuth               `json:"forwardAuth,omitempty"`
+	APIKey            *APIKey                    `json:"apiKey,omitempty"`
 	InFlightReq       *dynamic.InFlightReq       `json:"inFlightReq,omitempty"`
 	Buffering         *dynamic.Buffering         `json:"buffering,omitempty"`
+	BodyRewrite       *dynamic.BodyRewrite       `json:"bodyRewrite,omitempty"`
+	Cache             *Cache                     `json:"cache,omitempty"`
 	CircuitBreaker    *CircuitBreaker            `json:"circuitBreaker,omitempty"`
 	Compress          *dynamic.Compress          `json:"compress,omitempty"`
+	Decompress        *dynamic.Decompress        `json:"decompress,omitempty"`
 	PassTLSClientCert *dynamic.PassTLSClientCert `json:"passTLSClientCert,omitempty"`
 	Retry             *Retry                     `json:"retry,omitempty"`
 	ContentType       *dynamic.ContentType       `json:"contentType,omitempty"`
@@ -76,6 +82,32 @@ type ErrorPage struct {
 
 // +k8s:deepcopy-gen=true
 
+// Cache holds the cache middleware configuration.
+// This middleware stores the responses of the backends, and serves them to the subsequent requests
+// while they are fresh, following the HTTP caching semantics (RFC 9111).
+type Cache struct {
+	// MaxEntries defines the maximum number of cached responses.
+	// Default: 1000.
+	MaxEntries *int `json:"maxEntries,omitempty"`
+	// MaxBodyBytes defines the maximum size (in bytes) of the body of a cached response.
+	// Default: 1048576 (1Mi).
+	MaxBodyBytes *int64 `json:"maxBodyBytes,omitempty"`
+	// MaxMemory defines the maximum size (in bytes) of the responses stored in memory.
+	// Default: 67108864 (64Mi).
+	MaxMemory *int64 `json:"maxMemory,omitempty"`
+	// DefaultTTL defines how long a cacheable response without explicit expiration time is considered fresh.
+	// Default: 0 (such responses are only reused after a revalidation).
+	DefaultTTL *intstr.IntOrString `json:"defaultTTL,omitempty"`
+	// StatusHeader defines the name of the response header reporting the cache status of the responses.
+	// Default: X-Cache-Status.
+	StatusHeader string `json:"statusHeader,omitempty"`
+	// Disk defines the on-disk store of the cached responses.
+	// If not set, the responses are only stored in memory.
+	Disk *dynamic.CacheDisk `json:"disk,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // CircuitBreaker holds the circuit breaker configuration.
 type CircuitBreaker struct {
 	// Expression is the condition that triggers the tripped state.
@@ -119,6 +151,26 @@ type BasicAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// APIKey holds the API key middleware configuration.
+// This middleware restricts access to your services to the requests bearing a known API key.
+type APIKey struct {
+	// Secret is the name of the referenced Kubernetes Secret containing the authorized keys.
+	Secret string `json:"secret,omitempty"`
+	// HeaderName defines the name of the request header containing the key.
+	// Default: X-API-Key.
+	HeaderName string `json:"headerName,omitempty"`
+	// QueryParam defines the name of the query parameter containing the key.
+	QueryParam string `json:"queryParam,omitempty"`
+	// CookieName defines the name of the cookie containing the key.
+	CookieName string `json:"cookieName,omitempty"`
+	// HeaderField defines a header field to store the owner of the key.
+	HeaderField string `json:"headerField,omitempty"`
+	// RemoveKey defines whether to remove the key from the request before forwarding it to the service.
+	RemoveKey bool `json:"removeKey,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // DigestAuth holds the digest auth middleware configuration.
 // This middleware restricts access to your services to known users.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/digestauth/
@@ -155,6 +207,28 @@ type ForwardAuth struct {
 	AuthRequestHeaders []string `json:"authRequestHeaders,omitempty"`
 	// TLS defines the configuration used to secure the connection to the authentication server.
 	TLS *ClientTLS `json:"tls,omitempty"`
+	// Cache defines the caching of the authentication server responses.
+	Cache *ForwardAuthCache `json:"cache,omitempty"`
+	// ExtAuthz defines whether to call the authentication server with the Envoy ext_authz gRPC protocol, instead of HTTP.
+	// The Address is then the gRPC address (host:port) of the authentication server.
+	ExtAuthz *dynamic.ForwardAuthExtAuthz `json:"extAuthz,omitempty"`
+}
+
+// ForwardAuthCache holds the configuration of the ForwardAuth response cache.
+type ForwardAuthCache struct {
+	// TTL defines how long an authentication server response is cached.
+	// Default: 30s.
+	TTL *intstr.IntOrString `json:"ttl,omitempty"`
+	// MaxEntries defines the maximum number of cached responses.
+	// Default: 10000.
+	MaxEntries *int `json:"maxEntries,omitempty"`
+	// KeyHeaders defines the headers, sent to the authentication server, used to build the cache key.
+	KeyHeaders []string `json:"keyHeaders,omitempty"`
+	// KeyCookies defines the cookies, sent to the authentication server, used to build the cache key.
+	KeyCookies []string `json:"keyCookies,omitempty"`
+	// IgnoreURI defines whether the requested URI (X-Forwarded-Uri) is left out of the cache key,
+	// so that a response is reused for all the paths of a host.
+	IgnoreURI bool `json:"ignoreURI,omitempty"`
 }
 
 // ClientTLS holds the client TLS configuration.
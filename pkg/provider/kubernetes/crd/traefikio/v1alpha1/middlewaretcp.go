@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"traefik/v3/pkg/config/dynamic"
 )
 
@@ -27,6 +28,35 @@ type MiddlewareTCPSpec struct {
 	InFlightConn *dynamic.TCPInFlightConn `json:"inFlightConn,omitempty"`
 	// IPAllowList defines the IPAllowList middleware configuration.
 	IPAllowList *dynamic.TCPIPAllowList `json:"ipAllowList,omitempty"`
+	// IPDenyList defines the IPDenyList middleware configuration.
+	IPDenyList *dynamic.TCPIPDenyList `json:"ipDenyList,omitempty"`
+	// RateLimit defines the RateLimit middleware configuration.
+	RateLimit *TCPRateLimit `json:"rateLimit,omitempty"`
+	// Timeout defines the Timeout middleware configuration.
+	Timeout *TCPTimeout `json:"timeout,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPRateLimit holds the TCP rate limit configuration.
+// This middleware limits the rate of the new connections for one IP,
+// and optionally the throughput of each connection.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/tcp/ratelimit/
+type TCPRateLimit struct {
+	// Average is the maximum rate, by default in connections/s, allowed for one IP.
+	// It defaults to 0, which means no rate limiting of the new connections.
+	// The rate is actually defined by dividing Average by Period. So for a rate below 1conn/s,
+	// one needs to define a Period larger than a second.
+	Average int64 `json:"average,omitempty"`
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period *intstr.IntOrString `json:"period,omitempty"`
+	// Burst is the maximum number of connections allowed to be opened in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst *int64 `json:"burst,omitempty"`
+	// BytesPerSecond is the maximum throughput of each connection, in bytes/s, in each direction.
+	// It defaults to 0, which means no throttling of the connections.
+	BytesPerSecond int64 `json:"bytesPerSecond,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -41,3 +71,17 @@ type MiddlewareTCPList struct {
 	// Items is the list of MiddlewareTCP.
 	Items []MiddlewareTCP `json:"items"`
 }
+
+// +k8s:deepcopy-gen=true
+
+// TCPTimeout holds the TCP timeout configuration.
+// This middleware closes the connections which are idle for too long, or which are opened for too long.
+// More info: https://doc.traefik.io/traefik/v3.0/middlewares/tcp/timeout/
+type TCPTimeout struct {
+	// IdleTimeout is the maximum duration without traffic in either direction, after which the connection is closed.
+	// It defaults to 0, which means no idle timeout.
+	IdleTimeout *intstr.IntOrString `json:"idleTimeout,omitempty"`
+	// MaxLifetime is the maximum duration of the connection, after which it is closed, even if it is not idle.
+	// It defaults to 0, which means no maximum duration.
+	MaxLifetime *intstr.IntOrString `json:"maxLifetime,omitempty"`
+}
@@ -12,17 +12,23 @@ import (
 	"traefik/v3/pkg/config/runtime"
 	"traefik/v3/pkg/middlewares/addprefix"
 	"traefik/v3/pkg/middlewares/auth"
+	"traefik/v3/pkg/middlewares/bodyrewrite"
 	"traefik/v3/pkg/middlewares/buffering"
+	"traefik/v3/pkg/middlewares/cache"
 	"traefik/v3/pkg/middlewares/chain"
 	"traefik/v3/pkg/middlewares/circuitbreaker"
 	"traefik/v3/pkg/middlewares/compress"
 	"traefik/v3/pkg/middlewares/contenttype"
 	"traefik/v3/pkg/middlewares/customerrors"
+	"traefik/v3/pkg/middlewares/decompress"
+	"traefik/v3/pkg/middlewares/geoip"
 	"traefik/v3/pkg/middlewares/grpcweb"
 	"traefik/v3/pkg/middlewares/headers"
 	"traefik/v3/pkg/middlewares/inflightreq"
 	"traefik/v3/pkg/middlewares/ipallowlist"
+	"traefik/v3/pkg/middlewares/ipdenylist"
 	"traefik/v3/pkg/middlewares/passtlsclientcert"
+	"traefik/v3/pkg/middlewares/quota"
 	"traefik/v3/pkg/middlewares/ratelimiter"
 	"traefik/v3/pkg/middlewares/redirect"
 	"traefik/v3/pkg/middlewares/replacepath"
@@ -130,6 +136,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// BodyRewrite
+	if config.BodyRewrite != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return bodyrewrite.New(ctx, next, *config.BodyRewrite, middlewareName)
+		}
+	}
+
 	// Buffering
 	if config.Buffering != nil {
 		if middleware != nil {
@@ -140,6 +156,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Cache
+	if config.Cache != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return cache.New(ctx, next, *config.Cache, middlewareName)
+		}
+	}
+
 	// Chain
 	if config.Chain != nil {
 		if middleware != nil {
@@ -196,6 +222,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Decompress
+	if config.Decompress != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return decompress.New(ctx, next, *config.Decompress, middlewareName)
+		}
+	}
+
 	// DigestAuth
 	if config.DigestAuth != nil {
 		if middleware != nil {
@@ -246,6 +282,26 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// IPDenyList
+	if config.IPDenyList != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return ipdenylist.New(ctx, next, *config.IPDenyList, middlewareName)
+		}
+	}
+
+	// GeoIP
+	if config.GeoIP != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return geoip.New(ctx, next, *config.GeoIP, middlewareName)
+		}
+	}
+
 	// InFlightReq
 	if config.InFlightReq != nil {
 		if middleware != nil {
@@ -256,6 +312,36 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// JWT
+	if config.JWT != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewJWT(ctx, next, *config.JWT, middlewareName)
+		}
+	}
+
+	// OIDC
+	if config.OIDC != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewOIDC(ctx, next, *config.OIDC, middlewareName)
+		}
+	}
+
+	// APIKey
+	if config.APIKey != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewAPIKey(ctx, next, *config.APIKey, middlewareName)
+		}
+	}
+
 	// PassTLSClientCert
 	if config.PassTLSClientCert != nil {
 		if middleware != nil {
@@ -272,7 +358,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName)
+			return ratelimiter.New(ctx, next, *config.RateLimit, b.serviceBuilder, middlewareName)
+		}
+	}
+
+	// Quota
+	if config.Quota != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return quota.New(ctx, next, *config.Quota, config, middlewareName)
 		}
 	}
 
@@ -6,8 +6,12 @@ import (
 	"strings"
 
 	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/metrics"
 	"traefik/v3/pkg/middlewares/tcp/inflightconn"
 	"traefik/v3/pkg/middlewares/tcp/ipallowlist"
+	"traefik/v3/pkg/middlewares/tcp/ipdenylist"
+	"traefik/v3/pkg/middlewares/tcp/ratelimiter"
+	"traefik/v3/pkg/middlewares/tcp/timeout"
 	"traefik/v3/pkg/server/provider"
 	"traefik/v3/pkg/tcp"
 )
@@ -20,12 +24,18 @@ const (
 
 // Builder the middleware builder.
 type Builder struct {
-	configs map[string]*runtime.TCPMiddlewareInfo
+	configs         map[string]*runtime.TCPMiddlewareInfo
+	metricsRegistry metrics.Registry
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.TCPMiddlewareInfo) *Builder {
-	return &Builder{configs: configs}
+// A nil metrics registry is replaced by a void one.
+func NewBuilder(configs map[string]*runtime.TCPMiddlewareInfo, metricsRegistry metrics.Registry) *Builder {
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &Builder{configs: configs, metricsRegistry: metricsRegistry}
 }
 
 // BuildChain creates a middleware chain.
@@ -101,6 +111,27 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// IPDenyList
+	if config.IPDenyList != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return ipdenylist.New(ctx, next, *config.IPDenyList, middlewareName)
+		}
+	}
+
+	// RateLimit
+	if config.RateLimit != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName, b.metricsRegistry.TCPRateLimitRejectedConnsCounter())
+		}
+	}
+
+	// Timeout
+	if config.Timeout != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return timeout.New(ctx, next, *config.Timeout, middlewareName)
+		}
+	}
+
 	if middleware == nil {
 		return nil, fmt.Errorf("invalid middleware %q configuration: invalid middleware type or middleware does not exist", middlewareName)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"traefik/v3/pkg/config/runtime"
 	"traefik/v3/pkg/logs"
+	"traefik/v3/pkg/middlewares/accesslog"
 	"traefik/v3/pkg/middlewares/snicheck"
 	httpmuxer "traefik/v3/pkg/muxer/http"
 	tcpmuxer "traefik/v3/pkg/muxer/tcp"
@@ -30,6 +31,7 @@ func NewManager(conf *runtime.Configuration,
 	httpHandlers map[string]http.Handler,
 	httpsHandlers map[string]http.Handler,
 	tlsManager *traefiktls.Manager,
+	accessLogger *accesslog.TCPHandler,
 ) *Manager {
 	return &Manager{
 		serviceManager:     serviceManager,
@@ -37,6 +39,7 @@ func NewManager(conf *runtime.Configuration,
 		httpHandlers:       httpHandlers,
 		httpsHandlers:      httpsHandlers,
 		tlsManager:         tlsManager,
+		accessLogger:       accessLogger,
 		conf:               conf,
 	}
 }
@@ -48,6 +51,7 @@ type Manager struct {
 	httpHandlers       map[string]http.Handler
 	httpsHandlers      map[string]http.Handler
 	tlsManager         *traefiktls.Manager
+	accessLogger       *accesslog.TCPHandler
 	conf               *runtime.Configuration
 }
 
@@ -311,7 +315,7 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 		if routerConfig.TLS == nil {
 			logger.Debug().Msgf("Adding route for %q", routerConfig.Rule)
 
-			if err := router.AddRoute(routerConfig.Rule, routerConfig.Priority, handler); err != nil {
+			if err := router.AddRoute(routerConfig.Rule, routerConfig.Priority, m.withAccessLog(routerName, handler)); err != nil {
 				routerConfig.AddError(err, true)
 				logger.Error().Err(err).Send()
 			}
@@ -321,7 +325,7 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 		if routerConfig.TLS.Passthrough {
 			logger.Debug().Msgf("Adding Passthrough route for %q", routerConfig.Rule)
 
-			if err := router.muxerTCPTLS.AddRoute(routerConfig.Rule, routerConfig.Priority, handler); err != nil {
+			if err := router.muxerTCPTLS.AddRoute(routerConfig.Rule, routerConfig.Priority, m.withAccessLog(routerName, handler)); err != nil {
 				routerConfig.AddError(err, true)
 				logger.Error().Err(err).Send()
 			}
@@ -389,7 +393,7 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 
 		logger.Debug().Msgf("Adding TLS route for %q", routerConfig.Rule)
 
-		if err := router.muxerTCPTLS.AddRoute(routerConfig.Rule, routerConfig.Priority, handler); err != nil {
+		if err := router.muxerTCPTLS.AddRoute(routerConfig.Rule, routerConfig.Priority, m.withAccessLog(routerName, handler)); err != nil {
 			routerConfig.AddError(err, true)
 			logger.Error().Err(err).Send()
 			continue
@@ -397,6 +401,19 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 	}
 }
 
+// withAccessLog wraps the handler of the given router with the TCP access log, when enabled.
+func (m *Manager) withAccessLog(routerName string, handler tcp.Handler) tcp.Handler {
+	if m.accessLogger == nil {
+		return handler
+	}
+
+	handler = accesslog.NewTCPFieldHandler(handler, accesslog.RouterName, routerName)
+
+	return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		m.accessLogger.ServeTCP(conn, handler)
+	})
+}
+
 func (m *Manager) buildTCPHandler(ctx context.Context, router *runtime.TCPRouterInfo) (tcp.Handler, error) {
 	var qualifiedNames []string
 	for _, name := range router.Middlewares {
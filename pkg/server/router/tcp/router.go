@@ -174,7 +174,7 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 	// Contains also TCP TLS passthrough routes.
 	handlerTCPTLS, catchAllTCPTLS := r.muxerTCPTLS.Match(connData)
 	if handlerTCPTLS != nil && !catchAllTCPTLS {
-		handlerTCPTLS.ServeTCP(r.GetConn(conn, hello.peeked))
+		handlerTCPTLS.ServeTCP(r.getTLSConn(conn, hello))
 		return
 	}
 
@@ -188,7 +188,7 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 
 	// Fallback on TCP TLS catchAll.
 	if handlerTCPTLS != nil {
-		handlerTCPTLS.ServeTCP(r.GetConn(conn, hello.peeked))
+		handlerTCPTLS.ServeTCP(r.getTLSConn(conn, hello))
 		return
 	}
 
@@ -226,6 +226,15 @@ func (r *Router) GetConn(conn tcp.WriteCloser, peeked string) tcp.WriteCloser {
 	return conn
 }
 
+// getTLSConn creates a connection proxy with the peeked bytes and the server name of the given TLS client hello.
+func (r *Router) getTLSConn(conn tcp.WriteCloser, hello *clientHello) tcp.WriteCloser {
+	return &Conn{
+		Peeked:      []byte(hello.peeked),
+		serverName:  hello.serverName,
+		WriteCloser: conn,
+	}
+}
+
 // GetHTTPHandler gets the attached http handler.
 func (r *Router) GetHTTPHandler() http.Handler {
 	return r.httpHandler
@@ -302,6 +311,9 @@ type Conn struct {
 	// It set to nil by Read when fully consumed.
 	Peeked []byte
 
+	// serverName is the server name (SNI) of the TLS client hello, if any.
+	serverName string
+
 	// Conn is the underlying connection.
 	// It can be type asserted against *net.TCPConn or other types as needed.
 	// It should not be read from directly unless Peeked is nil.
@@ -321,6 +333,11 @@ func (c *Conn) Read(p []byte) (n int, err error) {
 	return c.WriteCloser.Read(p)
 }
 
+// ServerName returns the server name (SNI) requested by the client, if any.
+func (c *Conn) ServerName() string {
+	return c.serverName
+}
+
 type clientHello struct {
 	serverName string   // SNI server name
 	protos     []string // ALPN protocols list
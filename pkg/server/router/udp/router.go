@@ -8,6 +8,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"traefik/v3/pkg/config/runtime"
 	"traefik/v3/pkg/logs"
+	udpmuxer "traefik/v3/pkg/muxer/udp"
 	"traefik/v3/pkg/server/provider"
 	udpservice "traefik/v3/pkg/server/service/udp"
 	"traefik/v3/pkg/udp"
@@ -50,37 +51,46 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 		logger := log.Ctx(rootCtx).With().Str(logs.EntryPointName, entryPointName).Logger()
 		ctx := logger.WithContext(rootCtx)
 
-		if len(routers) > 1 {
-			logger.Warn().Msg("Config has more than one udp router for a given entrypoint.")
+		handler, err := m.buildEntryPointHandler(ctx, routers)
+		if err != nil {
+			logger.Error().Err(err).Send()
+			continue
 		}
 
-		handlers := m.buildEntryPointHandlers(ctx, routers)
-
-		if len(handlers) > 0 {
-			// As UDP support only one router per entrypoint, we only take the first one.
-			entryPointHandlers[entryPointName] = handlers[0]
+		if handler != nil {
+			entryPointHandlers[entryPointName] = handler
 		}
 	}
 	return entryPointHandlers
 }
 
-func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) []udp.Handler {
+// buildEntryPointHandler returns the muxer routing the sessions between the given routers,
+// or nil if none of the routers could be built.
+func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) (udp.Handler, error) {
+	muxer, err := udpmuxer.NewMuxer()
+	if err != nil {
+		return nil, err
+	}
+
 	var rtNames []string
 	for routerName := range configs {
 		rtNames = append(rtNames, routerName)
 	}
 
+	// Among routers with the same priority, the first added one takes precedence.
 	sort.Slice(rtNames, func(i, j int) bool {
 		return rtNames[i] > rtNames[j]
 	})
 
-	var handlers []udp.Handler
-
 	for _, routerName := range rtNames {
 		routerConfig := configs[routerName]
 		logger := log.Ctx(ctx).With().Str(logs.RouterName, routerName).Logger()
 		ctxRouter := logger.WithContext(provider.AddInContext(ctx, routerName))
 
+		if routerConfig.Priority == 0 {
+			routerConfig.Priority = udpmuxer.GetRulePriority(routerConfig.Rule)
+		}
+
 		if routerConfig.Service == "" {
 			err := errors.New("the service is missing on the udp router")
 			routerConfig.AddError(err, true)
@@ -95,8 +105,17 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 			continue
 		}
 
-		handlers = append(handlers, handler)
+		logger.Debug().Msgf("Adding route for %q", routerConfig.Rule)
+
+		if err := muxer.AddRoute(routerConfig.Rule, routerConfig.Priority, handler); err != nil {
+			routerConfig.AddError(err, true)
+			logger.Error().Err(err).Send()
+		}
+	}
+
+	if !muxer.HasRoutes() {
+		return nil, nil
 	}
 
-	return handlers
+	return muxer, nil
 }
@@ -7,6 +7,7 @@ import (
 	"traefik/v3/pkg/config/runtime"
 	"traefik/v3/pkg/config/static"
 	"traefik/v3/pkg/metrics"
+	"traefik/v3/pkg/middlewares/accesslog"
 	"traefik/v3/pkg/server/middleware"
 	tcpmiddleware "traefik/v3/pkg/server/middleware/tcp"
 	"traefik/v3/pkg/server/router"
@@ -35,12 +36,15 @@ type RouterFactory struct {
 
 	dialerManager *tcp.DialerManager
 
+	tcpAccessLogger *accesslog.TCPHandler
+
 	cancelPrevState func()
 }
 
 // NewRouterFactory creates a new RouterFactory.
 func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager,
 	chainBuilder *middleware.ChainBuilder, pluginBuilder middleware.PluginsBuilder, metricsRegistry metrics.Registry, dialerManager *tcp.DialerManager,
+	tcpAccessLogger *accesslog.TCPHandler,
 ) *RouterFactory {
 	var entryPointsTCP, entryPointsUDP []string
 	for name, cfg := range staticConfiguration.EntryPoints {
@@ -66,6 +70,7 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 		chainBuilder:    chainBuilder,
 		pluginBuilder:   pluginBuilder,
 		dialerManager:   dialerManager,
+		tcpAccessLogger: tcpAccessLogger,
 	}
 }
 
@@ -91,18 +96,22 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	serviceManager.LaunchHealthCheck(ctx)
 
 	// TCP
-	svcTCPManager := tcpsvc.NewManager(rtConf, f.dialerManager)
+	svcTCPManager := tcpsvc.NewManager(rtConf, f.dialerManager, f.metricsRegistry)
 
-	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares)
+	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares, f.metricsRegistry)
 
-	rtTCPManager := tcprouter.NewManager(rtConf, svcTCPManager, middlewaresTCPBuilder, handlersNonTLS, handlersTLS, f.tlsManager)
+	rtTCPManager := tcprouter.NewManager(rtConf, svcTCPManager, middlewaresTCPBuilder, handlersNonTLS, handlersTLS, f.tlsManager, f.tcpAccessLogger)
 	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
 
+	svcTCPManager.LaunchHealthCheck(ctx)
+
 	// UDP
-	svcUDPManager := udpsvc.NewManager(rtConf)
+	svcUDPManager := udpsvc.NewManager(rtConf, f.metricsRegistry)
 	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager)
 	routersUDP := rtUDPManager.BuildHandlers(ctx, f.entryPointsUDP)
 
+	svcUDPManager.LaunchHealthCheck(ctx)
+
 	rtConf.PopulateUsedBy()
 
 	return routersTCP, routersUDP
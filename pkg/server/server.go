@@ -22,6 +22,7 @@ type Server struct {
 	chainBuilder   *middleware.ChainBuilder
 
 	accessLoggerMiddleware *accesslog.Handler
+	tcpAccessLogger        *accesslog.TCPHandler
 
 	signals  chan os.Signal
 	stopChan chan bool
@@ -31,13 +32,14 @@ type Server struct {
 
 // NewServer returns an initialized Server.
 func NewServer(routinesPool *safe.Pool, entryPoints TCPEntryPoints, entryPointsUDP UDPEntryPoints, watcher *ConfigurationWatcher,
-	chainBuilder *middleware.ChainBuilder, accessLoggerMiddleware *accesslog.Handler,
+	chainBuilder *middleware.ChainBuilder, accessLoggerMiddleware *accesslog.Handler, tcpAccessLogger *accesslog.TCPHandler,
 ) *Server {
 	srv := &Server{
 		watcher:                watcher,
 		tcpEntryPoints:         entryPoints,
 		chainBuilder:           chainBuilder,
 		accessLoggerMiddleware: accessLoggerMiddleware,
+		tcpAccessLogger:        tcpAccessLogger,
 		signals:                make(chan os.Signal, 1),
 		stopChan:               make(chan bool, 1),
 		routinesPool:           routinesPool,
@@ -106,6 +108,12 @@ func (s *Server) Close() {
 
 	s.chainBuilder.Close()
 
+	if s.tcpAccessLogger != nil {
+		if err := s.tcpAccessLogger.Close(); err != nil {
+			log.Error().Err(err).Msg("Could not close the TCP access log file")
+		}
+	}
+
 	cancel()
 }
 
@@ -29,6 +29,12 @@ func (s *Server) listenSignals(ctx context.Context) {
 						log.Error().Err(err).Msg("Error rotating access log")
 					}
 				}
+
+				if s.tcpAccessLogger != nil {
+					if err := s.tcpAccessLogger.Rotate(); err != nil {
+						log.Error().Err(err).Msg("Error rotating TCP access log")
+					}
+				}
 			}
 		}
 	}
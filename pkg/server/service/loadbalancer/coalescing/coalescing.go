@@ -0,0 +1,373 @@
+// Package coalescing implements a handler collapsing the concurrent identical GET requests
+// into a single request to the servers, whose response is written to all of them.
+package coalescing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/healthcheck"
+)
+
+// defaultIdentityHeaders are the request headers always identifying the clients.
+var defaultIdentityHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// Coalescer is an http.Handler forwarding a single request at a time for identical GET requests.
+// The requests arriving while an identical request is in flight wait for its response,
+// and are forwarded on their own if the response cannot be shared.
+type Coalescer struct {
+	handler     http.Handler
+	maxBodySize int64
+	// identityHeaders holds the canonical names of the request headers identifying the clients.
+	identityHeaders []string
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// New returns a new instance of *Coalescer.
+func New(handler http.Handler, config *dynamic.Coalescing) *Coalescer {
+	identityHeaders := append([]string(nil), defaultIdentityHeaders...)
+	for _, name := range config.IdentityHeaders {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(identityHeaders, name) {
+			identityHeaders = append(identityHeaders, name)
+		}
+	}
+
+	return &Coalescer{
+		handler:         handler,
+		maxBodySize:     config.MaxBodySize,
+		identityHeaders: identityHeaders,
+		calls:           make(map[string]*call),
+	}
+}
+
+func (c *Coalescer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !coalescable(req) {
+		c.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	key := c.requestKey(req)
+
+	c.mu.Lock()
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		c.wait(rw, req, cl)
+		return
+	}
+
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	recorder := &responseRecorder{
+		rw:          rw,
+		header:      make(http.Header),
+		maxBodySize: c.maxBodySize,
+		abandon: func() {
+			c.finish(key, cl, nil)
+		},
+	}
+
+	// The waiting requests must be released even if the handler panics.
+	defer func() {
+		var resp *response
+		if recorder.shareable() && req.Context().Err() == nil {
+			resp = recorder.response(req)
+		}
+
+		c.finish(key, cl, resp)
+	}()
+
+	c.handler.ServeHTTP(recorder, req)
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the handler of the Coalescer changes.
+// Not thread safe.
+func (c *Coalescer) RegisterStatusUpdater(fn func(up bool)) error {
+	updater, ok := c.handler.(healthcheck.StatusUpdater)
+	if !ok {
+		return fmt.Errorf("service of coalescer %T not a healthcheck.StatusUpdater", c.handler)
+	}
+
+	return updater.RegisterStatusUpdater(fn)
+}
+
+// wait waits for the response of the in-flight call, and writes it if it can be shared with the request,
+// or forwards the request on its own otherwise.
+func (c *Coalescer) wait(rw http.ResponseWriter, req *http.Request, cl *call) {
+	select {
+	case <-cl.done:
+	case <-req.Context().Done():
+		return
+	}
+
+	if cl.resp == nil || !cl.resp.matches(req) {
+		c.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	for name, values := range cl.resp.header {
+		rw.Header()[name] = append([]string(nil), values...)
+	}
+
+	rw.WriteHeader(cl.resp.code)
+
+	if _, err := rw.Write(cl.resp.body); err != nil {
+		log.Ctx(req.Context()).Debug().Err(err).Msg("Error while writing coalesced response")
+	}
+}
+
+// finish removes the call, so that the next identical requests are forwarded,
+// and releases the waiting requests with the given response, which is nil if it cannot be shared.
+func (c *Coalescer) finish(key string, cl *call, resp *response) {
+	cl.once.Do(func() {
+		c.mu.Lock()
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		c.mu.Unlock()
+
+		cl.resp = resp
+		close(cl.done)
+	})
+}
+
+// call is a request in flight, for which the identical requests wait.
+type call struct {
+	once sync.Once
+	done chan struct{}
+	// resp is the shared response, set before done is closed.
+	resp *response
+}
+
+// response is a response which can be shared by the coalesced requests.
+type response struct {
+	code   int
+	header http.Header
+	body   []byte
+
+	// vary holds the values of the request headers listed in the Vary header of the response.
+	vary map[string][]string
+}
+
+// matches reports whether the response can be written to the given request,
+// i.e. whether the request headers listed in the Vary header have the same values.
+func (r *response) matches(req *http.Request) bool {
+	for name, values := range r.vary {
+		if strings.Join(req.Header.Values(name), ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// coalescable reports whether the request can be coalesced with the identical ones.
+// The requests with a body, the range requests, and the upgrade requests, such as the WebSocket handshakes, are never coalesced.
+func coalescable(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		(req.ContentLength == 0 || req.Body == nil || req.Body == http.NoBody) &&
+		req.Header.Get("Range") == "" &&
+		req.Header.Get("Upgrade") == ""
+}
+
+// requestKey returns the key identifying the identical requests.
+// The identity headers of the requests are part of the key, so that a response is only shared between requests of the same client.
+func (c *Coalescer) requestKey(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	var key strings.Builder
+	key.WriteString(scheme + "://" + req.Host + req.URL.RequestURI())
+
+	for _, name := range c.identityHeaders {
+		for _, value := range req.Header.Values(name) {
+			_, _ = fmt.Fprintf(&key, "\n%s: %s", name, value)
+		}
+	}
+
+	return key.String()
+}
+
+// responseRecorder forwards the response to the client, while keeping a copy of it up to the maximum body size.
+// As soon as the response cannot be shared, it calls abandon, so that the waiting requests do not wait for its end.
+type responseRecorder struct {
+	rw          http.ResponseWriter
+	maxBodySize int64
+	abandon     func()
+
+	// header holds the headers set by the handler, until the final response header is written.
+	header      http.Header
+	code        int
+	wroteHeader bool
+	body        bytes.Buffer
+	abandoned   bool
+}
+
+func (r *responseRecorder) Header() http.Header {
+	if r.wroteHeader {
+		return r.rw.Header()
+	}
+
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.wroteHeader {
+		return
+	}
+
+	// Only the headers set by the handler are recorded,
+	// and not the ones already set on the response writer for this specific request.
+	for k, v := range r.header {
+		r.rw.Header()[k] = v
+	}
+
+	// Informational responses are forwarded, and the final response is still to come.
+	if code >= 100 && code <= 199 {
+		r.rw.WriteHeader(code)
+		return
+	}
+
+	r.code = code
+	r.wroteHeader = true
+
+	if !shareableHeader(r.header) {
+		r.giveUp()
+	}
+
+	r.rw.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(buf []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.abandoned {
+		if int64(r.body.Len()+len(buf)) > r.maxBodySize {
+			r.giveUp()
+		} else {
+			r.body.Write(buf)
+		}
+	}
+
+	n, err := r.rw.Write(buf)
+	if err != nil {
+		r.giveUp()
+	}
+
+	return n, err
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	r.giveUp()
+
+	return hijacker.Hijack()
+}
+
+func (r *responseRecorder) giveUp() {
+	if r.abandoned {
+		return
+	}
+
+	r.abandoned = true
+	r.body = bytes.Buffer{}
+	r.abandon()
+}
+
+func (r *responseRecorder) shareable() bool {
+	return r.wroteHeader && !r.abandoned
+}
+
+func (r *responseRecorder) response(req *http.Request) *response {
+	resp := &response{
+		code:   r.code,
+		header: r.header,
+		body:   r.body.Bytes(),
+		vary:   make(map[string][]string),
+	}
+
+	for _, value := range r.header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				resp.vary[name] = req.Header.Values(name)
+			}
+		}
+	}
+
+	return resp
+}
+
+// shareableHeader reports whether a response with the given header can be written to other clients:
+// only the responses explicitly cacheable by shared caches are,
+// unless they are private, no-store, no-cache, cookie-setting, streamed, or varying on every request.
+func shareableHeader(header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+
+	if strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+
+	var cacheable bool
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(directive, "=")
+
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "private", "no-store", "no-cache":
+				return false
+			case "public", "s-maxage", "max-age":
+				cacheable = true
+			}
+		}
+	}
+
+	if !cacheable {
+		return false
+	}
+
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.TrimSpace(name) == "*" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,325 @@
+package coalescing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestCoalescer_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		identityHeaders []string
+		responseHeaders map[string]string
+		body            string
+		waiterHeaders   map[string]string
+		expectedCalls   int64
+	}{
+		{
+			desc:            "identical requests",
+			responseHeaders: map[string]string{"Cache-Control": "public"},
+			body:            "foo",
+			expectedCalls:   1,
+		},
+		{
+			desc:            "shared max age",
+			responseHeaders: map[string]string{"Cache-Control": "s-maxage=60"},
+			body:            "foo",
+			expectedCalls:   1,
+		},
+		{
+			desc:          "response without cache control",
+			body:          "foo",
+			expectedCalls: 4,
+		},
+		{
+			desc:            "no-cache response",
+			responseHeaders: map[string]string{"Cache-Control": "no-cache, max-age=60"},
+			body:            "foo",
+			expectedCalls:   4,
+		},
+		{
+			desc:            "response setting a cookie",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60", "Set-Cookie": "session=foo"},
+			body:            "foo",
+			expectedCalls:   4,
+		},
+		{
+			desc:            "private response",
+			responseHeaders: map[string]string{"Cache-Control": "private, max-age=60"},
+			body:            "foo",
+			expectedCalls:   4,
+		},
+		{
+			desc:            "no-store response",
+			responseHeaders: map[string]string{"Cache-Control": "no-store"},
+			body:            "foo",
+			expectedCalls:   4,
+		},
+		{
+			desc:            "event stream",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60", "Content-Type": "text/event-stream"},
+			body:            "foo",
+			expectedCalls:   4,
+		},
+		{
+			desc:            "response larger than max body size",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60"},
+			body:            strings.Repeat("a", 11),
+			expectedCalls:   4,
+		},
+		{
+			desc:            "matching vary header",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60", "Vary": "Accept-Encoding"},
+			body:            "foo",
+			expectedCalls:   1,
+		},
+		{
+			desc:            "vary header mismatch",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60", "Vary": "Accept-Encoding"},
+			body:            "foo",
+			waiterHeaders:   map[string]string{"Accept-Encoding": "gzip"},
+			expectedCalls:   4,
+		},
+		{
+			desc:            "vary star",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60", "Vary": "*"},
+			body:            "foo",
+			expectedCalls:   4,
+		},
+		{
+			desc:            "different credentials",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60"},
+			body:            "foo",
+			waiterHeaders:   map[string]string{"Authorization": "Basic Zm9vOmJhcg=="},
+			expectedCalls:   2,
+		},
+		{
+			desc:            "different proxy credentials",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60"},
+			body:            "foo",
+			waiterHeaders:   map[string]string{"Proxy-Authorization": "Basic Zm9vOmJhcg=="},
+			expectedCalls:   2,
+		},
+		{
+			desc:            "different first identity header",
+			identityHeaders: []string{"x-api-key", "X-Tenant"},
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60"},
+			body:            "foo",
+			waiterHeaders:   map[string]string{"X-Api-Key": "bar"},
+			expectedCalls:   2,
+		},
+		{
+			desc:            "different second identity header",
+			identityHeaders: []string{"x-api-key", "X-Tenant"},
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60"},
+			body:            "foo",
+			waiterHeaders:   map[string]string{"X-Tenant": "baz"},
+			expectedCalls:   2,
+		},
+		{
+			desc:            "header not identifying the clients",
+			responseHeaders: map[string]string{"Cache-Control": "max-age=60"},
+			body:            "foo",
+			waiterHeaders:   map[string]string{"X-Api-Key": "bar"},
+			expectedCalls:   1,
+		},
+		{
+			desc:          "range requests",
+			body:          "foo",
+			waiterHeaders: map[string]string{"Range": "bytes=0-1"},
+			expectedCalls: 4,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			called := make(chan struct{}, 10)
+			release := make(chan struct{})
+
+			var calls atomic.Int64
+			handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls.Add(1)
+				called <- struct{}{}
+				<-release
+
+				for name, value := range test.responseHeaders {
+					rw.Header().Set(name, value)
+				}
+				rw.WriteHeader(http.StatusAccepted)
+				_, _ = rw.Write([]byte(test.body))
+			})
+
+			coalescer := New(handler, &dynamic.Coalescing{MaxBodySize: 10, IdentityHeaders: test.identityHeaders})
+
+			recorders := make([]*httptest.ResponseRecorder, 4)
+
+			var wg sync.WaitGroup
+			serve := func(i int, headers map[string]string) {
+				defer wg.Done()
+
+				req := httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil)
+				for name, value := range headers {
+					req.Header.Set(name, value)
+				}
+
+				recorders[i] = httptest.NewRecorder()
+				coalescer.ServeHTTP(recorders[i], req)
+			}
+
+			wg.Add(1)
+			go serve(0, nil)
+			<-called
+
+			for i := 1; i < len(recorders); i++ {
+				wg.Add(1)
+				go serve(i, test.waiterHeaders)
+			}
+
+			// Gives the time to the identical requests to wait for the response of the first one.
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, test.expectedCalls, calls.Load())
+
+			for i, recorder := range recorders {
+				assert.Equal(t, http.StatusAccepted, recorder.Code, i)
+				assert.Equal(t, test.body, recorder.Body.String(), i)
+
+				for name, value := range test.responseHeaders {
+					assert.Equal(t, value, recorder.Header().Get(name), i)
+				}
+			}
+		})
+	}
+}
+
+func TestCoalescer_ServeHTTP_abandon(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	written := make(chan struct{})
+
+	var calls atomic.Int64
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if calls.Add(1) > 1 {
+			_, _ = rw.Write([]byte("waiter"))
+			return
+		}
+
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte(strings.Repeat("a", 20)))
+		close(written)
+		<-release
+	})
+
+	coalescer := New(handler, &dynamic.Coalescing{MaxBodySize: 10})
+
+	go coalescer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil))
+	<-written
+
+	// The first response is too large to be shared, so the identical requests do not wait for its end.
+	recorder := httptest.NewRecorder()
+	coalescer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil))
+
+	assert.Equal(t, "waiter", recorder.Body.String())
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestCoalescer_ServeHTTP_canceledWaiter(t *testing.T) {
+	called := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(called)
+		<-release
+	})
+
+	coalescer := New(handler, &dynamic.Coalescing{MaxBodySize: 10})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		coalescer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil))
+	}()
+	<-called
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "http://foo.bar/baz", nil).WithContext(ctx)
+	coalescer.ServeHTTP(httptest.NewRecorder(), req)
+
+	close(release)
+	<-done
+}
+
+func TestCoalescer_ServeHTTP_notCoalescable(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		method string
+		header map[string]string
+	}{
+		{
+			desc:   "post request",
+			method: http.MethodPost,
+		},
+		{
+			desc:   "head request",
+			method: http.MethodHead,
+		},
+		{
+			desc:   "upgrade request",
+			method: http.MethodGet,
+			header: map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, "http://foo.bar/baz", nil)
+			for name, value := range test.header {
+				req.Header.Set(name, value)
+			}
+
+			assert.False(t, coalescable(req))
+		})
+	}
+}
+
+func TestCoalescer_RegisterStatusUpdater(t *testing.T) {
+	coalescer := New(http.NotFoundHandler(), &dynamic.Coalescing{})
+	require.Error(t, coalescer.RegisterStatusUpdater(func(up bool) {}))
+
+	updater := &statusUpdater{Handler: http.NotFoundHandler()}
+	coalescer = New(updater, &dynamic.Coalescing{})
+	require.NoError(t, coalescer.RegisterStatusUpdater(func(up bool) {}))
+	assert.Len(t, updater.fns, 1)
+}
+
+type statusUpdater struct {
+	http.Handler
+
+	fns []func(up bool)
+}
+
+func (s *statusUpdater) RegisterStatusUpdater(fn func(up bool)) error {
+	s.fns = append(s.fns, fn)
+	return nil
+}
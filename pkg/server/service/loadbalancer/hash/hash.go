@@ -0,0 +1,337 @@
+package hash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/vulcand/oxy/v2/utils"
+	"traefik/v3/pkg/config/dynamic"
+	"traefik/v3/pkg/middlewares"
+)
+
+// DefaultReplicas is the default number of points a server with a weight of one owns on the ring.
+const DefaultReplicas = 100
+
+type namedHandler struct {
+	http.Handler
+	name string
+}
+
+type point struct {
+	hash    uint64
+	handler *namedHandler
+}
+
+type stickyCookie struct {
+	name     string
+	secure   bool
+	httpOnly bool
+}
+
+// Balancer is a consistent hashing load balancer based on a hash ring (https://en.wikipedia.org/wiki/Consistent_hashing).
+// Each server owns a number of points on the ring, proportional to its weight,
+// and a request is forwarded to the server owning the first point following the hash of its key.
+// Adding or removing a server therefore only remaps the keys of the ring arcs it owns.
+// When the selected server is down, the next healthy server on the ring is selected.
+type Balancer struct {
+	stickyCookie     *stickyCookie
+	wantsHealthCheck bool
+	extractor        utils.SourceExtractor
+	replicas         int
+
+	mutex    sync.RWMutex
+	handlers []*namedHandler
+	ring     []point
+	// next is the index of the handler selected when a request has no hash key.
+	next int
+	// status is a record of which child services of the Balancer are healthy, keyed
+	// by name of child service. A service is initially added to the map when it is
+	// created via Add, and it is later removed or added to the map as needed,
+	// through the SetStatus method.
+	status map[string]struct{}
+	// updaters is the list of hooks that are run (to update the Balancer
+	// parent(s)), whenever the Balancer status changes.
+	updaters []func(bool)
+}
+
+// New creates a new consistent hashing load balancer.
+func New(ctx context.Context, sticky *dynamic.Sticky, config *dynamic.HashPolicy, wantHealthCheck bool) (*Balancer, error) {
+	if config == nil {
+		config = &dynamic.HashPolicy{}
+	}
+
+	extractor, err := GetKeyExtractor(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := config.Replicas
+	if replicas <= 0 {
+		replicas = DefaultReplicas
+	}
+
+	balancer := &Balancer{
+		status:           make(map[string]struct{}),
+		wantsHealthCheck: wantHealthCheck,
+		extractor:        extractor,
+		replicas:         replicas,
+	}
+	if sticky != nil && sticky.Cookie != nil {
+		balancer.stickyCookie = &stickyCookie{
+			name:     sticky.Cookie.Name,
+			secure:   sticky.Cookie.Secure,
+			httpOnly: sticky.Cookie.HTTPOnly,
+		}
+	}
+	return balancer, nil
+}
+
+// SetStatus sets on the balancer that its given child is now of the given
+// status. balancerName is only needed for logging purposes.
+func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	status := "DOWN"
+	if up {
+		status = "UP"
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
+
+	if up {
+		b.status[childName] = struct{}{}
+	} else {
+		delete(b.status, childName)
+	}
+
+	upAfter := len(b.status) > 0
+	status = "DOWN"
+	if upAfter {
+		status = "UP"
+	}
+
+	// No Status Change
+	if upBefore == upAfter {
+		// We're still with the same status, no need to propagate
+		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", status)
+		return
+	}
+
+	// Status Change
+	log.Ctx(ctx).Debug().Msgf("Propagating new %s status", status)
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the Balancer changes.
+// Not thread safe.
+func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) error {
+	if !b.wantsHealthCheck {
+		return errors.New("healthCheck not enabled in config for this hash service")
+	}
+	b.updaters = append(b.updaters, fn)
+	return nil
+}
+
+var errNoAvailableServer = errors.New("no available server")
+
+func (b *Balancer) nextServer(key string) (*namedHandler, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.ring) == 0 || len(b.status) == 0 {
+		return nil, errNoAvailableServer
+	}
+
+	if key == "" {
+		return b.nextHealthyHandler(), nil
+	}
+
+	keyHash := hashOf(key)
+	start := sort.Search(len(b.ring), func(i int) bool {
+		return b.ring[i].hash >= keyHash
+	})
+
+	for i := 0; i < len(b.ring); i++ {
+		handler := b.ring[(start+i)%len(b.ring)].handler
+		if _, ok := b.status[handler.name]; ok {
+			log.Debug().Msgf("Service selected by hash: %s", handler.name)
+			return handler, nil
+		}
+	}
+
+	return nil, errNoAvailableServer
+}
+
+// nextHealthyHandler returns, in turn, the healthy handlers.
+// It must be called with the mutex held, and at least one healthy handler.
+func (b *Balancer) nextHealthyHandler() *namedHandler {
+	for {
+		handler := b.handlers[b.next%len(b.handlers)]
+		b.next = (b.next + 1) % len(b.handlers)
+
+		if _, ok := b.status[handler.name]; ok {
+			return handler
+		}
+	}
+}
+
+func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if b.stickyCookie != nil {
+		cookie, err := req.Cookie(b.stickyCookie.name)
+
+		if err != nil && !errors.Is(err, http.ErrNoCookie) {
+			log.Warn().Err(err).Msg("Error while reading cookie")
+		}
+
+		if err == nil && cookie != nil {
+			if handler := b.stickyHandler(cookie.Value); handler != nil {
+				handler.ServeHTTP(w, req)
+				return
+			}
+		}
+	}
+
+	key, _, err := b.extractor.Extract(req)
+	if err != nil {
+		log.Debug().Err(err).Msg("Unable to extract hash key, falling back to round robin")
+		key = ""
+	}
+
+	server, err := b.nextServer(key)
+	if err != nil {
+		if errors.Is(err, errNoAvailableServer) {
+			http.Error(w, errNoAvailableServer.Error(), http.StatusServiceUnavailable)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if b.stickyCookie != nil {
+		cookie := &http.Cookie{Name: b.stickyCookie.name, Value: server.name, Path: "/", HttpOnly: b.stickyCookie.httpOnly, Secure: b.stickyCookie.secure}
+		http.SetCookie(w, cookie)
+	}
+
+	server.ServeHTTP(w, req)
+}
+
+// stickyHandler returns the healthy handler with the given name, if any.
+func (b *Balancer) stickyHandler(name string) *namedHandler {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if _, ok := b.status[name]; !ok {
+		return nil
+	}
+
+	for _, handler := range b.handlers {
+		if handler.name == name {
+			return handler
+		}
+	}
+
+	return nil
+}
+
+// Add adds a handler.
+// A handler with a non-positive weight is ignored.
+func (b *Balancer) Add(name string, handler http.Handler, weight *int) {
+	w := 1
+	if weight != nil {
+		w = *weight
+	}
+
+	if w <= 0 { // non-positive weight is meaningless
+		return
+	}
+
+	h := &namedHandler{Handler: handler, name: name}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.handlers = append(b.handlers, h)
+	b.status[name] = struct{}{}
+
+	for i := 0; i < b.replicas*w; i++ {
+		b.ring = append(b.ring, point{hash: hashOf(name + "-" + strconv.Itoa(i)), handler: h})
+	}
+
+	sort.Slice(b.ring, func(i, j int) bool {
+		if b.ring[i].hash == b.ring[j].hash {
+			// Makes the order of colliding points independent of the order in which handlers are added.
+			return b.ring[i].handler.name < b.ring[j].handler.name
+		}
+		return b.ring[i].hash < b.ring[j].hash
+	})
+}
+
+func hashOf(value string) uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(value)) // this will never return an error.
+
+	// FNV-1a poorly spreads short values differing by their last bytes,
+	// hence the additional mixing (finalizer of the SplitMix64 generator).
+	h := hasher.Sum64()
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+
+	return h
+}
+
+// GetKeyExtractor returns the function extracting the hash key of a request, corresponding to the given configuration.
+// It defaults to the request's remote address.
+// It returns an error if more than one key source is provided.
+func GetKeyExtractor(ctx context.Context, config *dynamic.HashPolicy) (utils.SourceExtractor, error) {
+	var count int
+	if config.SourceCriterion != nil {
+		count++
+	}
+	if config.RequestCookieName != "" {
+		count++
+	}
+	if config.RequestQueryParameterName != "" {
+		count++
+	}
+	if count > 1 {
+		return nil, errors.New("sourceCriterion, requestCookieName and requestQueryParameterName are mutually exclusive")
+	}
+
+	logger := log.Ctx(ctx)
+
+	if config.RequestCookieName != "" {
+		logger.Debug().Msg("Using RequestCookieName")
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			cookie, err := req.Cookie(config.RequestCookieName)
+			if err != nil {
+				return "", 0, fmt.Errorf("reading cookie %q: %w", config.RequestCookieName, err)
+			}
+			return cookie.Value, 1, nil
+		}), nil
+	}
+
+	if config.RequestQueryParameterName != "" {
+		logger.Debug().Msg("Using RequestQueryParameterName")
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			return req.URL.Query().Get(config.RequestQueryParameterName), 1, nil
+		}), nil
+	}
+
+	return middlewares.GetSourceExtractor(ctx, config.SourceCriterion)
+}
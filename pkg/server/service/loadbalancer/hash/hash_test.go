@@ -0,0 +1,247 @@
+package hash
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestBalancer_sameKeySameServer(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  *dynamic.HashPolicy
+		request func(key string) *http.Request
+	}{
+		{
+			desc: "request header",
+			config: &dynamic.HashPolicy{
+				SourceCriterion: &dynamic.SourceCriterion{RequestHeaderName: "X-Tenant"},
+			},
+			request: func(key string) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("X-Tenant", key)
+				return req
+			},
+		},
+		{
+			desc: "request host",
+			config: &dynamic.HashPolicy{
+				SourceCriterion: &dynamic.SourceCriterion{RequestHost: true},
+			},
+			request: func(key string) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "http://"+key+"/", nil)
+			},
+		},
+		{
+			desc: "client IP",
+			config: &dynamic.HashPolicy{
+				SourceCriterion: &dynamic.SourceCriterion{IPStrategy: &dynamic.IPStrategy{Depth: 1}},
+			},
+			request: func(key string) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("X-Forwarded-For", key)
+				return req
+			},
+		},
+		{
+			desc:   "remote address by default",
+			config: nil,
+			request: func(key string) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = key + ":1234"
+				return req
+			},
+		},
+		{
+			desc:   "cookie",
+			config: &dynamic.HashPolicy{RequestCookieName: "tenant"},
+			request: func(key string) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.AddCookie(&http.Cookie{Name: "tenant", Value: key})
+				return req
+			},
+		},
+		{
+			desc:   "query parameter",
+			config: &dynamic.HashPolicy{RequestQueryParameterName: "tenant"},
+			request: func(key string) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/?tenant="+key, nil)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer, err := New(context.Background(), nil, test.config, false)
+			require.NoError(t, err)
+
+			for i := 0; i < 5; i++ {
+				balancer.Add(fmt.Sprintf("server%d", i), newHandler(fmt.Sprintf("server%d", i)), nil)
+			}
+
+			servers := make(map[string]struct{})
+			for i := 0; i < 20; i++ {
+				key := fmt.Sprintf("10.0.0.%d", i)
+
+				recorder := httptest.NewRecorder()
+				balancer.ServeHTTP(recorder, test.request(key))
+				server := recorder.Header().Get("server")
+				servers[server] = struct{}{}
+
+				for j := 0; j < 3; j++ {
+					recorder = httptest.NewRecorder()
+					balancer.ServeHTTP(recorder, test.request(key))
+					assert.Equal(t, server, recorder.Header().Get("server"))
+				}
+			}
+
+			// The keys are spread over the servers.
+			assert.Greater(t, len(servers), 1)
+		})
+	}
+}
+
+func TestBalancer_minimalRemapping(t *testing.T) {
+	before, err := New(context.Background(), nil, &dynamic.HashPolicy{RequestQueryParameterName: "key"}, false)
+	require.NoError(t, err)
+
+	after, err := New(context.Background(), nil, &dynamic.HashPolicy{RequestQueryParameterName: "key"}, false)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		before.Add(fmt.Sprintf("server%d", i), newHandler(fmt.Sprintf("server%d", i)), nil)
+	}
+	// The servers are added in another order, one of them is removed and a new one is added.
+	for i := 10; i > 0; i-- {
+		after.Add(fmt.Sprintf("server%d", i), newHandler(fmt.Sprintf("server%d", i)), nil)
+	}
+
+	const keys = 1000
+	var remapped int
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("tenant-%d", i)
+
+		serverBefore, err := before.nextServer(key)
+		require.NoError(t, err)
+
+		serverAfter, err := after.nextServer(key)
+		require.NoError(t, err)
+
+		if serverBefore.name != serverAfter.name {
+			remapped++
+			// Only the keys of the removed server, or the keys now owned by the new server, are remapped.
+			assert.True(t, serverBefore.name == "server0" || serverAfter.name == "server10")
+		}
+	}
+
+	// Around one tenth of the keys belong to each of the removed and the added server.
+	assert.Less(t, remapped, keys*3/10)
+}
+
+func TestBalancer_serverDown(t *testing.T) {
+	balancer, err := New(context.Background(), nil, &dynamic.HashPolicy{RequestQueryParameterName: "key"}, false)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		balancer.Add(fmt.Sprintf("server%d", i), newHandler(fmt.Sprintf("server%d", i)), nil)
+	}
+
+	owners := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("tenant-%d", i)
+		server, err := balancer.nextServer(key)
+		require.NoError(t, err)
+		owners[key] = server.name
+	}
+
+	balancer.SetStatus(context.Background(), "server1", false)
+
+	for key, owner := range owners {
+		server, err := balancer.nextServer(key)
+		require.NoError(t, err)
+
+		if owner == "server1" {
+			assert.NotEqual(t, "server1", server.name)
+		} else {
+			assert.Equal(t, owner, server.name)
+		}
+	}
+
+	balancer.SetStatus(context.Background(), "server0", false)
+	balancer.SetStatus(context.Background(), "server2", false)
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?key=foo", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	balancer.SetStatus(context.Background(), "server1", true)
+	for key := range owners {
+		server, err := balancer.nextServer(key)
+		require.NoError(t, err)
+		assert.Equal(t, "server1", server.name)
+	}
+}
+
+func TestBalancer_noKey(t *testing.T) {
+	balancer, err := New(context.Background(), nil, &dynamic.HashPolicy{RequestCookieName: "tenant"}, false)
+	require.NoError(t, err)
+
+	balancer.Add("first", newHandler("first"), nil)
+	balancer.Add("second", newHandler("second"), nil)
+
+	servers := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		recorder := httptest.NewRecorder()
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		servers[recorder.Header().Get("server")]++
+	}
+
+	assert.Equal(t, map[string]int{"first": 2, "second": 2}, servers)
+}
+
+func TestBalancer_propagate(t *testing.T) {
+	balancer, err := New(context.Background(), nil, nil, true)
+	require.NoError(t, err)
+
+	balancer.Add("first", newHandler("first"), nil)
+
+	var statuses []bool
+	err = balancer.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	})
+	require.NoError(t, err)
+
+	balancer.SetStatus(context.Background(), "first", false)
+	balancer.SetStatus(context.Background(), "first", true)
+
+	assert.Equal(t, []bool{false, true}, statuses)
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	_, err := New(context.Background(), nil, &dynamic.HashPolicy{
+		SourceCriterion:   &dynamic.SourceCriterion{RequestHost: true},
+		RequestCookieName: "tenant",
+	}, false)
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), nil, &dynamic.HashPolicy{
+		SourceCriterion: &dynamic.SourceCriterion{RequestHost: true, RequestHeaderName: "X-Tenant"},
+	}, false)
+	assert.Error(t, err)
+}
+
+func newHandler(name string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", name)
+		rw.WriteHeader(http.StatusOK)
+	})
+}
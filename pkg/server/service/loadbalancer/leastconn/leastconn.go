@@ -0,0 +1,295 @@
+package leastconn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+type namedHandler struct {
+	http.Handler
+	name   string
+	weight float64
+	// inflight is the number of requests currently being handled by the handler.
+	inflight atomic.Int64
+}
+
+// load returns the number of outstanding requests of the handler, the next one included, relative to its weight.
+func (h *namedHandler) load() float64 {
+	return float64(h.inflight.Load()+1) / h.weight
+}
+
+func (h *namedHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.inflight.Add(1)
+	defer h.inflight.Add(-1)
+
+	h.Handler.ServeHTTP(rw, req)
+}
+
+type stickyCookie struct {
+	name     string
+	secure   bool
+	httpOnly bool
+}
+
+// Balancer is a load balancer selecting the server with the least outstanding requests.
+// When created with NewPowerOfTwoChoices, it instead picks two healthy servers at random,
+// and selects the least loaded of both (https://www.eecs.harvard.edu/~michaelm/postscripts/mythesis.pdf),
+// which avoids herding on the least loaded server when many load balancers share the same servers.
+type Balancer struct {
+	stickyCookie     *stickyCookie
+	wantsHealthCheck bool
+	powerOfTwo       bool
+
+	mutex    sync.RWMutex
+	handlers []*namedHandler
+	// healthy is the list of handlers whose status is up.
+	healthy []*namedHandler
+	// next is the index of the handler where the next scan starts,
+	// so that equally loaded handlers are selected in turn.
+	next int
+	rand *rand.Rand
+	// status is a record of which child services of the Balancer are healthy, keyed
+	// by name of child service. A service is initially added to the map when it is
+	// created via Add, and it is later removed or added to the map as needed,
+	// through the SetStatus method.
+	status map[string]struct{}
+	// updaters is the list of hooks that are run (to update the Balancer
+	// parent(s)), whenever the Balancer status changes.
+	updaters []func(bool)
+}
+
+// New creates a new least connections load balancer.
+func New(sticky *dynamic.Sticky, wantHealthCheck bool) *Balancer {
+	return newBalancer(sticky, wantHealthCheck, false)
+}
+
+// NewPowerOfTwoChoices creates a new power of two random choices load balancer.
+func NewPowerOfTwoChoices(sticky *dynamic.Sticky, wantHealthCheck bool) *Balancer {
+	return newBalancer(sticky, wantHealthCheck, true)
+}
+
+func newBalancer(sticky *dynamic.Sticky, wantHealthCheck, powerOfTwo bool) *Balancer {
+	balancer := &Balancer{
+		status:           make(map[string]struct{}),
+		wantsHealthCheck: wantHealthCheck,
+		powerOfTwo:       powerOfTwo,
+		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if sticky != nil && sticky.Cookie != nil {
+		balancer.stickyCookie = &stickyCookie{
+			name:     sticky.Cookie.Name,
+			secure:   sticky.Cookie.Secure,
+			httpOnly: sticky.Cookie.HTTPOnly,
+		}
+	}
+	return balancer
+}
+
+// SetStatus sets on the balancer that its given child is now of the given
+// status. balancerName is only needed for logging purposes.
+func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	status := "DOWN"
+	if up {
+		status = "UP"
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
+
+	if up {
+		b.status[childName] = struct{}{}
+	} else {
+		delete(b.status, childName)
+	}
+
+	b.updateHealthy()
+
+	upAfter := len(b.status) > 0
+	status = "DOWN"
+	if upAfter {
+		status = "UP"
+	}
+
+	// No Status Change
+	if upBefore == upAfter {
+		// We're still with the same status, no need to propagate
+		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", status)
+		return
+	}
+
+	// Status Change
+	log.Ctx(ctx).Debug().Msgf("Propagating new %s status", status)
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
+// updateHealthy rebuilds the list of healthy handlers.
+// It must be called with the mutex held.
+func (b *Balancer) updateHealthy() {
+	b.healthy = b.healthy[:0]
+	for _, handler := range b.handlers {
+		if _, ok := b.status[handler.name]; ok {
+			b.healthy = append(b.healthy, handler)
+		}
+	}
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the Balancer changes.
+// Not thread safe.
+func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) error {
+	if !b.wantsHealthCheck {
+		return fmt.Errorf("healthCheck not enabled in config for this %s service", b.strategyName())
+	}
+	b.updaters = append(b.updaters, fn)
+	return nil
+}
+
+var errNoAvailableServer = errors.New("no available server")
+
+func (b *Balancer) nextServer() (*namedHandler, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.healthy) == 0 {
+		return nil, errNoAvailableServer
+	}
+
+	var handler *namedHandler
+	if b.powerOfTwo {
+		handler = b.pickPowerOfTwo()
+	} else {
+		handler = b.pickLeastLoaded()
+	}
+
+	log.Debug().Msgf("Service selected by %s: %s", b.strategyName(), handler.name)
+	return handler, nil
+}
+
+// strategyName returns the name of the load-balancing strategy, for logging purposes.
+func (b *Balancer) strategyName() string {
+	if b.powerOfTwo {
+		return "power of two random choices"
+	}
+	return "least connections"
+}
+
+// pickLeastLoaded returns the healthy handler with the least outstanding requests relative to its weight.
+// It must be called with the mutex held.
+func (b *Balancer) pickLeastLoaded() *namedHandler {
+	var handler *namedHandler
+	var minLoad float64
+	for i := range b.healthy {
+		candidate := b.healthy[(b.next+i)%len(b.healthy)]
+		if load := candidate.load(); handler == nil || load < minLoad {
+			handler = candidate
+			minLoad = load
+		}
+	}
+
+	b.next = (b.next + 1) % len(b.healthy)
+
+	return handler
+}
+
+// pickPowerOfTwo returns the least loaded of two distinct healthy handlers picked at random.
+// It must be called with the mutex held.
+func (b *Balancer) pickPowerOfTwo() *namedHandler {
+	if len(b.healthy) == 1 {
+		return b.healthy[0]
+	}
+
+	i := b.rand.Intn(len(b.healthy))
+	j := b.rand.Intn(len(b.healthy) - 1)
+	if j >= i {
+		j++
+	}
+
+	if b.healthy[j].load() < b.healthy[i].load() {
+		return b.healthy[j]
+	}
+	return b.healthy[i]
+}
+
+func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if b.stickyCookie != nil {
+		cookie, err := req.Cookie(b.stickyCookie.name)
+
+		if err != nil && !errors.Is(err, http.ErrNoCookie) {
+			log.Warn().Err(err).Msg("Error while reading cookie")
+		}
+
+		if err == nil && cookie != nil {
+			if handler := b.stickyHandler(cookie.Value); handler != nil {
+				handler.ServeHTTP(w, req)
+				return
+			}
+		}
+	}
+
+	server, err := b.nextServer()
+	if err != nil {
+		if errors.Is(err, errNoAvailableServer) {
+			http.Error(w, errNoAvailableServer.Error(), http.StatusServiceUnavailable)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if b.stickyCookie != nil {
+		cookie := &http.Cookie{Name: b.stickyCookie.name, Value: server.name, Path: "/", HttpOnly: b.stickyCookie.httpOnly, Secure: b.stickyCookie.secure}
+		http.SetCookie(w, cookie)
+	}
+
+	server.ServeHTTP(w, req)
+}
+
+// stickyHandler returns the healthy handler with the given name, if any.
+func (b *Balancer) stickyHandler(name string) *namedHandler {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, handler := range b.healthy {
+		if handler.name == name {
+			return handler
+		}
+	}
+
+	return nil
+}
+
+// Add adds a handler.
+// A handler with a non-positive weight is ignored.
+func (b *Balancer) Add(name string, handler http.Handler, weight *int) {
+	w := 1
+	if weight != nil {
+		w = *weight
+	}
+
+	if w <= 0 { // non-positive weight is meaningless
+		return
+	}
+
+	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
+
+	b.mutex.Lock()
+	b.handlers = append(b.handlers, h)
+	b.status[name] = struct{}{}
+	b.updateHealthy()
+	b.mutex.Unlock()
+}
@@ -0,0 +1,272 @@
+package leastconn
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"traefik/v3/pkg/config/dynamic"
+)
+
+func TestBalancer(t *testing.T) {
+	balancer := New(nil, false)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 4; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 2, recorder.save["first"])
+	assert.Equal(t, 2, recorder.save["second"])
+}
+
+func TestBalancerLeastOutstandingRequests(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		balancer *Balancer
+	}{
+		{
+			desc:     "least connections",
+			balancer: New(nil, false),
+		},
+		{
+			desc:     "power of two choices",
+			balancer: NewPowerOfTwoChoices(nil, false),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			test.balancer.Add("slow", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				started <- struct{}{}
+				<-release
+				rw.Header().Set("server", "slow")
+				rw.WriteHeader(http.StatusOK)
+			}), Int(1))
+
+			test.balancer.Add("fast", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("server", "fast")
+				rw.WriteHeader(http.StatusOK)
+			}), Int(1))
+
+			// Sends requests until one of them is stuck on the slow server.
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					recorder := httptest.NewRecorder()
+					test.balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+					if recorder.Header().Get("server") == "slow" {
+						return
+					}
+				}
+			}()
+			<-started
+
+			recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+			for i := 0; i < 10; i++ {
+				test.balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, 10, recorder.save["fast"])
+		})
+	}
+}
+
+func TestBalancerWeight(t *testing.T) {
+	balancer := New(nil, false)
+
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			<-release
+			rw.Header().Set("server", name)
+			rw.WriteHeader(http.StatusOK)
+		})
+	}
+	balancer.Add("first", handler("first"), Int(3))
+	balancer.Add("second", handler("second"), Int(1))
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	var mu sync.Mutex
+	for i := 0; i < 8; i++ {
+		server, err := balancer.nextServer()
+		require.NoError(t, err)
+
+		server.inflight.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer server.inflight.Add(-1)
+
+			rw := httptest.NewRecorder()
+			server.Handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			mu.Lock()
+			recorder.save[rw.Header().Get("server")]++
+			mu.Unlock()
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 6, recorder.save["first"])
+	assert.Equal(t, 2, recorder.save["second"])
+}
+
+func TestBalancerNoService(t *testing.T) {
+	balancer := New(nil, false)
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Result().StatusCode)
+}
+
+func TestBalancerAllServersZeroWeight(t *testing.T) {
+	balancer := NewPowerOfTwoChoices(nil, false)
+
+	balancer.Add("test", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(0))
+	balancer.Add("test2", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(0))
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Result().StatusCode)
+}
+
+func TestBalancerDownThenUp(t *testing.T) {
+	balancer := NewPowerOfTwoChoices(nil, false)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+	balancer.SetStatus(context.Background(), "second", false)
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["first"])
+
+	balancer.SetStatus(context.Background(), "first", false)
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, []int{http.StatusServiceUnavailable}, recorder.status)
+
+	balancer.SetStatus(context.Background(), "second", true)
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["second"])
+}
+
+func TestBalancerPropagate(t *testing.T) {
+	balancer := New(nil, true)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+
+	var statuses []bool
+	err := balancer.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	})
+	require.NoError(t, err)
+
+	balancer.SetStatus(context.Background(), "first", false)
+	balancer.SetStatus(context.Background(), "second", false)
+	balancer.SetStatus(context.Background(), "second", true)
+
+	assert.Equal(t, []bool{false, true}, statuses)
+
+	err = New(nil, false).RegisterStatusUpdater(func(up bool) {})
+	assert.Error(t, err)
+}
+
+func TestSticky(t *testing.T) {
+	balancer := New(&dynamic.Sticky{
+		Cookie: &dynamic.Cookie{Name: "test"},
+	}, false)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for i := 0; i < 3; i++ {
+		for _, cookie := range recorder.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		recorder.ResponseRecorder = httptest.NewRecorder()
+
+		balancer.ServeHTTP(recorder, req)
+	}
+
+	assert.Equal(t, 3, recorder.save["first"])
+	assert.Equal(t, 0, recorder.save["second"])
+
+	// The sticky server is down, the request is forwarded to a new server.
+	balancer.SetStatus(context.Background(), "first", false)
+
+	recorder.ResponseRecorder = httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, req)
+
+	assert.Equal(t, 1, recorder.save["second"])
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "second", cookies[0].Value)
+}
+
+func Int(v int) *int { return &v }
+
+type responseRecorder struct {
+	*httptest.ResponseRecorder
+	save   map[string]int
+	status []int
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.save[r.Header().Get("server")]++
+	r.status = append(r.status, statusCode)
+	r.ResponseRecorder.WriteHeader(statusCode)
+}
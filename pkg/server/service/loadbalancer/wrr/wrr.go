@@ -4,8 +4,10 @@ import (
 	"container/heap"
 	"context"
 	"errors"
+	"math"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"traefik/v3/pkg/config/dynamic"
@@ -16,6 +18,9 @@ type namedHandler struct {
 	name     string
 	weight   float64
 	deadline float64
+	// upSince is the time at which the handler was added, or was last set up,
+	// used to compute its effective weight during the slow start window.
+	upSince time.Time
 }
 
 type stickyCookie struct {
@@ -31,7 +36,12 @@ type stickyCookie struct {
 // providing weighted round-robin behavior with floating point weights and an O(log n) pick time.
 type Balancer struct {
 	stickyCookie     *stickyCookie
+	slowStart        *slowStart
 	wantsHealthCheck bool
+	// upTimes keeps the up times of the handlers across the balancers rebuilt on configuration reloads,
+	// under the upTimesPrefix keys.
+	upTimes       *UpTimes
+	upTimesPrefix string
 
 	mutex       sync.RWMutex
 	handlers    []*namedHandler
@@ -47,7 +57,7 @@ type Balancer struct {
 }
 
 // New creates a new load balancer.
-func New(sticky *dynamic.Sticky, wantHealthCheck bool) *Balancer {
+func New(sticky *dynamic.Sticky, slowStartConfig *dynamic.SlowStart, wantHealthCheck bool) *Balancer {
 	balancer := &Balancer{
 		status:           make(map[string]struct{}),
 		wantsHealthCheck: wantHealthCheck,
@@ -59,9 +69,20 @@ func New(sticky *dynamic.Sticky, wantHealthCheck bool) *Balancer {
 			httpOnly: sticky.Cookie.HTTPOnly,
 		}
 	}
+	if slowStartConfig != nil && slowStartConfig.Duration > 0 {
+		balancer.slowStart = newSlowStart(slowStartConfig)
+	}
 	return balancer
 }
 
+// KeepUpTimes makes the balancer keep the up times of its handlers in the given UpTimes, under the given service name,
+// so that the handlers already up in a previous balancer of the service do not restart their slow start window.
+// It must be called before adding the handlers.
+func (b *Balancer) KeepUpTimes(upTimes *UpTimes, serviceName string) {
+	b.upTimes = upTimes
+	b.upTimesPrefix = serviceName
+}
+
 // Len implements heap.Interface/sort.Interface.
 func (b *Balancer) Len() int { return len(b.handlers) }
 
@@ -108,6 +129,27 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 
 	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
 
+	if _, wasUp := b.status[childName]; up != wasUp {
+		// The recovered child restarts its slow start window.
+		now := time.Now()
+		if up {
+			for _, handler := range b.handlers {
+				if handler.name == childName {
+					handler.upSince = now
+				}
+			}
+		}
+
+		if b.upTimes != nil {
+			key := UpTimeKey(b.upTimesPrefix, childName)
+			if up {
+				b.upTimes.set(key, now)
+			} else {
+				b.upTimes.delete(key)
+			}
+		}
+	}
+
 	if up {
 		b.status[childName] = struct{}{}
 	} else {
@@ -162,7 +204,7 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 
 		// curDeadline should be handler's deadline so that new added entry would have a fair competition environment with the old ones.
 		b.curDeadline = handler.deadline
-		handler.deadline += 1 / handler.weight
+		handler.deadline += 1 / b.effectiveWeight(handler)
 
 		heap.Push(b, handler)
 		if _, ok := b.status[handler.name]; ok {
@@ -233,11 +275,122 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int) {
 		return
 	}
 
-	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
+	upSince := time.Now()
+	if b.upTimes != nil {
+		upSince = b.upTimes.loadOrStore(UpTimeKey(b.upTimesPrefix, name), upSince)
+	}
+
+	h := &namedHandler{Handler: handler, name: name, weight: float64(w), upSince: upSince}
 
 	b.mutex.Lock()
-	h.deadline = b.curDeadline + 1/h.weight
+	h.deadline = b.curDeadline + 1/b.effectiveWeight(h)
 	heap.Push(b, h)
 	b.status[name] = struct{}{}
 	b.mutex.Unlock()
 }
+
+// effectiveWeight returns the weight of the handler, reduced while it is in its slow start window.
+func (b *Balancer) effectiveWeight(handler *namedHandler) float64 {
+	if b.slowStart == nil {
+		return handler.weight
+	}
+
+	return handler.weight * b.slowStart.factor(time.Since(handler.upSince))
+}
+
+// slowStart computes the weight factor of handlers during their slow start window.
+type slowStart struct {
+	duration   time.Duration
+	aggression float64
+	minFactor  float64
+}
+
+func newSlowStart(config *dynamic.SlowStart) *slowStart {
+	aggression := config.Aggression
+	if aggression <= 0 {
+		aggression = dynamic.DefaultSlowStartAggression
+	}
+
+	minFactor := float64(config.MinWeightPercent) / 100
+	if minFactor <= 0 || minFactor > 1 {
+		minFactor = float64(dynamic.DefaultSlowStartMinWeightPercent) / 100
+	}
+
+	return &slowStart{
+		duration:   time.Duration(config.Duration),
+		aggression: aggression,
+		minFactor:  minFactor,
+	}
+}
+
+// factor returns the factor to apply to the weight of a handler which has been up for the given duration.
+// It grows from minFactor to 1 over the slow start window, following the curve defined by the aggression.
+func (s *slowStart) factor(elapsed time.Duration) float64 {
+	if elapsed >= s.duration {
+		return 1
+	}
+
+	if elapsed <= 0 {
+		return s.minFactor
+	}
+
+	factor := math.Pow(float64(elapsed)/float64(s.duration), 1/s.aggression)
+
+	return math.Max(factor, s.minFactor)
+}
+
+// UpTimes records the time at which the handlers of the balancers were last set up,
+// so that their slow start window is kept across the configuration reloads.
+type UpTimes struct {
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+// NewUpTimes creates a new UpTimes.
+func NewUpTimes() *UpTimes {
+	return &UpTimes{times: make(map[string]time.Time)}
+}
+
+// UpTimeKey returns the key of the up time of the given handler of the given service.
+func UpTimeKey(serviceName, handlerName string) string {
+	return serviceName + "/" + handlerName
+}
+
+// Retain removes the up times whose key is not in the given keys,
+// so that a handler removed from the configuration is considered as new when it is added back.
+func (u *UpTimes) Retain(keys map[string]struct{}) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for key := range u.times {
+		if _, ok := keys[key]; !ok {
+			delete(u.times, key)
+		}
+	}
+}
+
+func (u *UpTimes) loadOrStore(key string, upSince time.Time) time.Time {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if existing, ok := u.times[key]; ok {
+		return existing
+	}
+
+	u.times[key] = upSince
+	return upSince
+}
+
+func (u *UpTimes) set(key string, upSince time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.times[key] = upSince
+}
+
+func (u *UpTimes) delete(key string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.times, key)
+}
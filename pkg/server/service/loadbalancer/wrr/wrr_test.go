@@ -5,13 +5,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	ptypes "github.com/traefik/paerser/types"
 	"traefik/v3/pkg/config/dynamic"
 )
 
 func TestBalancer(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -33,7 +35,7 @@ func TestBalancer(t *testing.T) {
 }
 
 func TestBalancerNoService(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	recorder := httptest.NewRecorder()
 	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
@@ -42,7 +44,7 @@ func TestBalancerNoService(t *testing.T) {
 }
 
 func TestBalancerOneServerZeroWeight(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -64,7 +66,7 @@ type key string
 const serviceName key = "serviceName"
 
 func TestBalancerNoServiceUp(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -84,7 +86,7 @@ func TestBalancerNoServiceUp(t *testing.T) {
 }
 
 func TestBalancerOneServerDown(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -105,7 +107,7 @@ func TestBalancerOneServerDown(t *testing.T) {
 }
 
 func TestBalancerDownThenUp(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -134,7 +136,7 @@ func TestBalancerDownThenUp(t *testing.T) {
 }
 
 func TestBalancerPropagate(t *testing.T) {
-	balancer1 := New(nil, true)
+	balancer1 := New(nil, nil, true)
 
 	balancer1.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -145,7 +147,7 @@ func TestBalancerPropagate(t *testing.T) {
 		rw.WriteHeader(http.StatusOK)
 	}), Int(1))
 
-	balancer2 := New(nil, true)
+	balancer2 := New(nil, nil, true)
 	balancer2.Add("third", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "third")
 		rw.WriteHeader(http.StatusOK)
@@ -155,7 +157,7 @@ func TestBalancerPropagate(t *testing.T) {
 		rw.WriteHeader(http.StatusOK)
 	}), Int(1))
 
-	topBalancer := New(nil, true)
+	topBalancer := New(nil, nil, true)
 	topBalancer.Add("balancer1", balancer1, Int(1))
 	_ = balancer1.RegisterStatusUpdater(func(up bool) {
 		topBalancer.SetStatus(context.WithValue(context.Background(), serviceName, "top"), "balancer1", up)
@@ -207,7 +209,7 @@ func TestBalancerPropagate(t *testing.T) {
 }
 
 func TestBalancerAllServersZeroWeight(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	balancer.Add("test", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(0))
 	balancer.Add("test2", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(0))
@@ -221,7 +223,7 @@ func TestBalancerAllServersZeroWeight(t *testing.T) {
 func TestSticky(t *testing.T) {
 	balancer := New(&dynamic.Sticky{
 		Cookie: &dynamic.Cookie{Name: "test"},
-	}, false)
+	}, nil, false)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -252,7 +254,7 @@ func TestSticky(t *testing.T) {
 // TestBalancerBias makes sure that the WRR algorithm spreads elements evenly right from the start,
 // and that it does not "over-favor" the high-weighted ones with a biased start-up regime.
 func TestBalancerBias(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, nil, false)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "A")
@@ -275,6 +277,201 @@ func TestBalancerBias(t *testing.T) {
 	assert.Equal(t, wantSequence, recorder.sequence)
 }
 
+func TestBalancerSlowStart(t *testing.T) {
+	balancer := New(nil, &dynamic.SlowStart{Duration: ptypes.Duration(time.Hour), Aggression: 1, MinWeightPercent: 10}, true)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	// The first server is out of its slow start window, while the second one has just been added.
+	for _, handler := range balancer.handlers {
+		if handler.name == "first" {
+			handler.upSince = time.Now().Add(-2 * time.Hour)
+		}
+	}
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 110; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.InDelta(t, 100, recorder.save["first"], 2)
+	assert.InDelta(t, 10, recorder.save["second"], 2)
+
+	// Once the second server is done with its slow start, it receives its full share.
+	for _, handler := range balancer.handlers {
+		handler.upSince = time.Now().Add(-2 * time.Hour)
+	}
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 100; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.InDelta(t, 50, recorder.save["first"], 2)
+	assert.InDelta(t, 50, recorder.save["second"], 2)
+}
+
+func TestBalancerSlowStartAfterRecovery(t *testing.T) {
+	balancer := New(nil, &dynamic.SlowStart{Duration: ptypes.Duration(time.Hour)}, true)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+
+	longAgo := time.Now().Add(-2 * time.Hour)
+	for _, handler := range balancer.handlers {
+		handler.upSince = longAgo
+	}
+
+	// Setting a server up while it is already up does not restart its slow start window.
+	balancer.SetStatus(context.Background(), "first", true)
+
+	balancer.SetStatus(context.Background(), "second", false)
+	balancer.SetStatus(context.Background(), "second", true)
+
+	for _, handler := range balancer.handlers {
+		if handler.name == "first" {
+			assert.Equal(t, longAgo, handler.upSince)
+			continue
+		}
+		assert.True(t, handler.upSince.After(longAgo))
+	}
+}
+
+func TestBalancerSlowStartAcrossReloads(t *testing.T) {
+	upTimes := NewUpTimes()
+	slowStartConfig := &dynamic.SlowStart{Duration: ptypes.Duration(time.Hour), Aggression: 1, MinWeightPercent: 10}
+
+	balancer := New(nil, slowStartConfig, true)
+	balancer.KeepUpTimes(upTimes, "test")
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), Int(1))
+
+	// Both servers have been up for a long time.
+	longAgo := time.Now().Add(-2 * time.Hour)
+	upTimes.set(UpTimeKey("test", "first"), longAgo)
+	upTimes.set(UpTimeKey("test", "second"), longAgo)
+
+	// The second server goes down before the reload, and the third one is new.
+	balancer.SetStatus(context.Background(), "second", false)
+
+	// The reload removes the servers which are not in the new configuration.
+	upTimes.Retain(map[string]struct{}{
+		UpTimeKey("test", "first"):  {},
+		UpTimeKey("test", "second"): {},
+		UpTimeKey("test", "third"):  {},
+	})
+
+	reloaded := New(nil, slowStartConfig, true)
+	reloaded.KeepUpTimes(upTimes, "test")
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		reloaded.Add(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", name)
+			rw.WriteHeader(http.StatusOK)
+		}), Int(1))
+	}
+
+	for _, handler := range reloaded.handlers {
+		if handler.name == "first" {
+			assert.Equal(t, longAgo, handler.upSince)
+			continue
+		}
+		assert.True(t, handler.upSince.After(longAgo))
+	}
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 120; i++ {
+		reloaded.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	// The first server keeps its full share, while the others restart their slow start window.
+	assert.InDelta(t, 100, recorder.save["first"], 2)
+	assert.InDelta(t, 10, recorder.save["second"], 2)
+	assert.InDelta(t, 10, recorder.save["third"], 2)
+}
+
+func TestUpTimes_Retain(t *testing.T) {
+	upTimes := NewUpTimes()
+
+	longAgo := time.Now().Add(-2 * time.Hour)
+	upTimes.set(UpTimeKey("test", "kept"), longAgo)
+	upTimes.set(UpTimeKey("test", "removed"), longAgo)
+
+	upTimes.Retain(map[string]struct{}{UpTimeKey("test", "kept"): {}})
+
+	assert.Equal(t, longAgo, upTimes.loadOrStore(UpTimeKey("test", "kept"), time.Now()))
+	assert.NotEqual(t, longAgo, upTimes.loadOrStore(UpTimeKey("test", "removed"), time.Now()))
+}
+
+func TestSlowStart_factor(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *dynamic.SlowStart
+		elapsed  time.Duration
+		expected float64
+	}{
+		{
+			desc:     "beginning of the window",
+			config:   &dynamic.SlowStart{Duration: ptypes.Duration(10 * time.Second), Aggression: 1, MinWeightPercent: 10},
+			elapsed:  0,
+			expected: 0.1,
+		},
+		{
+			desc:     "linear ramp",
+			config:   &dynamic.SlowStart{Duration: ptypes.Duration(10 * time.Second), Aggression: 1, MinWeightPercent: 10},
+			elapsed:  5 * time.Second,
+			expected: 0.5,
+		},
+		{
+			desc:     "aggressive ramp",
+			config:   &dynamic.SlowStart{Duration: ptypes.Duration(10 * time.Second), Aggression: 2, MinWeightPercent: 10},
+			elapsed:  2500 * time.Millisecond,
+			expected: 0.5,
+		},
+		{
+			desc:     "conservative ramp",
+			config:   &dynamic.SlowStart{Duration: ptypes.Duration(10 * time.Second), Aggression: 0.5, MinWeightPercent: 10},
+			elapsed:  5 * time.Second,
+			expected: 0.25,
+		},
+		{
+			desc:     "min weight percent",
+			config:   &dynamic.SlowStart{Duration: ptypes.Duration(10 * time.Second), Aggression: 1, MinWeightPercent: 30},
+			elapsed:  2 * time.Second,
+			expected: 0.3,
+		},
+		{
+			desc:     "default values",
+			config:   &dynamic.SlowStart{Duration: ptypes.Duration(10 * time.Second)},
+			elapsed:  time.Second,
+			expected: 0.1,
+		},
+		{
+			desc:     "end of the window",
+			config:   &dynamic.SlowStart{Duration: ptypes.Duration(10 * time.Second), Aggression: 1, MinWeightPercent: 10},
+			elapsed:  time.Minute,
+			expected: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.InDelta(t, test.expected, newSlowStart(test.config).factor(test.elapsed), 0.0001)
+		})
+	}
+}
+
 func Int(v int) *int { return &v }
 
 type responseRecorder struct {
@@ -10,6 +10,7 @@ import (
 	"traefik/v3/pkg/config/static"
 	"traefik/v3/pkg/metrics"
 	"traefik/v3/pkg/safe"
+	"traefik/v3/pkg/server/service/loadbalancer/wrr"
 )
 
 // ManagerFactory a factory of service manager.
@@ -26,6 +27,9 @@ type ManagerFactory struct {
 	acmeHTTPHandler  http.Handler
 
 	routinesPool *safe.Pool
+
+	// upTimes keeps the slow start windows of the servers across the service managers built on configuration reloads.
+	upTimes *wrr.UpTimes
 }
 
 // NewManagerFactory creates a new ManagerFactory.
@@ -35,6 +39,7 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 		routinesPool:        routinesPool,
 		roundTripperManager: roundTripperManager,
 		acmeHTTPHandler:     acmeHTTPHandler,
+		upTimes:             wrr.NewUpTimes(),
 	}
 
 	if staticConfiguration.API != nil {
@@ -74,6 +79,11 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 func (f *ManagerFactory) Build(configuration *runtime.Configuration) *InternalHandlers {
 	svcManager := NewManager(configuration.Services, f.metricsRegistry, f.routinesPool, f.roundTripperManager)
 
+	if f.upTimes != nil {
+		f.upTimes.Retain(upTimeKeys(configuration.Services))
+		svcManager.upTimes = f.upTimes
+	}
+
 	var apiHandler http.Handler
 	if f.api != nil {
 		apiHandler = f.api(configuration)
@@ -81,3 +91,19 @@ func (f *ManagerFactory) Build(configuration *runtime.Configuration) *InternalHa
 
 	return NewInternalHandlers(svcManager, apiHandler, f.restHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, f.acmeHTTPHandler)
 }
+
+// upTimeKeys returns the up time keys of the servers of the given services.
+func upTimeKeys(services map[string]*runtime.ServiceInfo) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for serviceName, info := range services {
+		if info.Service == nil || info.LoadBalancer == nil {
+			continue
+		}
+
+		for _, server := range info.LoadBalancer.Servers {
+			keys[wrr.UpTimeKey(serviceName, getProxyName(server.URL))] = struct{}{}
+		}
+	}
+
+	return keys
+}
@@ -24,7 +24,10 @@ import (
 	"traefik/v3/pkg/safe"
 	"traefik/v3/pkg/server/cookie"
 	"traefik/v3/pkg/server/provider"
+	"traefik/v3/pkg/server/service/loadbalancer/coalescing"
 	"traefik/v3/pkg/server/service/loadbalancer/failover"
+	"traefik/v3/pkg/server/service/loadbalancer/hash"
+	"traefik/v3/pkg/server/service/loadbalancer/leastconn"
 	"traefik/v3/pkg/server/service/loadbalancer/mirror"
 	"traefik/v3/pkg/server/service/loadbalancer/wrr"
 )
@@ -36,6 +39,15 @@ type RoundTripperGetter interface {
 	Get(name string) (http.RoundTripper, error)
 }
 
+// serversBalancer is a load balancer of servers.
+type serversBalancer interface {
+	http.Handler
+	healthcheck.StatusSetter
+	healthcheck.StatusUpdater
+
+	Add(name string, handler http.Handler, weight *int)
+}
+
 // Manager The service manager.
 type Manager struct {
 	routinePool         *safe.Pool
@@ -47,6 +59,8 @@ type Manager struct {
 	configs        map[string]*runtime.ServiceInfo
 	healthCheckers map[string]*healthcheck.ServiceHealthChecker
 	rand           *rand.Rand // For the initial shuffling of load-balancers.
+	// upTimes keeps the slow start windows of the servers across the configuration reloads.
+	upTimes *wrr.UpTimes
 }
 
 // NewManager creates a new Manager.
@@ -60,6 +74,7 @@ func NewManager(configs map[string]*runtime.ServiceInfo, metricsRegistry metrics
 		configs:             configs,
 		healthCheckers:      make(map[string]*healthcheck.ServiceHealthChecker),
 		rand:                rand.New(rand.NewSource(time.Now().UnixNano())),
+		upTimes:             wrr.NewUpTimes(),
 	}
 }
 
@@ -217,7 +232,7 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 		config.Sticky.Cookie.Name = cookie.GetName(config.Sticky.Cookie.Name, serviceName)
 	}
 
-	balancer := wrr.New(config.Sticky, config.HealthCheck != nil)
+	balancer := wrr.New(config.Sticky, nil, config.HealthCheck != nil)
 	for _, service := range shuffle(config.Services, m.rand) {
 		serviceHandler, err := m.BuildHTTP(ctx, service.Name)
 		if err != nil {
@@ -280,14 +295,31 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		return nil, err
 	}
 
-	lb := wrr.New(service.Sticky, service.HealthCheck != nil)
+	lb, err := newServersBalancer(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	if balancer, ok := lb.(*wrr.Balancer); ok && m.upTimes != nil {
+		balancer.KeepUpTimes(m.upTimes, serviceName)
+	}
+
 	healthCheckTargets := make(map[string]*url.URL)
 
-	for _, server := range shuffle(service.Servers, m.rand) {
-		hasher := fnv.New64a()
-		_, _ = hasher.Write([]byte(server.URL)) // this will never return an error.
+	var passiveHealthChecker *healthcheck.PassiveHealthChecker
+	if service.PassiveHealthCheck != nil {
+		passiveHealthChecker = healthcheck.NewPassiveHealthChecker(
+			ctx,
+			m.metricsRegistry,
+			service.PassiveHealthCheck,
+			lb,
+			info,
+			serviceName,
+		)
+	}
 
-		proxyName := fmt.Sprintf("%x", hasher.Sum(nil))
+	for _, server := range shuffle(service.Servers, m.rand) {
+		proxyName := getProxyName(server.URL)
 
 		target, err := url.Parse(server.URL)
 		if err != nil {
@@ -307,6 +339,10 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 			proxy = metricsMiddle.NewServiceMiddleware(ctx, proxy, m.metricsRegistry, serviceName)
 		}
 
+		if passiveHealthChecker != nil {
+			proxy = passiveHealthChecker.WrapServer(proxyName, target.String(), proxy)
+		}
+
 		lb.Add(proxyName, proxy, nil)
 
 		// servers are considered UP by default.
@@ -316,20 +352,71 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 	}
 
 	if service.HealthCheck != nil {
+		// With a passive health check, a server is only up when both health checks agree.
+		var statusSetter healthcheck.StatusSetter = lb
+		if passiveHealthChecker != nil {
+			statusSetter = passiveHealthChecker
+		}
+
 		m.healthCheckers[serviceName] = healthcheck.NewServiceHealthChecker(
 			ctx,
 			m.metricsRegistry,
 			service.HealthCheck,
-			lb,
+			statusSetter,
 			info,
 			roundTripper,
 			healthCheckTargets,
 		)
 	}
 
+	if service.Coalescing != nil {
+		return coalescing.New(lb, service.Coalescing), nil
+	}
+
 	return lb, nil
 }
 
+// newServersBalancer creates the load balancer matching the strategy of the given service.
+func newServersBalancer(ctx context.Context, service *dynamic.ServersLoadBalancer) (serversBalancer, error) {
+	wantsHealthCheck := service.HealthCheck != nil || service.PassiveHealthCheck != nil
+
+	if service.SlowStart != nil && service.Strategy != "" && service.Strategy != dynamic.BalancerStrategyWRR {
+		return nil, fmt.Errorf("slow start is not supported by the %q load-balancing strategy, only by %q", service.Strategy, dynamic.BalancerStrategyWRR)
+	}
+
+	switch service.Strategy {
+	case "", dynamic.BalancerStrategyWRR:
+		slowStart := service.SlowStart
+		if slowStart == nil && service.PassiveHealthCheck != nil {
+			// The servers restored after a passive health check ejection are brought back progressively.
+			slowStart = &dynamic.SlowStart{}
+			slowStart.SetDefaults()
+		}
+
+		return wrr.New(service.Sticky, slowStart, wantsHealthCheck), nil
+	case dynamic.BalancerStrategyLeastConn:
+		return leastconn.New(service.Sticky, wantsHealthCheck), nil
+	case dynamic.BalancerStrategyP2C:
+		return leastconn.NewPowerOfTwoChoices(service.Sticky, wantsHealthCheck), nil
+	case dynamic.BalancerStrategyHash:
+		balancer, err := hash.New(ctx, service.Sticky, service.Hash, wantsHealthCheck)
+		if err != nil {
+			return nil, err
+		}
+		return balancer, nil
+	default:
+		return nil, fmt.Errorf("unknown load-balancing strategy %q", service.Strategy)
+	}
+}
+
+// getProxyName returns the name of the handler of the server with the given URL.
+func getProxyName(serverURL string) string {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(serverURL)) // this will never return an error.
+
+	return fmt.Sprintf("%x", hasher.Sum(nil))
+}
+
 // LaunchHealthCheck launches the health checks.
 func (m *Manager) LaunchHealthCheck(ctx context.Context) {
 	for serviceName, hc := range m.healthCheckers {
@@ -9,12 +9,15 @@ import (
 	"net/textproto"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"traefik/v3/pkg/config/dynamic"
 	"traefik/v3/pkg/config/runtime"
 	"traefik/v3/pkg/server/provider"
+	"traefik/v3/pkg/server/service/loadbalancer/wrr"
 	"traefik/v3/pkg/testhelpers"
 )
 
@@ -59,6 +62,67 @@ func TestGetLoadBalancer(t *testing.T) {
 			fwd:         &MockForwarder{},
 			expectError: false,
 		},
+		{
+			desc:        "Succeeds with the least connections strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: dynamic.BalancerStrategyLeastConn,
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Succeeds with the power of two choices strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: dynamic.BalancerStrategyP2C,
+				Sticky:   &dynamic.Sticky{Cookie: &dynamic.Cookie{}},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Succeeds with the hash strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: dynamic.BalancerStrategyHash,
+				Hash:     &dynamic.HashPolicy{RequestCookieName: "tenant"},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Fails with an invalid hash configuration",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: dynamic.BalancerStrategyHash,
+				Hash: &dynamic.HashPolicy{
+					RequestCookieName:         "tenant",
+					RequestQueryParameterName: "tenant",
+				},
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
+		{
+			desc:        "Fails with slow start and a strategy other than wrr",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy:  dynamic.BalancerStrategyP2C,
+				SlowStart: &dynamic.SlowStart{Duration: ptypes.Duration(time.Minute)},
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
+		{
+			desc:        "Fails with an unknown strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: "foo",
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -454,6 +518,103 @@ func Test1xxResponses(t *testing.T) {
 	}
 }
 
+func TestGetLoadBalancerServiceHandler_passiveHealthCheck(t *testing.T) {
+	sm := NewManager(nil, nil, nil, &RoundTripperManager{
+		roundTrippers: map[string]http.RoundTripper{
+			"default@internal": http.DefaultTransport,
+		},
+	})
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(backend.Close)
+
+	info := &runtime.ServiceInfo{
+		Service: &dynamic.Service{
+			LoadBalancer: &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{
+					{
+						URL: backend.URL,
+					},
+				},
+				PassiveHealthCheck: &dynamic.PassiveServerHealthCheck{
+					FailureThreshold: 2,
+					BaseEjectionTime: ptypes.Duration(time.Minute),
+					MaxEjectionTime:  ptypes.Duration(time.Minute),
+				},
+			},
+		},
+	}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "foobar", info)
+	require.NoError(t, err)
+
+	for _, expected := range []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+
+		assert.Equal(t, expected, recorder.Code)
+	}
+
+	assert.Equal(t, map[string]string{backend.URL: runtime.StatusDown}, info.GetAllStatus())
+}
+
+func TestManagerFactory_slowStartAcrossReloads(t *testing.T) {
+	factory := &ManagerFactory{
+		roundTripperManager: &RoundTripperManager{
+			roundTrippers: map[string]http.RoundTripper{
+				"default@internal": http.DefaultTransport,
+			},
+		},
+		upTimes: wrr.NewUpTimes(),
+	}
+
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "first")
+	}))
+	t.Cleanup(server1.Close)
+
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "second")
+	}))
+	t.Cleanup(server2.Close)
+
+	newConfiguration := func(servers ...dynamic.Server) *runtime.Configuration {
+		return &runtime.Configuration{
+			Services: map[string]*runtime.ServiceInfo{
+				"test@file": {
+					Service: &dynamic.Service{
+						LoadBalancer: &dynamic.ServersLoadBalancer{
+							SlowStart: &dynamic.SlowStart{Duration: ptypes.Duration(500 * time.Millisecond), Aggression: 1, MinWeightPercent: 10},
+							Servers:   servers,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	_, err := factory.Build(newConfiguration(dynamic.Server{URL: server1.URL})).BuildHTTP(context.Background(), "test@file")
+	require.NoError(t, err)
+
+	// The first server is done with its slow start when the second one is added.
+	time.Sleep(600 * time.Millisecond)
+
+	handler, err := factory.Build(newConfiguration(dynamic.Server{URL: server1.URL}, dynamic.Server{URL: server2.URL})).BuildHTTP(context.Background(), "test@file")
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	for i := 0; i < 50; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		counts[recorder.Header().Get("X-From")]++
+	}
+
+	// Without the kept up time, both servers would restart their slow start window together, and get the same share.
+	assert.Greater(t, counts["first"], 3*counts["second"])
+}
+
 func TestManager_Build(t *testing.T) {
 	testCases := []struct {
 		desc         string
@@ -10,24 +10,33 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/healthcheck"
 	"traefik/v3/pkg/logs"
+	"traefik/v3/pkg/metrics"
+	"traefik/v3/pkg/middlewares/accesslog"
 	"traefik/v3/pkg/server/provider"
 	"traefik/v3/pkg/tcp"
 )
 
 // Manager is the TCPHandlers factory.
 type Manager struct {
-	dialerManager *tcp.DialerManager
-	configs       map[string]*runtime.TCPServiceInfo
-	rand          *rand.Rand // For the initial shuffling of load-balancers.
+	dialerManager   *tcp.DialerManager
+	metricsRegistry metrics.Registry
+	configs         map[string]*runtime.TCPServiceInfo
+	services        map[string]tcp.Handler
+	healthCheckers  map[string]*healthcheck.ServiceTCPHealthChecker
+	rand            *rand.Rand // For the initial shuffling of load-balancers.
 }
 
 // NewManager creates a new manager.
-func NewManager(conf *runtime.Configuration, dialerManager *tcp.DialerManager) *Manager {
+func NewManager(conf *runtime.Configuration, dialerManager *tcp.DialerManager, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
-		dialerManager: dialerManager,
-		configs:       conf.TCPServices,
-		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		dialerManager:   dialerManager,
+		metricsRegistry: metricsRegistry,
+		configs:         conf.TCPServices,
+		services:        make(map[string]tcp.Handler),
+		healthCheckers:  make(map[string]*healthcheck.ServiceTCPHealthChecker),
+		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -38,6 +47,12 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 	logger := log.Ctx(rootCtx).With().Str(logs.ServiceName, serviceQualifiedName).Logger()
 	ctx := provider.AddInContext(rootCtx, serviceQualifiedName)
 
+	// The handler is shared by all the routers using the service,
+	// so that its servers status is updated by a single health checker.
+	if handler, ok := m.services[serviceQualifiedName]; ok {
+		return handler, nil
+	}
+
 	conf, ok := m.configs[serviceQualifiedName]
 	if !ok {
 		return nil, fmt.Errorf("the service %q does not exist", serviceQualifiedName)
@@ -51,7 +66,8 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := tcp.NewWRRLoadBalancer()
+		loadBalancer := tcp.NewWRRLoadBalancer(conf.LoadBalancer.HealthCheck != nil)
+		healthCheckTargets := make(map[string]*healthcheck.TCPHealthCheckTarget)
 
 		if len(conf.LoadBalancer.ServersTransport) > 0 {
 			conf.LoadBalancer.ServersTransport = provider.GetQualifiedName(ctx, conf.LoadBalancer.ServersTransport)
@@ -78,14 +94,47 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 				continue
 			}
 
-			loadBalancer.AddServer(handler)
+			loadBalancer.Add(server.Address, accesslog.NewTCPProxyHandler(handler, serviceQualifiedName, server.Address), nil)
 			logger.Debug().Msg("Creating TCP server")
+
+			// servers are considered UP by default.
+			conf.UpdateServerStatus(server.Address, runtime.StatusUp)
+
+			if conf.LoadBalancer.HealthCheck == nil {
+				continue
+			}
+
+			if conf.LoadBalancer.HealthCheck.TLS && !server.TLS {
+				dialer, err = m.dialerManager.Get(conf.LoadBalancer.ServersTransport, true)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			healthCheckTargets[server.Address] = &healthcheck.TCPHealthCheckTarget{
+				Address: server.Address,
+				Dialer:  dialer,
+			}
 		}
 
+		if conf.LoadBalancer.HealthCheck != nil {
+			m.healthCheckers[serviceQualifiedName] = healthcheck.NewServiceTCPHealthChecker(
+				ctx,
+				m.metricsRegistry,
+				conf.LoadBalancer.HealthCheck,
+				loadBalancer,
+				conf,
+				serviceQualifiedName,
+				healthCheckTargets,
+			)
+		}
+
+		m.services[serviceQualifiedName] = loadBalancer
+
 		return loadBalancer, nil
 
 	case conf.Weighted != nil:
-		loadBalancer := tcp.NewWRRLoadBalancer()
+		loadBalancer := tcp.NewWRRLoadBalancer(conf.Weighted.HealthCheck != nil)
 
 		for _, service := range shuffle(conf.Weighted.Services, m.rand) {
 			handler, err := m.BuildTCP(ctx, service.Name)
@@ -94,9 +143,30 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 				return nil, err
 			}
 
-			loadBalancer.AddWeightServer(handler, service.Weight)
+			loadBalancer.Add(service.Name, handler, service.Weight)
+
+			if conf.Weighted.HealthCheck == nil {
+				continue
+			}
+
+			childName := service.Name
+			updater, ok := handler.(healthcheck.StatusUpdater)
+			if !ok {
+				return nil, fmt.Errorf("child service %v of %v not a healthcheck.StatusUpdater (%T)", childName, serviceQualifiedName, handler)
+			}
+
+			if err := updater.RegisterStatusUpdater(func(up bool) {
+				loadBalancer.SetStatus(ctx, childName, up)
+			}); err != nil {
+				return nil, fmt.Errorf("cannot register %v as updater for %v: %w", childName, serviceQualifiedName, err)
+			}
+
+			logger.Debug().Str("parent", serviceQualifiedName).Str("child", childName).
+				Msg("Child service will update parent on status change")
 		}
 
+		m.services[serviceQualifiedName] = loadBalancer
+
 		return loadBalancer, nil
 
 	default:
@@ -106,6 +176,14 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 	}
 }
 
+// LaunchHealthCheck launches the health checks.
+func (m *Manager) LaunchHealthCheck(ctx context.Context) {
+	for serviceName, hc := range m.healthCheckers {
+		logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
+		go hc.Launch(logger.WithContext(ctx))
+	}
+}
+
 func shuffle[T any](values []T, r *rand.Rand) []T {
 	shuffled := make([]T, len(values))
 	copy(shuffled, values)
@@ -10,22 +10,30 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"traefik/v3/pkg/config/runtime"
+	"traefik/v3/pkg/healthcheck"
 	"traefik/v3/pkg/logs"
+	"traefik/v3/pkg/metrics"
 	"traefik/v3/pkg/server/provider"
 	"traefik/v3/pkg/udp"
 )
 
 // Manager handles UDP services creation.
 type Manager struct {
-	configs map[string]*runtime.UDPServiceInfo
-	rand    *rand.Rand // For the initial shuffling of load-balancers.
+	metricsRegistry metrics.Registry
+	configs         map[string]*runtime.UDPServiceInfo
+	services        map[string]udp.Handler
+	healthCheckers  map[string]*healthcheck.ServiceUDPHealthChecker
+	rand            *rand.Rand // For the initial shuffling of load-balancers.
 }
 
 // NewManager creates a new manager.
-func NewManager(conf *runtime.Configuration) *Manager {
+func NewManager(conf *runtime.Configuration, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
-		configs: conf.UDPServices,
-		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		metricsRegistry: metricsRegistry,
+		configs:         conf.UDPServices,
+		services:        make(map[string]udp.Handler),
+		healthCheckers:  make(map[string]*healthcheck.ServiceUDPHealthChecker),
+		rand:            rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -36,6 +44,12 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 	logger := log.Ctx(rootCtx).With().Str(logs.ServiceName, serviceQualifiedName).Logger()
 	ctx := provider.AddInContext(rootCtx, serviceQualifiedName)
 
+	// The handler is shared by all the routers using the service,
+	// so that its servers status is updated by a single health checker.
+	if handler, ok := m.services[serviceQualifiedName]; ok {
+		return handler, nil
+	}
+
 	conf, ok := m.configs[serviceQualifiedName]
 	if !ok {
 		return nil, fmt.Errorf("the UDP service %q does not exist", serviceQualifiedName)
@@ -49,7 +63,8 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := udp.NewWRRLoadBalancer()
+		loadBalancer := udp.NewWRRLoadBalancer(conf.LoadBalancer.HealthCheck != nil)
+		healthCheckTargets := make(map[string]string)
 
 		for index, server := range shuffle(conf.LoadBalancer.Servers, m.rand) {
 			srvLogger := logger.With().
@@ -67,14 +82,41 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 				continue
 			}
 
-			loadBalancer.AddServer(handler)
+			loadBalancer.Add(server.Address, handler, nil)
 			srvLogger.Debug().Msg("Creating UDP server")
+
+			// servers are considered UP by default.
+			conf.UpdateServerStatus(server.Address, runtime.StatusUp)
+
+			if conf.LoadBalancer.HealthCheck != nil {
+				healthCheckTargets[server.Address] = server.Address
+			}
+		}
+
+		if conf.LoadBalancer.HealthCheck != nil {
+			hc, err := healthcheck.NewServiceUDPHealthChecker(
+				ctx,
+				m.metricsRegistry,
+				conf.LoadBalancer.HealthCheck,
+				loadBalancer,
+				conf,
+				serviceQualifiedName,
+				healthCheckTargets,
+			)
+			if err != nil {
+				conf.AddError(err, true)
+				return nil, err
+			}
+
+			m.healthCheckers[serviceQualifiedName] = hc
 		}
 
+		m.services[serviceQualifiedName] = loadBalancer
+
 		return loadBalancer, nil
 
 	case conf.Weighted != nil:
-		loadBalancer := udp.NewWRRLoadBalancer()
+		loadBalancer := udp.NewWRRLoadBalancer(conf.Weighted.HealthCheck != nil)
 
 		for _, service := range shuffle(conf.Weighted.Services, m.rand) {
 			handler, err := m.BuildUDP(ctx, service.Name)
@@ -83,9 +125,30 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 				return nil, err
 			}
 
-			loadBalancer.AddWeightedServer(handler, service.Weight)
+			loadBalancer.Add(service.Name, handler, service.Weight)
+
+			if conf.Weighted.HealthCheck == nil {
+				continue
+			}
+
+			childName := service.Name
+			updater, ok := handler.(healthcheck.StatusUpdater)
+			if !ok {
+				return nil, fmt.Errorf("child service %v of %v not a healthcheck.StatusUpdater (%T)", childName, serviceQualifiedName, handler)
+			}
+
+			if err := updater.RegisterStatusUpdater(func(up bool) {
+				loadBalancer.SetStatus(ctx, childName, up)
+			}); err != nil {
+				return nil, fmt.Errorf("cannot register %v as updater for %v: %w", childName, serviceQualifiedName, err)
+			}
+
+			logger.Debug().Str("parent", serviceQualifiedName).Str("child", childName).
+				Msg("Child service will update parent on status change")
 		}
 
+		m.services[serviceQualifiedName] = loadBalancer
+
 		return loadBalancer, nil
 
 	default:
@@ -95,6 +158,14 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 	}
 }
 
+// LaunchHealthCheck launches the health checks.
+func (m *Manager) LaunchHealthCheck(ctx context.Context) {
+	for serviceName, hc := range m.healthCheckers {
+		logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
+		go hc.Launch(logger.WithContext(ctx))
+	}
+}
+
 func shuffle[T any](values []T, r *rand.Rand) []T {
 	shuffled := make([]T, len(values))
 	copy(shuffled, values)
@@ -171,6 +171,49 @@ func TestManager_BuildUDP(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "invalid health check expected response",
+			serviceName: "test",
+			configs: map[string]*runtime.UDPServiceInfo{
+				"test": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{Address: "192.168.0.12:53"},
+							},
+							HealthCheck: &dynamic.UDPServerHealthCheck{Expect: "("},
+						},
+					},
+				},
+			},
+			expectedError: "compiling health check expected response: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			desc:        "weighted service with health check, and a child without",
+			serviceName: "test",
+			configs: map[string]*runtime.UDPServiceInfo{
+				"test": {
+					UDPService: &dynamic.UDPService{
+						Weighted: &dynamic.UDPWeightedRoundRobin{
+							Services: []dynamic.UDPWRRService{
+								{Name: "child"},
+							},
+							HealthCheck: &dynamic.HealthCheck{},
+						},
+					},
+				},
+				"child": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{Address: "192.168.0.12:53"},
+							},
+						},
+					},
+				},
+			},
+			expectedError: "cannot register child as updater for test: healthCheck not enabled in config for this weighted service",
+		},
 	}
 
 	for _, test := range testCases {
@@ -180,7 +223,7 @@ func TestManager_BuildUDP(t *testing.T) {
 
 			manager := NewManager(&runtime.Configuration{
 				UDPServices: test.configs,
-			})
+			}, nil)
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
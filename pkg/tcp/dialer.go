@@ -21,12 +21,18 @@ import (
 
 type Dialer interface {
 	proxy.Dialer
+	proxy.ContextDialer
 
 	TerminationDelay() time.Duration
 }
 
-type tcpDialer struct {
+type dialer interface {
 	proxy.Dialer
+	proxy.ContextDialer
+}
+
+type tcpDialer struct {
+	dialer
 	terminationDelay time.Duration
 }
 
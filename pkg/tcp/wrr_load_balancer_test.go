@@ -1,6 +1,7 @@
 package tcp
 
 import (
+	"context"
 	"net"
 	"testing"
 	"time"
@@ -125,10 +126,10 @@ func TestLoadBalancing(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			balancer := NewWRRLoadBalancer()
+			balancer := NewWRRLoadBalancer(false)
 			for server, weight := range test.serversWeight {
 				server := server
-				balancer.AddWeightServer(HandlerFunc(func(conn WriteCloser) {
+				balancer.Add(server, HandlerFunc(func(conn WriteCloser) {
 					_, err := conn.Write([]byte(server))
 					require.NoError(t, err)
 				}), &weight)
@@ -144,3 +145,46 @@ func TestLoadBalancing(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadBalancing_status(t *testing.T) {
+	balancer := NewWRRLoadBalancer(true)
+	for _, server := range []string{"h1", "h2"} {
+		server := server
+		balancer.Add(server, HandlerFunc(func(conn WriteCloser) {
+			_, err := conn.Write([]byte(server))
+			require.NoError(t, err)
+		}), nil)
+	}
+
+	var statuses []bool
+	err := balancer.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	})
+	require.NoError(t, err)
+
+	balancer.SetStatus(context.Background(), "h1", false)
+
+	conn := &fakeConn{writeCall: make(map[string]int)}
+	for i := 0; i < 4; i++ {
+		balancer.ServeTCP(conn)
+	}
+	assert.Equal(t, map[string]int{"h2": 4}, conn.writeCall)
+
+	balancer.SetStatus(context.Background(), "h2", false)
+
+	conn = &fakeConn{writeCall: make(map[string]int)}
+	balancer.ServeTCP(conn)
+	assert.Empty(t, conn.writeCall)
+	assert.Equal(t, 1, conn.closeCall)
+
+	balancer.SetStatus(context.Background(), "h1", true)
+	balancer.SetStatus(context.Background(), "h2", true)
+
+	conn = &fakeConn{writeCall: make(map[string]int)}
+	for i := 0; i < 4; i++ {
+		balancer.ServeTCP(conn)
+	}
+	assert.Equal(t, map[string]int{"h1": 2, "h2": 2}, conn.writeCall)
+
+	assert.Equal(t, []bool{false, true}, statuses)
+}
@@ -0,0 +1,202 @@
+package testhelpers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+)
+
+// MaxMindNetwork is a network and its data, to write in a MaxMind database.
+type MaxMindNetwork struct {
+	CIDR string
+	Data map[string]interface{}
+}
+
+// WriteMaxMindDatabase writes a database in the MaxMind DB format holding the given networks, which must not overlap.
+// The data values can be maps, strings, uint16, uint32 and uint64.
+// The strings written more than once are written as pointers, as done in the MaxMind databases.
+func WriteMaxMindDatabase(path, databaseType string, ipVersion, recordSize int, networks []MaxMindNetwork) error {
+	type child struct {
+		node int
+		data int
+	}
+
+	// Node 0 is the root node. A child is either empty, a node, or the 1-based index of its data.
+	nodes := [][2]child{{}}
+	data := &maxMindEncoder{strings: make(map[string]int)}
+	var dataOffsets []int
+
+	for _, network := range networks {
+		ip, ipNet, err := net.ParseCIDR(network.CIDR)
+		if err != nil {
+			return err
+		}
+
+		ones, _ := ipNet.Mask.Size()
+		address := ip.To4()
+		if address == nil {
+			address = ip.To16()
+		} else if ipVersion == 6 {
+			address = append(make(net.IP, 12), address...)
+			ones += 96
+		}
+
+		dataOffsets = append(dataOffsets, len(data.buf))
+		if err := data.encode(network.Data); err != nil {
+			return err
+		}
+
+		node := 0
+		for i := 0; i < ones; i++ {
+			bit := (address[i/8] >> (7 - uint(i)%8)) & 1
+
+			if i == ones-1 {
+				nodes[node][bit] = child{data: len(dataOffsets)}
+				break
+			}
+
+			if nodes[node][bit].node == 0 {
+				nodes = append(nodes, [2]child{})
+				nodes[node][bit] = child{node: len(nodes) - 1}
+			}
+			node = nodes[node][bit].node
+		}
+	}
+
+	nodeCount := len(nodes)
+	record := func(c child) uint32 {
+		switch {
+		case c.data > 0:
+			return uint32(nodeCount + 16 + dataOffsets[c.data-1])
+		case c.node > 0:
+			return uint32(c.node)
+		default:
+			return uint32(nodeCount)
+		}
+	}
+
+	var tree []byte
+	for _, n := range nodes {
+		left, right := record(n[0]), record(n[1])
+
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			tree = append(tree, byte(left>>16), byte(left>>8), byte(left), byte(left>>24)<<4|byte(right>>24)&0x0F, byte(right>>16), byte(right>>8), byte(right))
+		case 32:
+			tree = binary.BigEndian.AppendUint32(tree, left)
+			tree = binary.BigEndian.AppendUint32(tree, right)
+		default:
+			return fmt.Errorf("unsupported record size: %d", recordSize)
+		}
+	}
+
+	metadata := &maxMindEncoder{}
+	err := metadata.encode(map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"database_type":               databaseType,
+		"ip_version":                  uint16(ipVersion),
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+	})
+	if err != nil {
+		return err
+	}
+
+	content := append(tree, make([]byte, 16)...)
+	content = append(content, data.buf...)
+	content = append(content, "\xAB\xCD\xEFMaxMind.com"...)
+	content = append(content, metadata.buf...)
+
+	return os.WriteFile(path, content, 0o600)
+}
+
+type maxMindEncoder struct {
+	buf     []byte
+	strings map[string]int
+}
+
+func (e *maxMindEncoder) encode(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		if offset, ok := e.strings[v]; ok && offset < 2048 {
+			e.buf = append(e.buf, 1<<5|byte(offset>>8)&0x07, byte(offset))
+			return nil
+		}
+
+		if e.strings != nil {
+			e.strings[v] = len(e.buf)
+		}
+		e.control(2, len(v))
+		e.buf = append(e.buf, v...)
+
+	case uint16:
+		e.uint(5, uint64(v))
+
+	case uint32:
+		e.uint(6, uint64(v))
+
+	case uint64:
+		e.uint(9, v)
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		e.control(7, len(v))
+		for _, key := range keys {
+			if err := e.encode(key); err != nil {
+				return err
+			}
+			if err := e.encode(v[key]); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+
+	return nil
+}
+
+func (e *maxMindEncoder) uint(typeNum int, value uint64) {
+	var payload []byte
+	for ; value > 0; value >>= 8 {
+		payload = append([]byte{byte(value)}, payload...)
+	}
+
+	e.control(typeNum, len(payload))
+	e.buf = append(e.buf, payload...)
+}
+
+func (e *maxMindEncoder) control(typeNum, size int) {
+	var sizeBits byte
+	var extra []byte
+
+	switch {
+	case size < 29:
+		sizeBits = byte(size)
+	case size < 285:
+		sizeBits = 29
+		extra = []byte{byte(size - 29)}
+	default:
+		sizeBits = 30
+		extra = []byte{byte((size - 285) >> 8), byte(size - 285)}
+	}
+
+	if typeNum > 7 {
+		e.buf = append(e.buf, sizeBits, byte(typeNum-7))
+	} else {
+		e.buf = append(e.buf, byte(typeNum)<<5|sizeBits)
+	}
+
+	e.buf = append(e.buf, extra...)
+}
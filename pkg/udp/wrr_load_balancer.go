@@ -1,6 +1,8 @@
 package udp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -9,21 +11,34 @@ import (
 
 type server struct {
 	Handler
+	name   string
 	weight int
 }
 
 // WRRLoadBalancer is a naive RoundRobin load balancer for UDP services.
 type WRRLoadBalancer struct {
+	wantsHealthCheck bool
+
 	servers       []server
 	lock          sync.Mutex
 	currentWeight int
 	index         int
+	// status is a record of which servers of the WRRLoadBalancer are healthy, keyed
+	// by name of server. A server is initially added to the map when it is
+	// created via Add, and it is later removed or added to the map as needed,
+	// through the SetStatus method.
+	status map[string]struct{}
+	// updaters is the list of hooks that are run (to update the WRRLoadBalancer
+	// parent(s)), whenever the WRRLoadBalancer status changes.
+	updaters []func(bool)
 }
 
 // NewWRRLoadBalancer creates a new WRRLoadBalancer.
-func NewWRRLoadBalancer() *WRRLoadBalancer {
+func NewWRRLoadBalancer(wantsHealthCheck bool) *WRRLoadBalancer {
 	return &WRRLoadBalancer{
-		index: -1,
+		wantsHealthCheck: wantsHealthCheck,
+		index:            -1,
+		status:           make(map[string]struct{}),
 	}
 }
 
@@ -42,14 +57,8 @@ func (b *WRRLoadBalancer) ServeUDP(conn *Conn) {
 	next.ServeUDP(conn)
 }
 
-// AddServer appends a handler to the existing list.
-func (b *WRRLoadBalancer) AddServer(serverHandler Handler) {
-	w := 1
-	b.AddWeightedServer(serverHandler, &w)
-}
-
-// AddWeightedServer appends a handler to the existing list with a weight.
-func (b *WRRLoadBalancer) AddWeightedServer(serverHandler Handler, weight *int) {
+// Add appends a server to the existing list with a name and a weight.
+func (b *WRRLoadBalancer) Add(name string, serverHandler Handler, weight *int) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
@@ -57,13 +66,71 @@ func (b *WRRLoadBalancer) AddWeightedServer(serverHandler Handler, weight *int)
 	if weight != nil {
 		w = *weight
 	}
-	b.servers = append(b.servers, server{Handler: serverHandler, weight: w})
+	b.servers = append(b.servers, server{Handler: serverHandler, name: name, weight: w})
+	b.status[name] = struct{}{}
+}
+
+// SetStatus sets on the balancer that its given child is now of the given
+// status.
+func (b *WRRLoadBalancer) SetStatus(ctx context.Context, childName string, up bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	status := "DOWN"
+	if up {
+		status = "UP"
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
+
+	if up {
+		b.status[childName] = struct{}{}
+	} else {
+		delete(b.status, childName)
+	}
+
+	upAfter := len(b.status) > 0
+	status = "DOWN"
+	if upAfter {
+		status = "UP"
+	}
+
+	// No Status Change
+	if upBefore == upAfter {
+		// We're still with the same status, no need to propagate
+		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", status)
+		return
+	}
+
+	// Status Change
+	log.Ctx(ctx).Debug().Msgf("Propagating new %s status", status)
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the WRRLoadBalancer changes.
+// Not thread safe.
+func (b *WRRLoadBalancer) RegisterStatusUpdater(fn func(up bool)) error {
+	if !b.wantsHealthCheck {
+		return errors.New("healthCheck not enabled in config for this weighted service")
+	}
+	b.updaters = append(b.updaters, fn)
+	return nil
+}
+
+func (b *WRRLoadBalancer) isUp(s server) bool {
+	_, ok := b.status[s.name]
+	return ok
 }
 
 func (b *WRRLoadBalancer) maxWeight() int {
 	max := -1
 	for _, s := range b.servers {
-		if s.weight > max {
+		if b.isUp(s) && s.weight > max {
 			max = s.weight
 		}
 	}
@@ -73,6 +140,10 @@ func (b *WRRLoadBalancer) maxWeight() int {
 func (b *WRRLoadBalancer) weightGcd() int {
 	divisor := -1
 	for _, s := range b.servers {
+		if !b.isUp(s) {
+			continue
+		}
+
 		if divisor == -1 {
 			divisor = s.weight
 		} else {
@@ -94,13 +165,17 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 		return nil, fmt.Errorf("no servers in the pool")
 	}
 
+	if len(b.status) == 0 {
+		return nil, fmt.Errorf("no healthy servers in the pool")
+	}
+
 	// The algorithm below may look messy,
 	// but is actually very simple it calculates the GCD  and subtracts it on every iteration,
 	// what interleaves servers and allows us not to build an iterator every time we readjust weights.
 
 	// Maximum weight across all enabled servers
 	max := b.maxWeight()
-	if max == 0 {
+	if max <= 0 {
 		return nil, fmt.Errorf("all servers have 0 weight")
 	}
 
@@ -116,7 +191,7 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 			}
 		}
 		srv := b.servers[b.index]
-		if srv.weight >= b.currentWeight {
+		if b.isUp(srv) && srv.weight >= b.currentWeight {
 			return srv, nil
 		}
 	}
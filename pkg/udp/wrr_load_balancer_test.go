@@ -0,0 +1,55 @@
+package udp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBalancing_status(t *testing.T) {
+	balancer := NewWRRLoadBalancer(true)
+	balancer.Add("h1", HandlerFunc(func(conn *Conn) {}), nil)
+	balancer.Add("h2", HandlerFunc(func(conn *Conn) {}), nil)
+
+	var statuses []bool
+	err := balancer.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	})
+	require.NoError(t, err)
+
+	balancer.SetStatus(context.Background(), "h1", false)
+	assert.Equal(t, map[string]int{"h2": 4}, nextServers(t, balancer, 4))
+
+	balancer.SetStatus(context.Background(), "h2", false)
+	_, err = balancer.next()
+	assert.Error(t, err)
+
+	balancer.SetStatus(context.Background(), "h1", true)
+	balancer.SetStatus(context.Background(), "h2", true)
+	assert.Equal(t, map[string]int{"h1": 2, "h2": 2}, nextServers(t, balancer, 4))
+
+	assert.Equal(t, []bool{false, true}, statuses)
+}
+
+func TestLoadBalancing_statusWithoutHealthCheck(t *testing.T) {
+	balancer := NewWRRLoadBalancer(false)
+
+	err := balancer.RegisterStatusUpdater(func(up bool) {})
+	assert.Error(t, err)
+}
+
+func nextServers(t *testing.T, balancer *WRRLoadBalancer, count int) map[string]int {
+	t.Helper()
+
+	servers := make(map[string]int)
+	for i := 0; i < count; i++ {
+		srv, err := balancer.next()
+		require.NoError(t, err)
+
+		servers[srv.(server).name]++
+	}
+
+	return servers
+}